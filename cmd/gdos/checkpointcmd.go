@@ -0,0 +1,211 @@
+// Copyright 2018 The dos Authors
+// This file is part of dos.
+//
+// dos is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// dos is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with dos. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doslink/dos/accounts/keystore"
+	"github.com/doslink/dos/cmd/utils"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/contracts/checkpointoracle"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/light"
+	"github.com/doslink/dos/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	checkpointIndexFlag = cli.Uint64Flag{
+		Name:  "index",
+		Usage: "Section index of the checkpoint",
+	}
+	checkpointOracleFlag = cli.StringFlag{
+		Name:  "oracle",
+		Usage: "Address of the checkpoint oracle contract",
+	}
+	checkpointSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "Admin account to sign the checkpoint with",
+	}
+	checkpointAdminsFlag = cli.StringFlag{
+		Name:  "admins",
+		Usage: "Comma separated list of the admins allowed to sign checkpoints",
+	}
+	checkpointThresholdFlag = cli.Uint64Flag{
+		Name:  "threshold",
+		Usage: "Number of admin signatures needed to register a checkpoint",
+	}
+
+	checkpointCommand = cli.Command{
+		Name:     "checkpoint",
+		Usage:    "Produce and verify signed trusted checkpoints",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+A checkpoint commits to the head, the CHT root and the bloom trie root of a
+section of the chain, which light clients and fast syncing nodes start off.
+Checkpoints are registered in the checkpoint oracle contract once signed by a
+threshold of its admins, each assembling the checkpoint from its own chain.
+
+The CHT and bloom trie roots are generated by the light server, so the sections
+to checkpoint must have been processed by a node running with --lightserv.`,
+		Subcommands: []cli.Command{
+			{
+				Name:   "sign",
+				Usage:  "Sign the checkpoint of a section of the local chain",
+				Action: utils.MigrateFlags(checkpointSign),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.KeyStoreDirFlag,
+					utils.PasswordFileFlag,
+					utils.LightKDFFlag,
+					checkpointIndexFlag,
+					checkpointOracleFlag,
+					checkpointSignerFlag,
+				},
+				Description: `
+    gdos checkpoint sign --index <index> --oracle <address> --signer <address>
+
+Assembles the checkpoint of the given section from the local chain and signs a
+vote for registering it in the oracle with the admin account. The signature is
+printed to be handed over to the admin registering the checkpoint.`,
+			},
+			{
+				Name:      "verify",
+				Usage:     "Verify the signatures of the checkpoint of a section of the local chain",
+				Action:    utils.MigrateFlags(checkpointVerify),
+				ArgsUsage: "<signature> [<signature>...]",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					checkpointIndexFlag,
+					checkpointOracleFlag,
+					checkpointAdminsFlag,
+					checkpointThresholdFlag,
+				},
+				Description: `
+    gdos checkpoint verify --index <index> --oracle <address> --admins <addresses> --threshold <n> <signatures>
+
+Assembles the checkpoint of the given section from the local chain and ensures
+that the signatures are votes of at least threshold distinct admins for it.`,
+			},
+		},
+	}
+)
+
+// checkpointSign signs a vote for the checkpoint of a section of the local chain.
+func checkpointSign(ctx *cli.Context) error {
+	if !ctx.IsSet(checkpointSignerFlag.Name) {
+		utils.Fatalf("The admin account to sign with is required (--%s)", checkpointSignerFlag.Name)
+	}
+	stack, _ := makeConfigNode(ctx)
+	oracle := makeOracleAddress(ctx)
+
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	checkpoint := readCheckpoint(chaindb, ctx.Uint64(checkpointIndexFlag.Name))
+	chaindb.Close()
+
+	ks := stack.AccountManager().Backends(keystore.KeyStoreType)[0].(*keystore.KeyStore)
+	account, _ := unlockAccount(ctx, ks, ctx.String(checkpointSignerFlag.Name), 0, utils.MakePasswordList(ctx))
+
+	sig, err := ks.SignHash(account, checkpointoracle.SignatureHash(oracle, checkpoint.SectionIndex, checkpoint.Hash()).Bytes())
+	if err != nil {
+		utils.Fatalf("Failed to sign checkpoint: %v", err)
+	}
+	sig[64] += 27 // Transform V from 0/1 to 27/28 as expected by ecrecover
+
+	printCheckpoint(checkpoint)
+	fmt.Printf("Signer:       %s\n", account.Address.Hex())
+	fmt.Printf("Signature:    %s\n", hexutil.Encode(sig))
+	return nil
+}
+
+// checkpointVerify ensures the checkpoint of a section of the local chain was
+// signed by a threshold of oracle admins.
+func checkpointVerify(ctx *cli.Context) error {
+	if len(ctx.Args()) == 0 {
+		utils.Fatalf("This command requires at least one signature argument.")
+	}
+	config := &params.CheckpointOracleConfig{
+		Address:   makeOracleAddress(ctx),
+		Threshold: ctx.Uint64(checkpointThresholdFlag.Name),
+	}
+	for _, admin := range strings.Split(ctx.String(checkpointAdminsFlag.Name), ",") {
+		if admin = strings.TrimSpace(admin); !common.IsHexAddress(admin) {
+			utils.Fatalf("Invalid admin address %q", admin)
+		}
+		config.Signers = append(config.Signers, common.HexToAddress(admin))
+	}
+	var sigs [][]byte
+	for _, arg := range ctx.Args() {
+		sig, err := hexutil.Decode(arg)
+		if err != nil {
+			utils.Fatalf("Invalid signature %q: %v", arg, err)
+		}
+		sigs = append(sigs, sig)
+	}
+	stack, _ := makeConfigNode(ctx)
+
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	checkpoint := readCheckpoint(chaindb, ctx.Uint64(checkpointIndexFlag.Name))
+	chaindb.Close()
+
+	signers, err := checkpointoracle.VerifySignatures(config, checkpoint, sigs)
+	if err != nil {
+		utils.Fatalf("Checkpoint verification failed: %v", err)
+	}
+	printCheckpoint(checkpoint)
+	for _, signer := range signers {
+		fmt.Printf("Signed by:    %s\n", signer.Hex())
+	}
+	return nil
+}
+
+// makeOracleAddress retrieves the address of the checkpoint oracle from the
+// command line flags.
+func makeOracleAddress(ctx *cli.Context) common.Address {
+	oracle := ctx.String(checkpointOracleFlag.Name)
+	if !common.IsHexAddress(oracle) {
+		utils.Fatalf("Invalid checkpoint oracle address %q (--%s)", oracle, checkpointOracleFlag.Name)
+	}
+	return common.HexToAddress(oracle)
+}
+
+// readCheckpoint assembles the checkpoint of a section from the chain database.
+func readCheckpoint(db dosdb.Database, index uint64) *params.TrustedCheckpoint {
+	checkpoint := &params.TrustedCheckpoint{SectionIndex: index}
+	checkpoint.SectionHead = rawdb.ReadCanonicalHash(db, checkpoint.HeadNumber())
+	checkpoint.CHTRoot = light.GetChtV2Root(db, index, checkpoint.SectionHead)
+	checkpoint.BloomRoot = light.GetBloomTrieRoot(db, index, checkpoint.SectionHead)
+
+	if checkpoint.Empty() {
+		utils.Fatalf("Checkpoint of section %d not available, was it processed by the light server?", index)
+	}
+	return checkpoint
+}
+
+// printCheckpoint prints the fields of a checkpoint and the hash signed by the
+// oracle admins.
+func printCheckpoint(checkpoint *params.TrustedCheckpoint) {
+	fmt.Printf("Section:      %d\n", checkpoint.SectionIndex)
+	fmt.Printf("Section head: %s (block %d)\n", checkpoint.SectionHead.Hex(), checkpoint.HeadNumber())
+	fmt.Printf("CHT root:     %s\n", checkpoint.CHTRoot.Hex())
+	fmt.Printf("Bloom root:   %s\n", checkpoint.BloomRoot.Hex())
+	fmt.Printf("Hash:         %s\n", checkpoint.Hash().Hex())
+}
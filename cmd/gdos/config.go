@@ -175,6 +175,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	if cfg.Dosstats.URL != "" {
 		utils.RegisterDosStatsService(stack, cfg.Dosstats.URL)
 	}
+	// Add the GraphQL endpoint if requested.
+	if cfg.Node.GraphQLHost != "" {
+		utils.RegisterGraphQLService(stack, cfg.Node.GraphQLEndpoint(), cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts)
+	}
 	return stack
 }
 
@@ -56,6 +56,8 @@ var (
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
+		utils.AncientFlag,
+		utils.AncientThresholdFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
 		utils.DashboardEnabledFlag,
@@ -70,6 +72,7 @@ var (
 		utils.DosashDatasetsOnDiskFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
+		utils.TxPoolPolicyFlag,
 		utils.TxPoolRejournalFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
@@ -82,12 +85,16 @@ var (
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.SnapshotFlag,
+		utils.SnapshotRebuildFlag,
+		utils.TraceIndexFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
 		utils.CacheGCFlag,
+		utils.BloomFilterSizeFlag,
 		utils.TrieCacheGenFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
@@ -117,7 +124,16 @@ var (
 		utils.NoCompactionFlag,
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
+		utils.GpoPendingFlag,
 		utils.ExtraDataFlag,
+		utils.MinerRecommitIntervalFlag,
+		utils.MinerNoEmptyFlag,
+		utils.MinerNotifyFlag,
+		utils.MinerTxOrderFlag,
+		utils.MinerPrioritySendersFlag,
+		utils.MinerSenderTxLimitFlag,
+		utils.MinerStratumFlag,
+		utils.MinerStratumDiffFlag,
 		configFileFlag,
 	}
 
@@ -131,6 +147,11 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
+		utils.GraphQLVirtualHostsFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -157,6 +178,10 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		// See prunecmd.go:
+		pruneStateCommand,
+		// See checkpointcmd.go:
+		checkpointCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -0,0 +1,102 @@
+// Copyright 2018 The dos Authors
+// This file is part of dos.
+//
+// dos is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// dos is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with dos. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/doslink/dos/cmd/utils"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/state/pruner"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	pruneStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(pruneState),
+		Name:      "prune-state",
+		Usage:     "Delete all stale state trie nodes from the database",
+		ArgsUsage: "[<blockHash> | <blockNum>]",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.CacheFlag,
+			utils.BloomFilterSizeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-state command walks the state trie of the given block, marking every
+live trie node and contract code in a bloom filter, and then deletes every other
+trie node from the chain database. If no block is specified, the most recent
+block with its state persisted to disk is used.
+
+The bloom filter is saved into the data directory before anything is deleted,
+so the command can be interrupted and resumed later on by running it again.
+The node must not be running while pruning.`,
+	}
+)
+
+// pruneState deletes all the state trie nodes not belonging to the selected
+// block from the chain database.
+func pruneState(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("This command accepts at most one argument.")
+	}
+	stack, _ := makeConfigNode(ctx)
+
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	defer chaindb.Close()
+
+	var root common.Hash
+	if arg := ctx.Args().First(); arg != "" {
+		header := findHeader(chaindb, arg)
+		if header == nil {
+			utils.Fatalf("Block %s not found", arg)
+		}
+		root = header.Root
+	}
+	bloomPath := stack.ResolvePath("statebloom.bf.gz")
+
+	prune, err := pruner.NewPruner(chaindb, bloomPath, ctx.GlobalUint64(utils.BloomFilterSizeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to create state pruner: %v", err)
+	}
+	if err := prune.Prune(root); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	return nil
+}
+
+// findHeader retrieves a canonical header by hash or number from the database.
+func findHeader(db dosdb.Database, arg string) *types.Header {
+	if hashish(arg) {
+		hash := common.HexToHash(arg)
+		number := rawdb.ReadHeaderNumber(db, hash)
+		if number == nil {
+			return nil
+		}
+		return rawdb.ReadHeader(db, hash, *number)
+	}
+	number, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, number), number)
+}
@@ -69,6 +69,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			configFileFlag,
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.AncientThresholdFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.NetworkIdFlag,
@@ -76,6 +78,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RinkebyFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.SnapshotFlag,
+			utils.SnapshotRebuildFlag,
+			utils.TraceIndexFlag,
 			utils.DosStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
@@ -115,6 +120,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.TxPoolNoLocalsFlag,
 			utils.TxPoolJournalFlag,
+			utils.TxPoolPolicyFlag,
 			utils.TxPoolRejournalFlag,
 			utils.TxPoolPriceLimitFlag,
 			utils.TxPoolPriceBumpFlag,
@@ -131,6 +137,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.CacheFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
+			utils.BloomFilterSizeFlag,
 			utils.TrieCacheGenFlag,
 		},
 	},
@@ -153,6 +160,11 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLListenAddrFlag,
+			utils.GraphQLPortFlag,
+			utils.GraphQLCORSDomainFlag,
+			utils.GraphQLVirtualHostsFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -188,6 +200,14 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TargetGasLimitFlag,
 			utils.GasPriceFlag,
 			utils.ExtraDataFlag,
+			utils.MinerRecommitIntervalFlag,
+			utils.MinerNoEmptyFlag,
+			utils.MinerNotifyFlag,
+			utils.MinerTxOrderFlag,
+			utils.MinerPrioritySendersFlag,
+			utils.MinerSenderTxLimitFlag,
+			utils.MinerStratumFlag,
+			utils.MinerStratumDiffFlag,
 		},
 	},
 	{
@@ -195,6 +215,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.GpoBlocksFlag,
 			utils.GpoPercentileFlag,
+			utils.GpoPendingFlag,
 		},
 	},
 	{
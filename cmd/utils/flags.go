@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -35,7 +36,10 @@ import (
 	"github.com/doslink/dos/consensus"
 	"github.com/doslink/dos/consensus/clique"
 	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/consensus/ibft"
+	"github.com/doslink/dos/consensus/transition"
 	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/rawdb"
 	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/crypto"
@@ -45,9 +49,11 @@ import (
 	"github.com/doslink/dos/dos/gasprice"
 	"github.com/doslink/dos/dosdb"
 	"github.com/doslink/dos/dosstats"
+	"github.com/doslink/dos/graphql"
 	"github.com/doslink/dos/les"
 	"github.com/doslink/dos/log"
 	"github.com/doslink/dos/metrics"
+	"github.com/doslink/dos/miner"
 	"github.com/doslink/dos/node"
 	"github.com/doslink/dos/p2p"
 	"github.com/doslink/dos/p2p/discover"
@@ -118,6 +124,15 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for ancient chain segments (default = inside chaindata)",
+	}
+	AncientThresholdFlag = cli.Uint64Flag{
+		Name:  "datadir.ancient.threshold",
+		Usage: "Number of recent blocks to keep in the database before moving them to the ancient store",
+		Value: dos.DefaultConfig.DatabaseFreezerThreshold,
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -175,6 +190,18 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	SnapshotFlag = cli.BoolFlag{
+		Name:  "snapshot",
+		Usage: "Maintain a flat snapshot of the state for faster account and storage access",
+	}
+	SnapshotRebuildFlag = cli.BoolFlag{
+		Name:  "snapshot.rebuild",
+		Usage: "Discard the persisted state snapshot and regenerate it in the background",
+	}
+	TraceIndexFlag = cli.BoolFlag{
+		Name:  "trace.index",
+		Usage: "Index the accounts of call traces for faster trace_filter queries (requires --gcmode=archive)",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -249,6 +276,11 @@ var (
 		Usage: "Disk journal for local transaction to survive node restarts",
 		Value: core.DefaultTxPoolConfig.Journal,
 	}
+	TxPoolPolicyFlag = cli.StringFlag{
+		Name:  "txpool.policy",
+		Usage: "Disk file of the transaction admission policy to survive node restarts",
+		Value: core.DefaultTxPoolConfig.Policy,
+	}
 	TxPoolRejournalFlag = cli.DurationFlag{
 		Name:  "txpool.rejournal",
 		Usage: "Time interval to regenerate the local transaction journal",
@@ -305,6 +337,11 @@ var (
 		Usage: "Percentage of cache memory allowance to use for trie pruning",
 		Value: 25,
 	}
+	BloomFilterSizeFlag = cli.Uint64Flag{
+		Name:  "bloomfilter.size",
+		Usage: "Megabytes of memory allocated to bloom-filter for state pruning",
+		Value: 2048,
+	}
 	TrieCacheGenFlag = cli.IntFlag{
 		Name:  "trie-cache-gens",
 		Usage: "Number of trie node generations to keep in memory",
@@ -339,6 +376,41 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	MinerRecommitIntervalFlag = cli.DurationFlag{
+		Name:  "miner.recommit",
+		Usage: "Time interval to recreate the block being mined with newly arrived transactions (0 = disabled)",
+		Value: dos.DefaultConfig.MinerRecommit,
+	}
+	MinerNoEmptyFlag = cli.BoolFlag{
+		Name:  "miner.noempty",
+		Usage: "Do not seal empty blocks while transactions are pending",
+	}
+	MinerNotifyFlag = cli.StringFlag{
+		Name:  "miner.notify",
+		Usage: "Comma separated HTTP URL list to notify of new work packages",
+	}
+	MinerTxOrderFlag = cli.StringFlag{
+		Name:  "miner.txorder",
+		Usage: `Ordering of the pending transactions in mined blocks ("price", "fifo" or "priority")`,
+		Value: miner.TxOrderPrice,
+	}
+	MinerPrioritySendersFlag = cli.StringFlag{
+		Name:  "miner.prioritysenders",
+		Usage: "Comma separated list of senders whose transactions are mined first with the priority ordering",
+	}
+	MinerSenderTxLimitFlag = cli.IntFlag{
+		Name:  "miner.sendertxlimit",
+		Usage: "Maximum number of transactions from a single sender per mined block (0 = unlimited)",
+	}
+	MinerStratumFlag = cli.StringFlag{
+		Name:  "miner.stratum",
+		Usage: "Stratum server listening interface:port pushing work to external miners (disabled if empty)",
+	}
+	MinerStratumDiffFlag = cli.Float64Flag{
+		Name:  "miner.stratum.diff",
+		Usage: "Share difficulty announced to Stratum miners",
+		Value: dos.DefaultConfig.MinerStratumDiff,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -434,6 +506,30 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -518,6 +614,10 @@ var (
 		Usage: "Suggested gas price is the given percentile of a set of recent transaction gas prices",
 		Value: dos.DefaultConfig.GPO.Percentile,
 	}
+	GpoPendingFlag = cli.BoolFlag{
+		Name:  "gpopending",
+		Usage: "Raise the suggested gas price to the same percentile of the pending transactions if higher",
+	}
 	WhisperEnabledFlag = cli.BoolFlag{
 		Name:  "shh",
 		Usage: "Enable Whisper",
@@ -695,6 +795,26 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -874,6 +994,7 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -905,6 +1026,9 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
 	if ctx.GlobalIsSet(GpoPercentileFlag.Name) {
 		cfg.Percentile = ctx.GlobalInt(GpoPercentileFlag.Name)
 	}
+	if ctx.GlobalIsSet(GpoPendingFlag.Name) {
+		cfg.Pending = ctx.GlobalBool(GpoPendingFlag.Name)
+	}
 }
 
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
@@ -914,6 +1038,9 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolJournalFlag.Name) {
 		cfg.Journal = ctx.GlobalString(TxPoolJournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPolicyFlag.Name) {
+		cfg.Policy = ctx.GlobalString(TxPoolPolicyFlag.Name)
+	}
 	if ctx.GlobalIsSet(TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(TxPoolRejournalFlag.Name)
 	}
@@ -1046,11 +1173,28 @@ func SetDosConfig(ctx *cli.Context, stack *node.Node, cfg *dos.Config) {
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
 
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
+	if ctx.GlobalIsSet(AncientThresholdFlag.Name) {
+		cfg.DatabaseFreezerThreshold = ctx.GlobalUint64(AncientThresholdFlag.Name)
+	}
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
 
+	if ctx.GlobalIsSet(SnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalBool(SnapshotFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotRebuildFlag.Name) {
+		cfg.Snapshot = true
+		cfg.SnapshotRebuild = ctx.GlobalBool(SnapshotRebuildFlag.Name)
+	}
+	if ctx.GlobalIsSet(TraceIndexFlag.Name) {
+		cfg.TraceIndex = ctx.GlobalBool(TraceIndexFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
@@ -1066,6 +1210,35 @@ func SetDosConfig(ctx *cli.Context, stack *node.Node, cfg *dos.Config) {
 	if ctx.GlobalIsSet(GasPriceFlag.Name) {
 		cfg.GasPrice = GlobalBig(ctx, GasPriceFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerRecommitIntervalFlag.Name) {
+		cfg.MinerRecommit = ctx.GlobalDuration(MinerRecommitIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerNoEmptyFlag.Name) {
+		cfg.MinerNoEmpty = ctx.GlobalBool(MinerNoEmptyFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerNotifyFlag.Name) {
+		cfg.MinerNotify = strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",")
+	}
+	if ctx.GlobalIsSet(MinerTxOrderFlag.Name) {
+		cfg.MinerTxOrder = ctx.GlobalString(MinerTxOrderFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerPrioritySendersFlag.Name) {
+		for _, sender := range strings.Split(ctx.GlobalString(MinerPrioritySendersFlag.Name), ",") {
+			if sender = strings.TrimSpace(sender); !common.IsHexAddress(sender) {
+				Fatalf("Invalid priority sender address: %q", sender)
+			}
+			cfg.MinerPrioritySenders = append(cfg.MinerPrioritySenders, common.HexToAddress(sender))
+		}
+	}
+	if ctx.GlobalIsSet(MinerSenderTxLimitFlag.Name) {
+		cfg.MinerSenderTxLimit = ctx.GlobalInt(MinerSenderTxLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumFlag.Name) {
+		cfg.MinerStratum = ctx.GlobalString(MinerStratumFlag.Name)
+	}
+	if ctx.GlobalIsSet(MinerStratumDiffFlag.Name) {
+		cfg.MinerStratumDiff = ctx.GlobalFloat64(MinerStratumDiffFlag.Name)
+	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
@@ -1175,6 +1348,25 @@ func RegisterDosStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService configures the GraphQL API service and adds it to the
+// given node, serving the full or light Doslink service, whichever is running.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Retrieve the backend of either the dos or les service
+		var dosServ *dos.Doslink
+		if err := ctx.Service(&dosServ); err == nil {
+			return graphql.New(dosServ.APIBackend, endpoint, cors, vhosts)
+		}
+		var lesServ *les.LightDoslink
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, endpoint, cors, vhosts)
+		}
+		return nil, errors.New("no Doslink service to serve GraphQL queries from")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1195,9 +1387,22 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) dosdb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	// Light clients don't store block bodies, only full nodes have a freezer
+	if _, ok := chainDb.(*dosdb.LDBDatabase); ok && !ctx.GlobalBool(LightModeFlag.Name) {
+		chainDb, err = rawdb.NewDatabaseWithFreezer(chainDb, MakeAncientDir(ctx, stack), "dos/db/chaindata/", ctx.GlobalUint64(AncientThresholdFlag.Name))
+		if err != nil {
+			Fatalf("Could not open ancient database: %v", err)
+		}
+	}
 	return chainDb
 }
 
+// MakeAncientDir resolves the directory of the ancient chain store from the
+// command line flags, defaulting to a folder inside the chain database.
+func MakeAncientDir(ctx *cli.Context, stack *node.Node) string {
+	return dos.AncientDir(stack.ResolvePath, "chaindata", ctx.GlobalString(AncientFlag.Name))
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -1221,8 +1426,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		Fatalf("%v", err)
 	}
 	var engine consensus.Engine
-	if config.Clique != nil {
+	if config.Clique != nil && config.CliqueBlock == nil {
 		engine = clique.New(config.Clique, chainDb)
+	} else if config.Ibft != nil {
+		engine = ibft.New(config.Ibft, chainDb)
 	} else {
 		engine = dosash.NewFaker()
 		if !ctx.GlobalBool(FakePoWFlag.Name) {
@@ -1235,6 +1442,9 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 				DatasetsOnDisk: dos.DefaultConfig.Dosash.DatasetsOnDisk,
 			})
 		}
+		if config.Clique != nil {
+			engine = transition.New(config, engine, clique.New(config.Clique, chainDb))
+		}
 	}
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
@@ -1243,6 +1453,7 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		Disabled:      ctx.GlobalString(GCModeFlag.Name) == "archive",
 		TrieNodeLimit: dos.DefaultConfig.TrieCache,
 		TrieTimeLimit: dos.DefaultConfig.TrieTimeout,
+		Snapshot:      ctx.GlobalBool(SnapshotFlag.Name),
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieNodeLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
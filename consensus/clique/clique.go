@@ -130,6 +130,10 @@ var (
 	// on an instant chain (0 second period). It's important to refuse these as the
 	// block reward is zero, so an empty block just bloats the chain... fast.
 	errWaitTransactions = errors.New("waiting for transactions")
+
+	// errNoTransitionSigners is returned if the chain switches over to clique
+	// without any initial signers configured to seal the following blocks.
+	errNoTransitionSigners = errors.New("no initial signers configured for the clique switch")
 )
 
 // SignerFn is a signer callback function to request a hash to be signed by a
@@ -388,6 +392,19 @@ func (c *Clique) snapshot(chain consensus.ChainReader, number uint64, hash commo
 				break
 			}
 		}
+		// If we're right before switching over from proof-of-work, make a snapshot
+		// with the configured initial signers
+		if transition := chain.Config().CliqueBlock; transition != nil && transition.Sign() > 0 && number+1 == transition.Uint64() {
+			if len(c.config.Signers) == 0 {
+				return nil, errNoTransitionSigners
+			}
+			snap = newSnapshot(c.config, c.signatures, number, hash, c.config.Signers)
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored transition voting snapshot to disk", "number", number, "hash", hash)
+			break
+		}
 		// If we're at block zero, make a snapshot
 		if number == 0 {
 			genesis := chain.GetHeaderByNumber(0)
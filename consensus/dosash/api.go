@@ -0,0 +1,98 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package dosash
+
+import (
+	"errors"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/rpc"
+)
+
+var (
+	// errUnknownBlock is returned when the rewards of a block are requested that
+	// is not part of the local blockchain, or that was not mined.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errNotMined is returned when the rewards of a block are requested that was
+	// sealed by proof-of-authority after the chain switched over to clique.
+	errNotMined = errors.New("block not sealed by proof-of-work")
+)
+
+// API is a user facing RPC API exposing the reward schedule of the
+// proof-of-work scheme.
+type API struct {
+	chain consensus.ChainReader
+}
+
+// Rewards is the split of the rewards paid out for mining a block.
+type Rewards struct {
+	Coinbase       common.Address `json:"coinbase"`
+	Reward         *hexutil.Big   `json:"reward"`
+	Treasury       common.Address `json:"treasury"`
+	TreasuryReward *hexutil.Big   `json:"treasuryReward"`
+	Uncles         []UncleReward  `json:"uncles"`
+}
+
+// UncleReward is the reward paid out to the miner of an included uncle.
+type UncleReward struct {
+	Coinbase common.Address `json:"coinbase"`
+	Reward   *hexutil.Big   `json:"reward"`
+}
+
+// GetRewards retrieves the split of the mining rewards of the given block
+// between its miner, the treasury and the miners of its uncles.
+func (api *API) GetRewards(number *rpc.BlockNumber) (*Rewards, error) {
+	// Retrieve the requested block number (or current if none requested)
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	// Ensure we have an actually valid block, the genesis paying no rewards
+	if header == nil || header.Number.Sign() == 0 {
+		return nil, errUnknownBlock
+	}
+	if api.chain.Config().IsClique(header.Number) {
+		return nil, errNotMined
+	}
+	block := api.chain.GetBlock(header.Hash(), header.Number.Uint64())
+	if block == nil {
+		return nil, errUnknownBlock
+	}
+	// Split the rewards the same way as the block was finalized
+	split := calcRewards(api.chain.Config(), header, block.Uncles())
+
+	rewards := &Rewards{
+		Coinbase:       header.Coinbase,
+		Reward:         (*hexutil.Big)(split.miner),
+		Treasury:       split.treasury,
+		TreasuryReward: (*hexutil.Big)(split.fund),
+		Uncles:         make([]UncleReward, len(split.uncles)),
+	}
+	for i, uncle := range block.Uncles() {
+		rewards.Uncles[i] = UncleReward{
+			Coinbase: uncle.Coinbase,
+			Reward:   (*hexutil.Big)(split.uncles[i]),
+		}
+	}
+	return rewards, nil
+}
@@ -30,6 +30,7 @@ import (
 	"github.com/doslink/dos/consensus/misc"
 	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/log"
 	"github.com/doslink/dos/params"
 	set "gopkg.in/fatih/set.v0"
 )
@@ -39,6 +40,8 @@ var (
 	FrontierBlockReward    *big.Int = big.NewInt(5e+18) // Block reward in wei for successfully mining a block
 	ByzantiumBlockReward   *big.Int = big.NewInt(3e+18) // Block reward in wei for successfully mining a block upward from Byzantium
 	maxUncles                       = 2                 // Maximum number of uncles allowed in a single block
+	lwmaDefaultWindow      uint64   = 60                // Number of blocks averaged by LWMA if not configured
+	lwmaDefaultTarget      uint64   = 15                // Target block time of LWMA in seconds if not configured
 	allowedFutureBlockTime          = 15 * time.Second  // Max time from current time allowed for blocks, before they're considered future blocks
 )
 
@@ -81,7 +84,7 @@ func (dosash *Dosash) VerifyHeader(chain consensus.ChainReader, header *types.He
 		return consensus.ErrUnknownAncestor
 	}
 	// Sanity checks passed, do a proper verification
-	return dosash.verifyHeader(chain, header, parent, false, seal)
+	return dosash.verifyHeader(chain, header, parent, nil, false, seal)
 }
 
 // VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers
@@ -162,7 +165,7 @@ func (dosash *Dosash) verifyHeaderWorker(chain consensus.ChainReader, headers []
 	if chain.GetHeader(headers[index].Hash(), headers[index].Number.Uint64()) != nil {
 		return nil // known block
 	}
-	return dosash.verifyHeader(chain, headers[index], parent, false, seals[index])
+	return dosash.verifyHeader(chain, headers[index], parent, headers[:index], false, seals[index])
 }
 
 // VerifyUncles verifies that the given block's uncles conform to the consensus
@@ -210,7 +213,7 @@ func (dosash *Dosash) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 		if ancestors[uncle.ParentHash] == nil || uncle.ParentHash == block.ParentHash() {
 			return errDanglingUncle
 		}
-		if err := dosash.verifyHeader(chain, uncle, ancestors[uncle.ParentHash], true, true); err != nil {
+		if err := dosash.verifyHeader(chain, uncle, ancestors[uncle.ParentHash], nil, true, true); err != nil {
 			return err
 		}
 	}
@@ -218,9 +221,10 @@ func (dosash *Dosash) VerifyUncles(chain consensus.ChainReader, block *types.Blo
 }
 
 // verifyHeader checks whether a header conforms to the consensus rules of the
-// stock Doslink dosash engine.
+// stock Doslink dosash engine. The parents are the headers of the batch being
+// verified preceding the header, not yet available in the chain.
 // See YP section 4.3.4. "Block Header Validity"
-func (dosash *Dosash) verifyHeader(chain consensus.ChainReader, header, parent *types.Header, uncle bool, seal bool) error {
+func (dosash *Dosash) verifyHeader(chain consensus.ChainReader, header, parent *types.Header, parents []*types.Header, uncle bool, seal bool) error {
 	// Ensure that the header's extra-data section is of a reasonable size
 	if uint64(len(header.Extra)) > params.MaximumExtraDataSize {
 		return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
@@ -239,8 +243,10 @@ func (dosash *Dosash) verifyHeader(chain consensus.ChainReader, header, parent *
 		return errZeroBlockTime
 	}
 	// Verify the block's difficulty based in it's timestamp and parent's difficulty
-	expected := dosash.CalcDifficulty(chain, header.Time.Uint64(), parent)
-
+	expected, err := dosash.calcDifficulty(chain, header.Time.Uint64(), parent, parents)
+	if err != nil {
+		return err
+	}
 	if expected.Cmp(header.Difficulty) != 0 {
 		return fmt.Errorf("invalid difficulty: have %v, want %v", header.Difficulty, expected)
 	}
@@ -286,9 +292,37 @@ func (dosash *Dosash) verifyHeader(chain consensus.ChainReader, header, parent *
 
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time
-// given the parent block's time and difficulty.
+// given the parent block's time and difficulty. If the ancestors needed
+// to adjust it are unavailable, the parent's difficulty is returned.
 func (dosash *Dosash) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
-	return CalcDifficulty(chain.Config(), time, parent)
+	difficulty, err := dosash.calcDifficulty(chain, time, parent, nil)
+	if err != nil {
+		log.Error("Failed to calculate difficulty", "number", new(big.Int).Add(parent.Number, big1), "parent", parent.Hash(), "err", err)
+		return new(big.Int).Set(parent.Difficulty)
+	}
+	return difficulty
+}
+
+// calcDifficulty is the difficulty adjustment algorithm selected by the chain
+// config. Ancestors needed by the LWMA window are looked up in the parents first
+// (the headers of a batch being verified), falling back to the chain.
+func (dosash *Dosash) calcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header, parents []*types.Header) (*big.Int, error) {
+	config := chain.Config()
+	if !config.Dosash.IsLWMA(new(big.Int).Add(parent.Number, big1)) {
+		return CalcDifficulty(config, time, parent), nil
+	}
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		if len(parents) > 0 {
+			first := parents[0].Number.Uint64()
+			if number >= first && number-first < uint64(len(parents)) {
+				if header := parents[number-first]; header.Hash() == hash {
+					return header
+				}
+			}
+		}
+		return chain.GetHeader(hash, number)
+	}
+	return CalcDifficultyLWMA(config.Dosash, parent, getHeader)
 }
 
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
@@ -306,11 +340,91 @@ func CalcDifficulty(config *params.ChainConfig, time uint64, parent *types.Heade
 	}
 }
 
+// CalcDifficultyLWMA is the linearly weighted moving average difficulty
+// adjustment algorithm. It returns the difficulty that a new block should have
+// based on the difficulties and solve times of the last blocks of the chain,
+// weighting recent solve times more heavily so the difficulty follows swings in
+// the hash rate quickly. The timestamp of the new block is deliberately ignored
+// to prevent miners from manipulating their own difficulty.
+//
+// Ancestors are retrieved through getHeader, averaging fewer blocks only if the
+// chain is shorter than the window. A missing ancestor is reported as an error,
+// the difficulty depending on the full window.
+func CalcDifficultyLWMA(config *params.DosashConfig, parent *types.Header, getHeader func(common.Hash, uint64) *types.Header) (*big.Int, error) {
+	window, target := config.LWMAWindow, config.LWMATarget
+	if window == 0 {
+		window = lwmaDefaultWindow
+	}
+	if target == 0 {
+		target = lwmaDefaultTarget
+	}
+	// Gather the headers of the window, newest first
+	headers := []*types.Header{parent}
+	for uint64(len(headers)) <= window {
+		last := headers[len(headers)-1]
+		if last.Number.Sign() == 0 {
+			break
+		}
+		header := getHeader(last.ParentHash, last.Number.Uint64()-1)
+		if header == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		headers = append(headers, header)
+	}
+	n := int64(len(headers) - 1)
+	if n == 0 {
+		return new(big.Int).Set(parent.Difficulty), nil
+	}
+	// Sum the difficulties and the solve times weighted by their recency, the
+	// latter clamped to avoid timestamp manipulation skewing the average
+	var (
+		bigTarget = new(big.Int).SetUint64(target)
+		maxSolve  = new(big.Int).Mul(bigTarget, big6)
+
+		weighted     = new(big.Int)
+		difficulty   = new(big.Int)
+		solve        = new(big.Int)
+		newer, older *types.Header
+	)
+	for i := int64(1); i <= n; i++ {
+		newer, older = headers[n-i], headers[n-i+1]
+
+		solve.Sub(newer.Time, older.Time)
+		if solve.Sign() <= 0 {
+			solve.Set(big1)
+		}
+		if solve.Cmp(maxSolve) > 0 {
+			solve.Set(maxSolve)
+		}
+		weighted.Add(weighted, solve.Mul(solve, big.NewInt(i)))
+		difficulty.Add(difficulty, newer.Difficulty)
+	}
+	// Limit the difficulty increase to tenfold of the average
+	limit := big.NewInt(n * (n + 1) / 2)
+	limit.Mul(limit, bigTarget)
+	limit.Div(limit, big10)
+	if weighted.Cmp(limit) < 0 {
+		weighted.Set(limit)
+	}
+	if weighted.Sign() == 0 {
+		weighted.Set(big1)
+	}
+	// next = sum(difficulties) * target * (n + 1) / (2 * weighted solve times)
+	next := difficulty.Mul(difficulty, bigTarget)
+	next.Mul(next, big.NewInt(n+1))
+	next.Div(next, weighted.Mul(weighted, big2))
+	if next.Cmp(big1) < 0 {
+		next.Set(big1)
+	}
+	return next, nil
+}
+
 // Some weird constants to avoid constant memory allocs for them.
 var (
 	expDiffPeriod = big.NewInt(100000)
 	big1          = big.NewInt(1)
 	big2          = big.NewInt(2)
+	big6          = big.NewInt(6)
 	big9          = big.NewInt(9)
 	big10         = big.NewInt(10)
 	bigMinus99    = big.NewInt(-99)
@@ -500,6 +614,29 @@ func (dosash *Dosash) VerifySeal(chain consensus.ChainReader, header *types.Head
 	return nil
 }
 
+// Hashimoto computes the mix digest and PoW value of a sealing hash and nonce at
+// the given block number using the verification cache. It allows validating
+// externally mined shares whose difficulty is below that of the block itself.
+func (dosash *Dosash) Hashimoto(number uint64, hash common.Hash, nonce uint64) (common.Hash, common.Hash) {
+	// If we're running a fake PoW, every nonce is a perfect solution
+	if dosash.config.PowMode == ModeFake || dosash.config.PowMode == ModeFullFake {
+		return common.Hash{}, common.Hash{}
+	}
+	// If we're running a shared PoW, delegate the computation to it
+	if dosash.shared != nil {
+		return dosash.shared.Hashimoto(number, hash, nonce)
+	}
+	cache := dosash.cache(number)
+	size := datasetSize(number)
+	if dosash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	digest, result := hashimotoLight(size, cache.cache, hash.Bytes(), nonce)
+	runtime.KeepAlive(cache)
+
+	return common.BytesToHash(digest), common.BytesToHash(result)
+}
+
 // Prepare implements consensus.Engine, initializing the difficulty field of a
 // header to conform to the dosash protocol. The changes are done inline.
 func (dosash *Dosash) Prepare(chain consensus.ChainReader, header *types.Header) error {
@@ -507,7 +644,11 @@ func (dosash *Dosash) Prepare(chain consensus.ChainReader, header *types.Header)
 	if parent == nil {
 		return consensus.ErrUnknownAncestor
 	}
-	header.Difficulty = dosash.CalcDifficulty(chain, header.Time.Uint64(), parent)
+	difficulty, err := dosash.calcDifficulty(chain, header.Time.Uint64(), parent, nil)
+	if err != nil {
+		return err
+	}
+	header.Difficulty = difficulty
 	return nil
 }
 
@@ -524,31 +665,70 @@ func (dosash *Dosash) Finalize(chain consensus.ChainReader, header *types.Header
 
 // Some weird constants to avoid constant memory allocs for them.
 var (
-	big8  = big.NewInt(8)
-	big32 = big.NewInt(32)
+	big8   = big.NewInt(8)
+	big32  = big.NewInt(32)
+	big100 = big.NewInt(100)
 )
 
-// AccumulateRewards credits the coinbase of the given block with the mining
-// reward. The total reward consists of the static block reward and rewards for
-// included uncles. The coinbase of each uncle block is also rewarded.
-func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+// rewardSplit is the split of the rewards paid out for mining a block.
+type rewardSplit struct {
+	miner    *big.Int       // Reward credited to the coinbase of the block
+	treasury common.Address // Development fund credited with a share of the block reward
+	fund     *big.Int       // Share of the block reward credited to the treasury
+	uncles   []*big.Int     // Rewards credited to the coinbases of the uncles
+}
+
+// calcRewards calculates the split of the mining rewards of a block. The miner
+// is rewarded with the static block reward, less the share of the treasury, and
+// rewards for included uncles. The coinbase of each uncle block is also rewarded.
+func calcRewards(config *params.ChainConfig, header *types.Header, uncles []*types.Header) *rewardSplit {
 	// Select the correct block reward based on chain progression
 	blockReward := FrontierBlockReward
 	if config.IsByzantium(header.Number) {
 		blockReward = ByzantiumBlockReward
 	}
+	if reward := config.Dosash.BlockReward(header.Number); reward != nil {
+		blockReward = reward
+	}
+	uncleRatio := new(big.Int).SetUint64(config.Dosash.UncleRewardPercent())
+
+	// Set aside the share of the development fund
+	treasury, percent := config.Dosash.TreasuryShare(header.Number)
+	fund := new(big.Int).Mul(blockReward, new(big.Int).SetUint64(percent))
+	fund.Div(fund, big100)
+
+	split := &rewardSplit{
+		miner:    new(big.Int).Sub(blockReward, fund),
+		treasury: treasury,
+		fund:     fund,
+		uncles:   make([]*big.Int, len(uncles)),
+	}
 	// Accumulate the rewards for the miner and any included uncles
-	reward := new(big.Int).Set(blockReward)
-	r := new(big.Int)
-	for _, uncle := range uncles {
-		r.Add(uncle.Number, big8)
+	for i, uncle := range uncles {
+		r := new(big.Int).Add(uncle.Number, big8)
 		r.Sub(r, header.Number)
 		r.Mul(r, blockReward)
 		r.Div(r, big8)
-		state.AddBalance(uncle.Coinbase, r)
+		r.Mul(r, uncleRatio)
+		r.Div(r, big100)
+		split.uncles[i] = r
 
-		r.Div(blockReward, big32)
-		reward.Add(reward, r)
+		split.miner.Add(split.miner, new(big.Int).Div(blockReward, big32))
+	}
+	return split
+}
+
+// AccumulateRewards credits the coinbase of the given block with the mining
+// reward. The total reward consists of the static block reward and rewards for
+// included uncles. The coinbase of each uncle block is also rewarded, as is the
+// treasury with its share of the static block reward.
+func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
+	split := calcRewards(config, header, uncles)
+	for i, uncle := range uncles {
+		state.AddBalance(uncle.Coinbase, split.uncles[i])
+	}
+	if split.fund.Sign() > 0 {
+		state.AddBalance(split.treasury, split.fund)
 	}
-	state.AddBalance(header.Coinbase, reward)
+	state.AddBalance(header.Coinbase, split.miner)
 }
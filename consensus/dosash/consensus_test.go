@@ -23,8 +23,12 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/doslink/dos/common"
 	"github.com/doslink/dos/common/math"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
 	"github.com/doslink/dos/params"
 )
 
@@ -84,3 +88,141 @@ func TestCalcDifficulty(t *testing.T) {
 		}
 	}
 }
+
+// Tests that block, uncle and treasury rewards follow the configured reward
+// schedule.
+func TestAccumulateRewards(t *testing.T) {
+	ratio := uint64(50)
+	treasury := common.Address{3}
+	tests := []struct {
+		dosash *params.DosashConfig
+		miner  int64
+		uncle  int64
+		fund   int64
+	}{
+		// Default Byzantium rewards
+		{new(params.DosashConfig), 3e+18 + 3e+18/32, 3e+18 * 7 / 8, 0},
+		// Reward eras with halving and reduced uncle rewards
+		{
+			&params.DosashConfig{
+				RewardEras:       []params.RewardEra{{Block: big.NewInt(0), Reward: big.NewInt(64e+16)}},
+				HalvingInterval:  5,
+				UncleRewardRatio: &ratio,
+			},
+			32e+16 + 32e+16/32, 32e+16 * 7 / 8 / 2, 0,
+		},
+		// Treasury share of the block reward, changed at a later fork
+		{
+			&params.DosashConfig{
+				Treasury: []params.TreasuryEra{
+					{Block: big.NewInt(0), Address: common.Address{4}, Percent: 20},
+					{Block: big.NewInt(5), Address: treasury, Percent: 10},
+					{Block: big.NewInt(6), Address: common.Address{4}, Percent: 30},
+				},
+			},
+			3e+18*9/10 + 3e+18/32, 3e+18 * 7 / 8, 3e+18 / 10,
+		},
+	}
+	for i, tt := range tests {
+		config := *params.TestChainConfig
+		config.Dosash = tt.dosash
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(dosdb.NewMemDatabase()))
+		header := &types.Header{Number: big.NewInt(5), Coinbase: common.Address{1}}
+		uncle := &types.Header{Number: big.NewInt(4), Coinbase: common.Address{2}}
+		accumulateRewards(&config, statedb, header, []*types.Header{uncle})
+
+		if balance := statedb.GetBalance(header.Coinbase); balance.Cmp(big.NewInt(tt.miner)) != 0 {
+			t.Errorf("test %d: miner reward mismatch: have %v, want %v", i, balance, tt.miner)
+		}
+		if balance := statedb.GetBalance(uncle.Coinbase); balance.Cmp(big.NewInt(tt.uncle)) != 0 {
+			t.Errorf("test %d: uncle reward mismatch: have %v, want %v", i, balance, tt.uncle)
+		}
+		if balance := statedb.GetBalance(treasury); balance.Cmp(big.NewInt(tt.fund)) != 0 {
+			t.Errorf("test %d: treasury reward mismatch: have %v, want %v", i, balance, tt.fund)
+		}
+	}
+}
+
+// testerChainReader implements consensus.ChainReader over a set of headers.
+type testerChainReader struct {
+	config  *params.ChainConfig
+	headers map[common.Hash]*types.Header
+}
+
+func (r *testerChainReader) Config() *params.ChainConfig            { return r.config }
+func (r *testerChainReader) CurrentHeader() *types.Header           { panic("not supported") }
+func (r *testerChainReader) GetHeaderByNumber(uint64) *types.Header { panic("not supported") }
+func (r *testerChainReader) GetBlock(common.Hash, uint64) *types.Block {
+	panic("not supported")
+}
+func (r *testerChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	return r.headers[hash]
+}
+func (r *testerChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := r.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+// Tests that a batch of headers longer than the LWMA window verifies, the
+// window being filled with the preceding headers of the batch not yet in the
+// chain.
+func TestVerifyHeadersLWMA(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Dosash = &params.DosashConfig{LWMABlock: big.NewInt(0), LWMAWindow: 5, LWMATarget: 10}
+
+	genesis := &types.Header{
+		Number:     big.NewInt(0),
+		Time:       big.NewInt(0),
+		Difficulty: big.NewInt(131072),
+		GasLimit:   params.GenesisGasLimit,
+	}
+	all := map[common.Hash]*types.Header{genesis.Hash(): genesis}
+	lookup := func(hash common.Hash, number uint64) *types.Header {
+		if header := all[hash]; header != nil && header.Number.Uint64() == number {
+			return header
+		}
+		return nil
+	}
+	var (
+		headers []*types.Header
+		parent  = genesis
+	)
+	for i := 1; i <= 20; i++ {
+		difficulty, err := CalcDifficultyLWMA(config.Dosash, parent, lookup)
+		if err != nil {
+			t.Fatalf("block %d: failed to calculate difficulty: %v", i, err)
+		}
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(int64(i)),
+			Time:       new(big.Int).Add(parent.Time, big.NewInt(int64(1+i%7*3))),
+			Difficulty: difficulty,
+			GasLimit:   params.GenesisGasLimit,
+		}
+		all[header.Hash()] = header
+		headers = append(headers, header)
+		parent = header
+	}
+	chain := &testerChainReader{config: &config, headers: map[common.Hash]*types.Header{genesis.Hash(): genesis}}
+
+	_, results := NewFaker().VerifyHeaders(chain, headers, make([]bool, len(headers)))
+	for i := range headers {
+		if err := <-results; err != nil {
+			t.Errorf("header %d: verification failed: %v", i+1, err)
+		}
+	}
+	// A header whose window can't be gathered must be rejected, not verified
+	// against a shorter window
+	chain.headers[headers[len(headers)-2].Hash()] = headers[len(headers)-2]
+	if err := NewFaker().VerifyHeader(chain, headers[len(headers)-1], false); err != consensus.ErrUnknownAncestor {
+		t.Errorf("gapped header verification mismatch: have %v, want %v", err, consensus.ErrUnknownAncestor)
+	}
+	// Calculating the difficulty without the window must fall back to the parent's
+	parent = headers[len(headers)-2]
+	if difficulty := NewFaker().CalcDifficulty(chain, parent.Time.Uint64()+10, parent); difficulty == nil || difficulty.Cmp(parent.Difficulty) != 0 {
+		t.Errorf("gapped difficulty mismatch: have %v, want %v", difficulty, parent.Difficulty)
+	}
+}
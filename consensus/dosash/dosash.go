@@ -565,10 +565,14 @@ func (dosash *Dosash) Hashrate() float64 {
 	return dosash.hashrate.Rate1()
 }
 
-// APIs implements consensus.Engine, returning the user facing RPC APIs. Currently
-// that is empty.
+// APIs implements consensus.Engine, returning the user facing RPC APIs.
 func (dosash *Dosash) APIs(chain consensus.ChainReader) []rpc.API {
-	return nil
+	return []rpc.API{{
+		Namespace: "dosash",
+		Version:   "1.0",
+		Service:   &API{chain: chain},
+		Public:    true,
+	}}
 }
 
 // SeedHash is the seed to use for generating a verification cache and the mining
@@ -0,0 +1,105 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package ibft
+
+import (
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/rpc"
+)
+
+// API is a user facing RPC API to allow controlling the validator voting of the
+// Byzantine fault tolerant scheme.
+type API struct {
+	chain consensus.ChainReader
+	ibft  *Ibft
+}
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	// Retrieve the requested block number (or current if none requested)
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	// Ensure we have an actually valid block and return its snapshot
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.ibft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetSnapshotAtHash retrieves the state snapshot at a given block.
+func (api *API) GetSnapshotAtHash(hash common.Hash) (*Snapshot, error) {
+	header := api.chain.GetHeaderByHash(hash)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.ibft.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetValidators retrieves the list of validators at the specified block.
+func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.validators(), nil
+}
+
+// GetValidatorsAtHash retrieves the list of validators at the specified block.
+func (api *API) GetValidatorsAtHash(hash common.Hash) ([]common.Address, error) {
+	snap, err := api.GetSnapshotAtHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	return snap.validators(), nil
+}
+
+// Proposals returns the current proposals the node tries to uphold and vote on.
+func (api *API) Proposals() map[common.Address]bool {
+	api.ibft.lock.RLock()
+	defer api.ibft.lock.RUnlock()
+
+	proposals := make(map[common.Address]bool)
+	for address, auth := range api.ibft.proposals {
+		proposals[address] = auth
+	}
+	return proposals
+}
+
+// Propose injects a new validator proposal that the node will attempt to push
+// through, adding the address to the validators if auth is set or removing it
+// otherwise.
+func (api *API) Propose(address common.Address, auth bool) {
+	api.ibft.lock.Lock()
+	defer api.ibft.lock.Unlock()
+
+	api.ibft.proposals[address] = auth
+}
+
+// Discard drops a currently running proposal, stopping the node from casting
+// further votes (either for or against).
+func (api *API) Discard(address common.Address) {
+	api.ibft.lock.Lock()
+	defer api.ibft.lock.Unlock()
+
+	delete(api.ibft.proposals, address)
+}
@@ -0,0 +1,693 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ibft implements the Istanbul Byzantine fault tolerant consensus engine.
+//
+// Blocks are agreed on by a set of validators in rounds: the proposer of a round
+// broadcasts its block (pre-prepare), the validators accepting it announce so
+// (prepare) and, once a quorum prepared it, sign their commitment (commit). A
+// block carrying the commit seals of a quorum in its extra-data is final.
+package ibft
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/doslink/dos/accounts"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/consensus/misc"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/crypto/sha3"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rlp"
+	"github.com/doslink/dos/rpc"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
+	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block proposers to keep in memory
+)
+
+// IBFT protocol constants.
+var (
+	epochLength    = uint64(30000)            // Default number of blocks after which to checkpoint and reset the pending votes
+	requestTimeout = 10000 * time.Millisecond // Default time to wait for a round to commit before changing it
+
+	nonceAuthVote = hexutil.MustDecode("0xffffffffffffffff") // Magic nonce number to vote on adding a new validator
+	nonceDropVote = hexutil.MustDecode("0x0000000000000000") // Magic nonce number to vote on removing a validator.
+
+	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
+
+	defaultDifficulty = big.NewInt(1) // Block difficulty, constant as blocks are final
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of validators is requested for a
+	// block that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidCheckpointBeneficiary is returned if a checkpoint/epoch transition
+	// block has a beneficiary set to non-zeroes.
+	errInvalidCheckpointBeneficiary = errors.New("beneficiary in checkpoint block non-zero")
+
+	// errInvalidVote is returned if a nonce value is something else that the two
+	// allowed constants of 0x00..0 or 0xff..f.
+	errInvalidVote = errors.New("vote nonce not 0x00..0 or 0xff..f")
+
+	// errInvalidCheckpointVote is returned if a checkpoint/epoch transition block
+	// has a vote nonce set to non-zeroes.
+	errInvalidCheckpointVote = errors.New("vote nonce in checkpoint block non-zero")
+
+	// errExtraValidators is returned if non-checkpoint block contain validator
+	// data in their extra-data fields.
+	errExtraValidators = errors.New("non-checkpoint block contains extra validator list")
+
+	// errInvalidCheckpointValidators is returned if a checkpoint block contains a
+	// different list of validators than the local snapshot.
+	errInvalidCheckpointValidators = errors.New("invalid validator list on checkpoint block")
+
+	// errInvalidMixDigest is returned if a block's mix digest is not the IBFT one.
+	errInvalidMixDigest = errors.New("invalid ibft mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not 1.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// ErrInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errInvalidVotingChain is returned if a validator set is attempted to be
+	// modified via out-of-range or non-contiguous headers.
+	errInvalidVotingChain = errors.New("invalid voting chain")
+
+	// errUnauthorized is returned if a header is proposed by a non-validator.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errInvalidCommittedSeals is returned if a committed seal is not signed by a
+	// validator, or a validator signed more than once.
+	errInvalidCommittedSeals = errors.New("invalid committed seals")
+
+	// errInsufficientCommittedSeals is returned if a block carries commit seals
+	// from less validators than the quorum.
+	errInsufficientCommittedSeals = errors.New("insufficient committed seals")
+
+	// errWaitTransactions is returned if an empty block is attempted to be sealed
+	// on an instant chain (0 second period).
+	errWaitTransactions = errors.New("waiting for transactions")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// sigHash returns the hash which is used as input for the proposer seal. It is
+// the hash of the entire header apart from the proposer and committed seals.
+func sigHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+
+	rlp.Encode(hasher, types.IBFTFilteredHeader(header, false))
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// commitHash returns the hash which is signed by the validators to commit to the
+// block with the given hash.
+func commitHash(hash common.Hash) []byte {
+	return crypto.Keccak256(hash.Bytes(), []byte{msgCommit})
+}
+
+// ecrecover extracts the Doslink account address of the proposer from a sealed
+// header.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// If the proposer's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Retrieve the signature from the header extra-data
+	extra, err := types.ExtractIBFTExtra(header)
+	if err != nil {
+		return common.Address{}, err
+	}
+	proposer, err := recoverAddress(sigHash(header).Bytes(), extra.Seal)
+	if err != nil {
+		return common.Address{}, err
+	}
+	sigcache.Add(hash, proposer)
+	return proposer, nil
+}
+
+// recoverAddress returns the Doslink address of the account that signed a hash.
+func recoverAddress(hash []byte, sig []byte) (common.Address, error) {
+	pubkey, err := crypto.Ecrecover(hash, sig)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	return signer, nil
+}
+
+// Ibft is the Byzantine fault tolerant consensus engine, providing immediate
+// finality to the blocks agreed on by a permissioned set of validators.
+type Ibft struct {
+	config *params.IbftConfig // Consensus engine configuration parameters
+	db     dosdb.Database     // Database to store and retrieve snapshot checkpoints
+
+	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
+	signatures *lru.ARCCache // Proposers of recent blocks to speed up mining
+
+	proposals map[common.Address]bool // Current list of proposals we are pushing
+
+	signer common.Address // Doslink address of the signing key
+	signFn SignerFn       // Signer function to authorize hashes with
+	lock   sync.RWMutex   // Protects the signer fields
+
+	rounds   *roundState        // Round state machine agreeing on the blocks
+	peers    *peerSet           // Validators connected via the consensus sub-protocol
+	commitFn func(*types.Block) // Callback to import blocks committed on remote proposals
+}
+
+// New creates an IBFT consensus engine with the initial validators set to the
+// ones in the genesis block.
+func New(config *params.IbftConfig, db dosdb.Database) *Ibft {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	if conf.RequestTimeout == 0 {
+		conf.RequestTimeout = uint64(requestTimeout / time.Millisecond)
+	}
+	// Allocate the snapshot caches and create the engine
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	ibft := &Ibft{
+		config:     &conf,
+		db:         db,
+		recents:    recents,
+		signatures: signatures,
+		proposals:  make(map[common.Address]bool),
+		peers:      newPeerSet(),
+	}
+	ibft.rounds = newRoundState(ibft)
+	return ibft
+}
+
+// Author implements consensus.Engine, returning the Doslink address recovered
+// from the proposer seal in the header's extra-data section.
+func (c *Ibft) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, c.signatures)
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (c *Ibft) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return c.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (c *Ibft) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := c.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules, including
+// its finality via the committed seals. The caller may optionally pass in a batch
+// of parents (ascending order) to avoid looking those up from the database.
+func (c *Ibft) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if err := c.verifyProposal(chain, header, parents); err != nil {
+		return err
+	}
+	if header.Number.Uint64() == 0 {
+		return nil
+	}
+	return c.verifyCommittedSeals(chain, header, parents)
+}
+
+// verifyProposal checks whether a header conforms to the consensus rules apart
+// from the committed seals, which a proposal does not have yet.
+func (c *Ibft) verifyProposal(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Don't waste time checking blocks from the future
+	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Ensure that the extra-data contains a validator list on checkpoint, but none otherwise
+	extra, err := types.ExtractIBFTExtra(header)
+	if err != nil {
+		return err
+	}
+	checkpoint := (number % c.config.Epoch) == 0
+	if !checkpoint && len(extra.Validators) != 0 {
+		return errExtraValidators
+	}
+	// The genesis block is the always valid dead-end
+	if number == 0 {
+		return nil
+	}
+	// Checkpoint blocks need to enforce zero beneficiary
+	if checkpoint && header.Coinbase != (common.Address{}) {
+		return errInvalidCheckpointBeneficiary
+	}
+	// Nonces must be 0x00..0 or 0xff..f, zeroes enforced on checkpoints
+	if !bytes.Equal(header.Nonce[:], nonceAuthVote) && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidVote
+	}
+	if checkpoint && !bytes.Equal(header.Nonce[:], nonceDropVote) {
+		return errInvalidCheckpointVote
+	}
+	// Ensure that the mix digest marks the block as IBFT sealed
+	if header.MixDigest != types.IBFTDigest {
+		return errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in BFT
+	if header.UncleHash != uncleHash {
+		return errInvalidUncleHash
+	}
+	if header.Difficulty == nil || header.Difficulty.Cmp(defaultDifficulty) != 0 {
+		return errInvalidDifficulty
+	}
+	// If all checks passed, validate any special fields for hard forks
+	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
+		return err
+	}
+	// All basic checks passed, verify cascading fields
+	return c.verifyCascadingFields(chain, header, extra, parents)
+}
+
+// verifyCascadingFields verifies all the header fields that are not standalone,
+// rather depend on a batch of previous headers. The caller may optionally pass
+// in a batch of parents (ascending order) to avoid looking those up from the
+// database. This is useful for concurrently verifying a batch of new headers.
+func (c *Ibft) verifyCascadingFields(chain consensus.ChainReader, header *types.Header, extra *types.IBFTExtra, parents []*types.Header) error {
+	// Ensure that the block's timestamp isn't too close to it's parent
+	number := header.Number.Uint64()
+
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time.Uint64()+c.config.Period > header.Time.Uint64() {
+		return ErrInvalidTimestamp
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// If the block is a checkpoint block, verify the validator list
+	if number%c.config.Epoch == 0 {
+		validators := snap.validators()
+		if len(validators) != len(extra.Validators) {
+			return errInvalidCheckpointValidators
+		}
+		for i, validator := range validators {
+			if extra.Validators[i] != validator {
+				return errInvalidCheckpointValidators
+			}
+		}
+	}
+	// All basic checks passed, verify the proposer seal
+	proposer, err := ecrecover(header, c.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[proposer]; !ok {
+		return errUnauthorized
+	}
+	return nil
+}
+
+// verifyCommittedSeals checks whether the committed seals of a header were signed
+// by a quorum of distinct validators.
+func (c *Ibft) verifyCommittedSeals(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	number := header.Number.Uint64()
+
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	extra, err := types.ExtractIBFTExtra(header)
+	if err != nil {
+		return err
+	}
+	hash := commitHash(header.Hash())
+
+	signers := make(map[common.Address]struct{})
+	for _, seal := range extra.CommittedSeal {
+		signer, err := recoverAddress(hash, seal)
+		if err != nil {
+			return errInvalidCommittedSeals
+		}
+		if _, ok := snap.Validators[signer]; !ok {
+			return errInvalidCommittedSeals
+		}
+		if _, ok := signers[signer]; ok {
+			return errInvalidCommittedSeals
+		}
+		signers[signer] = struct{}{}
+	}
+	if len(signers) < snap.quorum() {
+		return errInsufficientCommittedSeals
+	}
+	return nil
+}
+
+// snapshot retrieves the validator snapshot at a given point in time.
+func (c *Ibft) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or on disk for checkpoints
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := c.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// If an on-disk checkpoint snapshot can be found, use that
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(c.config, c.signatures, c.db, hash); err == nil {
+				log.Trace("Loaded voting snapshot form disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// If we're at block zero, make a snapshot
+		if number == 0 {
+			genesis := chain.GetHeaderByNumber(0)
+			if err := c.VerifyHeader(chain, genesis, false); err != nil {
+				return nil, err
+			}
+			extra, err := types.ExtractIBFTExtra(genesis)
+			if err != nil {
+				return nil, err
+			}
+			snap = newSnapshot(c.config, c.signatures, 0, genesis.Hash(), extra.Validators)
+			if err := snap.store(c.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored genesis voting snapshot to disk")
+			break
+		}
+		// No snapshot for this header, gather the header and move backward
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	c.recents.Add(snap.Hash, snap)
+
+	// If we've generated a new checkpoint snapshot, save to disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(c.db); err != nil {
+			return nil, err
+		}
+		log.Trace("Stored voting snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (c *Ibft) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the proposer seal and
+// the committed seals contained in the header satisfy the consensus protocol
+// requirements.
+func (c *Ibft) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	proposer, err := ecrecover(header, c.signatures)
+	if err != nil {
+		return err
+	}
+	if _, ok := snap.Validators[proposer]; !ok {
+		return errUnauthorized
+	}
+	return c.verifyCommittedSeals(chain, header, nil)
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (c *Ibft) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	// If the block isn't a checkpoint, cast a random vote (good enough for now)
+	header.Coinbase = common.Address{}
+	header.Nonce = types.BlockNonce{}
+
+	number := header.Number.Uint64()
+	// Assemble the voting snapshot to check which votes make sense
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	if number%c.config.Epoch != 0 {
+		c.lock.RLock()
+
+		// Gather all the proposals that make sense voting on
+		addresses := make([]common.Address, 0, len(c.proposals))
+		for address, authorize := range c.proposals {
+			if snap.validVote(address, authorize) {
+				addresses = append(addresses, address)
+			}
+		}
+		// If there's pending proposals, cast a vote on them
+		if len(addresses) > 0 {
+			header.Coinbase = addresses[rand.Intn(len(addresses))]
+			if c.proposals[header.Coinbase] {
+				copy(header.Nonce[:], nonceAuthVote)
+			} else {
+				copy(header.Nonce[:], nonceDropVote)
+			}
+		}
+		c.lock.RUnlock()
+	}
+	// Blocks are final, so the difficulty carries no meaning
+	header.Difficulty = new(big.Int).Set(defaultDifficulty)
+
+	// Assemble the extra data with an empty seal, adding the validators on checkpoints
+	extra := &types.IBFTExtra{}
+	if number%c.config.Epoch == 0 {
+		extra.Validators = snap.validators()
+	}
+	vanity := header.Extra
+	if len(vanity) > types.IBFTExtraVanity {
+		vanity = vanity[:types.IBFTExtraVanity]
+	}
+	if header.Extra, err = types.EncodeIBFTExtra(vanity, extra); err != nil {
+		return err
+	}
+	// Mix digest marks the block hash to exclude the committed seals
+	header.MixDigest = types.IBFTDigest
+
+	// Ensure the timestamp has the correct delay
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(c.config.Period))
+	if header.Time.Int64() < time.Now().Unix() {
+		header.Time = big.NewInt(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize implements consensus.Engine, ensuring no uncles are set, nor block
+// rewards given, and returns the final block.
+func (c *Ibft) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	// No block rewards in BFT, so the state remains as is and uncles are dropped
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	// Assemble and return the final block for sealing
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// Authorize injects a private key into the consensus engine to propose, prepare
+// and commit blocks with.
+func (c *Ibft) Authorize(signer common.Address, signFn SignerFn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.signer = signer
+	c.signFn = signFn
+}
+
+// sign signs a hash with the local validator key.
+func (c *Ibft) sign(hash []byte) ([]byte, error) {
+	c.lock.RLock()
+	signer, signFn := c.signer, c.signFn
+	c.lock.RUnlock()
+
+	if signFn == nil {
+		return nil, errUnauthorized
+	}
+	return signFn(accounts.Account{Address: signer}, hash)
+}
+
+// Seal implements consensus.Engine, proposing the block to the validators and
+// waiting until a quorum of them committed to it.
+func (c *Ibft) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	if c.config.Period == 0 && len(block.Transactions()) == 0 {
+		return nil, errWaitTransactions
+	}
+	// Bail out if we're not a validator
+	c.lock.RLock()
+	signer := c.signer
+	c.lock.RUnlock()
+
+	snap, err := c.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, authorized := snap.Validators[signer]; !authorized {
+		return nil, errUnauthorized
+	}
+	// Wait until the block is due before proposing it
+	delay := time.Unix(header.Time.Int64(), 0).Sub(time.Now()) // nolint: gosimple
+	log.Trace("Waiting for slot to propose", "delay", common.PrettyDuration(delay))
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	// Add the proposer seal and hand the block to the round state machine
+	extra, err := types.ExtractIBFTExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	if extra.Seal, err = c.sign(sigHash(header).Bytes()); err != nil {
+		return nil, err
+	}
+	if header.Extra, err = types.EncodeIBFTExtra(header.Extra[:types.IBFTExtraVanity], extra); err != nil {
+		return nil, err
+	}
+	result, err := c.rounds.request(chain, block.WithSeal(header), snap)
+	if err != nil {
+		return nil, err
+	}
+	select {
+	case <-stop:
+		return nil, nil
+	case block := <-result:
+		return block, nil
+	}
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
+// that a new block should have, which is constant for IBFT.
+func (c *Ibft) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return new(big.Int).Set(defaultDifficulty)
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to allow
+// controlling the validator voting.
+func (c *Ibft) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "ibft",
+		Version:   "1.0",
+		Service:   &API{chain: chain, ibft: c},
+		Public:    false,
+	}}
+}
@@ -0,0 +1,509 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package ibft
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/accounts"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/p2p"
+	"github.com/doslink/dos/params"
+)
+
+type testerVote struct {
+	validator string
+	voted     string
+	auth      bool
+}
+
+// testerAccountPool is a pool to maintain currently active tester accounts,
+// mapped from textual names used in the tests below to actual Doslink private
+// keys capable of signing transactions.
+type testerAccountPool struct {
+	accounts map[string]*ecdsa.PrivateKey
+}
+
+func newTesterAccountPool() *testerAccountPool {
+	return &testerAccountPool{
+		accounts: make(map[string]*ecdsa.PrivateKey),
+	}
+}
+
+func (ap *testerAccountPool) key(account string) *ecdsa.PrivateKey {
+	// Ensure we have a persistent key for the account
+	if ap.accounts[account] == nil {
+		ap.accounts[account], _ = crypto.GenerateKey()
+	}
+	return ap.accounts[account]
+}
+
+func (ap *testerAccountPool) sign(header *types.Header, validator string) {
+	// Sign the header and embed the proposer seal in extra data
+	extra, _ := types.ExtractIBFTExtra(header)
+	extra.Seal, _ = crypto.Sign(sigHash(header).Bytes(), ap.key(validator))
+	header.Extra, _ = types.EncodeIBFTExtra(nil, extra)
+}
+
+func (ap *testerAccountPool) address(account string) common.Address {
+	return crypto.PubkeyToAddress(ap.key(account).PublicKey)
+}
+
+// sortAddresses sorts a list of addresses in ascending order.
+func sortAddresses(addresses []common.Address) {
+	for i := 0; i < len(addresses); i++ {
+		for j := i + 1; j < len(addresses); j++ {
+			if bytes.Compare(addresses[i][:], addresses[j][:]) > 0 {
+				addresses[i], addresses[j] = addresses[j], addresses[i]
+			}
+		}
+	}
+}
+
+// testerChainReader implements consensus.ChainReader to access the genesis
+// block. All other methods and requests will panic.
+type testerChainReader struct {
+	db dosdb.Database
+}
+
+func (r *testerChainReader) Config() *params.ChainConfig                 { return params.AllCliqueProtocolChanges }
+func (r *testerChainReader) CurrentHeader() *types.Header                { panic("not supported") }
+func (r *testerChainReader) GetHeader(common.Hash, uint64) *types.Header { panic("not supported") }
+func (r *testerChainReader) GetBlock(common.Hash, uint64) *types.Block   { panic("not supported") }
+func (r *testerChainReader) GetHeaderByHash(common.Hash) *types.Header   { panic("not supported") }
+func (r *testerChainReader) GetHeaderByNumber(number uint64) *types.Header {
+	if number == 0 {
+		return rawdb.ReadHeader(r.db, rawdb.ReadCanonicalHash(r.db, 0), 0)
+	}
+	panic("not supported")
+}
+
+// Tests that validator voting is evaluated correctly for various scenarios.
+func TestVoting(t *testing.T) {
+	tests := []struct {
+		epoch      uint64
+		validators []string
+		votes      []testerVote
+		results    []string
+	}{
+		{
+			// Single validator, no votes cast
+			validators: []string{"A"},
+			votes:      []testerVote{{validator: "A"}},
+			results:    []string{"A"},
+		}, {
+			// Single validator, voting to add two others (only accept first, second needs 2 votes)
+			validators: []string{"A"},
+			votes: []testerVote{
+				{validator: "A", voted: "B", auth: true},
+				{validator: "B"},
+				{validator: "A", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		}, {
+			// Four validators, removing one needs three votes
+			validators: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{validator: "A", voted: "D"},
+				{validator: "B", voted: "D"},
+				{validator: "A"},
+			},
+			results: []string{"A", "B", "C", "D"},
+		}, {
+			// Four validators, removing one with a majority
+			validators: []string{"A", "B", "C", "D"},
+			votes: []testerVote{
+				{validator: "A", voted: "D"},
+				{validator: "B", voted: "D"},
+				{validator: "C", voted: "D"},
+			},
+			results: []string{"A", "B", "C"},
+		}, {
+			// Votes from the same validator replace each other
+			validators: []string{"A", "B"},
+			votes: []testerVote{
+				{validator: "A", voted: "C", auth: true},
+				{validator: "A", voted: "C", auth: true},
+				{validator: "A", voted: "D", auth: true},
+				{validator: "B", voted: "D", auth: true},
+			},
+			results: []string{"A", "B", "D"},
+		}, {
+			// Pending votes are reset on epoch checkpoints
+			epoch:      3,
+			validators: []string{"A", "B"},
+			votes: []testerVote{
+				{validator: "A", voted: "C", auth: true},
+				{validator: "B"},
+				{validator: "A"},
+				{validator: "B", voted: "C", auth: true},
+			},
+			results: []string{"A", "B"},
+		},
+	}
+	for i, tt := range tests {
+		// Create the account pool and generate the initial set of validators
+		accounts := newTesterAccountPool()
+
+		validators := make([]common.Address, len(tt.validators))
+		for j, validator := range tt.validators {
+			validators[j] = accounts.address(validator)
+		}
+		sortAddresses(validators)
+
+		// Create the genesis block with the initial set of validators
+		extra, _ := types.EncodeIBFTExtra(nil, &types.IBFTExtra{Validators: validators})
+		genesis := &core.Genesis{ExtraData: extra}
+
+		db := dosdb.NewMemDatabase()
+		genesis.Commit(db)
+
+		// Assemble a chain of headers from the cast votes
+		config := &params.IbftConfig{Epoch: tt.epoch}
+		if config.Epoch == 0 {
+			config.Epoch = epochLength
+		}
+		empty, _ := types.EncodeIBFTExtra(nil, new(types.IBFTExtra))
+
+		headers := make([]*types.Header, len(tt.votes))
+		for j, vote := range tt.votes {
+			headers[j] = &types.Header{
+				Number:    big.NewInt(int64(j) + 1),
+				Time:      big.NewInt(int64(j)),
+				MixDigest: types.IBFTDigest,
+				Extra:     empty,
+			}
+			if vote.voted != "" {
+				headers[j].Coinbase = accounts.address(vote.voted)
+			}
+			if j > 0 {
+				headers[j].ParentHash = headers[j-1].Hash()
+			}
+			if vote.auth {
+				copy(headers[j].Nonce[:], nonceAuthVote)
+			}
+			accounts.sign(headers[j], vote.validator)
+		}
+		// Pass all the headers through the engine and ensure tallying succeeds
+		head := headers[len(headers)-1]
+
+		snap, err := New(config, db).snapshot(&testerChainReader{db: db}, head.Number.Uint64(), head.Hash(), headers)
+		if err != nil {
+			t.Errorf("test %d: failed to create voting snapshot: %v", i, err)
+			continue
+		}
+		// Verify the final list of validators against the expected ones
+		validators = make([]common.Address, len(tt.results))
+		for j, validator := range tt.results {
+			validators[j] = accounts.address(validator)
+		}
+		sortAddresses(validators)
+
+		result := snap.validators()
+		if len(result) != len(validators) {
+			t.Errorf("test %d: validators mismatch: have %x, want %x", i, result, validators)
+			continue
+		}
+		for j := 0; j < len(result); j++ {
+			if result[j] != validators[j] {
+				t.Errorf("test %d, validator %d: validator mismatch: have %x, want %x", i, j, result[j], validators[j])
+			}
+		}
+	}
+}
+
+// testerValidator is a validator node of a simulated IBFT network.
+type testerValidator struct {
+	key       *ecdsa.PrivateKey
+	engine    *Ibft
+	chain     *core.BlockChain
+	committed chan *types.Block
+}
+
+// newTesterNetwork creates a set of validators running on the same genesis,
+// interconnected via the consensus sub-protocol.
+func newTesterNetwork(t *testing.T, n int) []*testerValidator {
+	keys := make([]*ecdsa.PrivateKey, n)
+	validators := make([]common.Address, n)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		validators[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	// Order the validators by address so their index matches the proposer turns
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if bytes.Compare(validators[i][:], validators[j][:]) > 0 {
+				validators[i], validators[j] = validators[j], validators[i]
+				keys[i], keys[j] = keys[j], keys[i]
+			}
+		}
+	}
+	extra, _ := types.EncodeIBFTExtra(nil, &types.IBFTExtra{Validators: validators})
+
+	config := *params.AllCliqueProtocolChanges
+	config.Clique, config.Ibft = nil, &params.IbftConfig{Period: 1, RequestTimeout: 200}
+
+	nodes := make([]*testerValidator, n)
+	for i := range nodes {
+		db := dosdb.NewMemDatabase()
+		genesis := &core.Genesis{Config: &config, ExtraData: extra, Difficulty: big.NewInt(1)}
+		genesis.MustCommit(db)
+
+		engine := New(config.Ibft, db)
+		chain, err := core.NewBlockChain(db, nil, &config, engine, vm.Config{})
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		key := keys[i]
+		engine.Authorize(validators[i], func(account accounts.Account, hash []byte) ([]byte, error) {
+			return crypto.Sign(hash, key)
+		})
+		nodes[i] = &testerValidator{key: key, engine: engine, chain: chain, committed: make(chan *types.Block, 1)}
+
+		node := nodes[i]
+		engine.Start(chain, func(block *types.Block) { node.committed <- block })
+	}
+	// Connect every validator to all the others
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			rw1, rw2 := p2p.MsgPipe()
+			go nodes[i].engine.handlePeer(fmt.Sprintf("peer-%d", j), rw1)
+			go nodes[j].engine.handlePeer(fmt.Sprintf("peer-%d", i), rw2)
+		}
+	}
+	return nodes
+}
+
+// propose assembles the next block on top of a validator's chain.
+func (v *testerValidator) propose(t *testing.T) *types.Block {
+	parent := v.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   parent.GasLimit(),
+	}
+	if err := v.engine.Prepare(v.chain, header); err != nil {
+		t.Fatalf("failed to prepare header: %v", err)
+	}
+	statedb, err := v.chain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve state: %v", err)
+	}
+	block, err := v.engine.Finalize(v.chain, header, statedb, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to finalize block: %v", err)
+	}
+	return block
+}
+
+// testCommit runs a round of consensus on the given validators of a network,
+// ensuring the expected proposer seals a block committed by all of them.
+func testCommit(t *testing.T, nodes []*testerValidator, online []int, proposer int) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	sealed := make(chan *types.Block, len(nodes))
+	for _, i := range online {
+		block := nodes[i].propose(t)
+		go func(v *testerValidator) {
+			result, err := v.engine.Seal(v.chain, block, stop)
+			if err != nil {
+				t.Errorf("failed to seal block: %v", err)
+			}
+			if result != nil {
+				sealed <- result
+			}
+		}(nodes[i])
+	}
+	var final *types.Block
+	select {
+	case final = <-sealed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("proposer failed to seal block")
+	}
+	if author, _ := nodes[proposer].engine.Author(final.Header()); author != crypto.PubkeyToAddress(nodes[proposer].key.PublicKey) {
+		t.Errorf("proposer mismatch: have %x, want %x", author, crypto.PubkeyToAddress(nodes[proposer].key.PublicKey))
+	}
+	// Ensure every other online validator committed the same block
+	for _, i := range online {
+		if i == proposer {
+			continue
+		}
+		select {
+		case block := <-nodes[i].committed:
+			if block.Hash() != final.Hash() {
+				t.Errorf("validator %d: committed hash mismatch: have %x, want %x", i, block.Hash(), final.Hash())
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("validator %d: failed to commit block", i)
+		}
+	}
+	// Ensure the committed block is final and accepted by the chain
+	extra, err := types.ExtractIBFTExtra(final.Header())
+	if err != nil {
+		t.Fatalf("failed to decode extra-data: %v", err)
+	}
+	if len(extra.CommittedSeal) < (2*len(nodes)+2)/3 {
+		t.Errorf("committed seals mismatch: have %d, want at least %d", len(extra.CommittedSeal), (2*len(nodes)+2)/3)
+	}
+	if _, err := nodes[proposer].chain.InsertChain(types.Blocks{final}); err != nil {
+		t.Fatalf("failed to import committed block: %v", err)
+	}
+}
+
+// Tests that a quorum of validators commits the block of the round's proposer.
+func TestCommit(t *testing.T) {
+	nodes := newTesterNetwork(t, 4)
+	defer func() {
+		for _, node := range nodes {
+			node.engine.Stop()
+			node.chain.Stop()
+		}
+	}()
+	// The proposer of the first round of block 1 is the second validator
+	testCommit(t, nodes, []int{0, 1, 2, 3}, 1)
+}
+
+// Tests that the validators change the round if the proposer is offline, and
+// commit the block of the next proposer.
+func TestRoundChange(t *testing.T) {
+	nodes := newTesterNetwork(t, 4)
+	defer func() {
+		for _, node := range nodes {
+			node.engine.Stop()
+			node.chain.Stop()
+		}
+	}()
+	// Take the first round's proposer offline by revoking its key, the next one
+	// is the third validator
+	nodes[1].engine.Authorize(common.Address{}, nil)
+	testCommit(t, nodes, []int{0, 2, 3}, 2)
+}
+
+// Tests that blocks with too few or forged committed seals are rejected.
+func TestCommittedSeals(t *testing.T) {
+	nodes := newTesterNetwork(t, 4)
+	defer func() {
+		for _, node := range nodes {
+			node.engine.Stop()
+			node.chain.Stop()
+		}
+	}()
+	block := nodes[1].propose(t)
+	header := block.Header()
+
+	extra, _ := types.ExtractIBFTExtra(header)
+	extra.Seal, _ = crypto.Sign(sigHash(header).Bytes(), nodes[1].key)
+	header.Extra, _ = types.EncodeIBFTExtra(nil, extra)
+
+	want := header.Hash()
+	hash := commitHash(want)
+	for i, node := range nodes[:2] {
+		seal, _ := crypto.Sign(hash, node.key)
+		extra.CommittedSeal = append(extra.CommittedSeal, seal)
+
+		header.Extra, _ = types.EncodeIBFTExtra(nil, extra)
+		if err := nodes[0].engine.VerifySeal(nodes[0].chain, header); err != errInsufficientCommittedSeals {
+			t.Errorf("%d seals: error mismatch: have %v, want %v", i+1, err, errInsufficientCommittedSeals)
+		}
+	}
+	// Sealing twice by the same validator must not count
+	extra.CommittedSeal = append(extra.CommittedSeal, extra.CommittedSeal[0])
+	header.Extra, _ = types.EncodeIBFTExtra(nil, extra)
+	if err := nodes[0].engine.VerifySeal(nodes[0].chain, header); err != errInvalidCommittedSeals {
+		t.Errorf("duplicate seal: error mismatch: have %v, want %v", err, errInvalidCommittedSeals)
+	}
+	seal, _ := crypto.Sign(hash, nodes[2].key)
+	extra.CommittedSeal[2] = seal
+	header.Extra, _ = types.EncodeIBFTExtra(nil, extra)
+	if err := nodes[0].engine.VerifySeal(nodes[0].chain, header); err != nil {
+		t.Errorf("quorum seals: failed to verify: %v", err)
+	}
+	// The committed seals must not change the block hash
+	if hash := header.Hash(); hash != want {
+		t.Errorf("sealed hash mismatch: have %x, want %x", hash, want)
+	}
+}
+
+// Tests that only messages of validators within a small window ahead of the
+// current sequence are backlogged, and that the stale ones are dropped once the
+// sequence moves on.
+func TestFutureMessages(t *testing.T) {
+	nodes := newTesterNetwork(t, 4)
+	defer func() {
+		for _, node := range nodes {
+			node.engine.Stop()
+			node.chain.Stop()
+		}
+	}()
+	outsider, _ := crypto.GenerateKey()
+
+	rounds := nodes[0].engine.rounds
+	signed := func(key *ecdsa.PrivateKey, sequence, round uint64) *message {
+		msg := &message{Code: msgPrepare, Sequence: sequence, Round: round, Payload: make([]byte, common.HashLength)}
+		msg.Signature, _ = crypto.Sign(msg.sigHash(), key)
+		return msg
+	}
+	tests := []struct {
+		msg *message
+		err error
+	}{
+		{signed(outsider, 2, 0), errUnauthorized},
+		{signed(nodes[1].key, 2+maxFutureSequence, 0), errFutureMessage},
+		{signed(nodes[1].key, 2, maxFutureRound+1), errFutureMessage},
+		{signed(nodes[1].key, 2, maxFutureRound), nil},
+		{signed(nodes[2].key, 2, 0), nil},
+	}
+	for i, tt := range tests {
+		if err := rounds.handle(tt.msg); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	backlogged := func() (count int) {
+		rounds.lock.Lock()
+		defer rounds.lock.Unlock()
+
+		for _, msg := range rounds.backlog {
+			if msg.Sequence == 2 {
+				count++
+			}
+		}
+		return count
+	}
+	if count := backlogged(); count != 2 {
+		t.Fatalf("backlogged messages mismatch: have %d, want %d", count, 2)
+	}
+	// Moving past the backlogged sequence must drop its messages
+	rounds.lock.Lock()
+	rounds.startSequence(3, rounds.snap)
+	rounds.lock.Unlock()
+
+	if count := backlogged(); count != 0 {
+		t.Fatalf("stale messages retained: have %d, want %d", count, 0)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package ibft
+
+import (
+	"errors"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/rlp"
+)
+
+// Codes of the messages exchanged by the validators during a round.
+const (
+	msgPreprepare  = 0x00 // Block proposed by the round's proposer
+	msgPrepare     = 0x01 // Acceptance of the proposal by a validator
+	msgCommit      = 0x02 // Commitment to the prepared proposal, carrying a committed seal
+	msgRoundChange = 0x03 // Request to move to a higher round
+)
+
+// errInvalidMessage is returned if a consensus message cannot be decoded or its
+// signature cannot be recovered.
+var errInvalidMessage = errors.New("invalid consensus message")
+
+// message is a signed consensus message of a validator, concerning the block at
+// a given sequence (block number) in a given round.
+type message struct {
+	Code      uint64
+	Sequence  uint64
+	Round     uint64
+	Payload   []byte
+	Signature []byte
+
+	sender common.Address // Validator recovered from the signature, cached
+}
+
+// commitPayload is the payload of a commit message.
+type commitPayload struct {
+	Digest common.Hash // Hash of the committed proposal
+	Seal   []byte      // Commit signature to embed into the final block
+}
+
+// sigHash returns the hash which is signed by the sender of the message.
+func (m *message) sigHash() []byte {
+	blob, _ := rlp.EncodeToBytes([]interface{}{m.Code, m.Sequence, m.Round, m.Payload})
+	return crypto.Keccak256(blob)
+}
+
+// hash returns the unique identifier of the message, used to avoid gossiping it
+// more than once.
+func (m *message) hash() common.Hash {
+	blob, _ := rlp.EncodeToBytes(m)
+	return crypto.Keccak256Hash(blob)
+}
+
+// recover resolves and caches the validator that signed the message.
+func (m *message) recover() (common.Address, error) {
+	if m.sender != (common.Address{}) {
+		return m.sender, nil
+	}
+	sender, err := recoverAddress(m.sigHash(), m.Signature)
+	if err != nil {
+		return common.Address{}, errInvalidMessage
+	}
+	m.sender = sender
+	return sender, nil
+}
+
+// digest decodes the proposal hash of a prepare message.
+func (m *message) digest() (common.Hash, error) {
+	if len(m.Payload) != common.HashLength {
+		return common.Hash{}, errInvalidMessage
+	}
+	return common.BytesToHash(m.Payload), nil
+}
@@ -0,0 +1,210 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package ibft
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/p2p"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Constants to match up protocol versions and messages
+const (
+	protocolName    = "ibft"
+	protocolVersion = 1
+	protocolLength  = 1 // Number of implemented message codes
+
+	consensusMsg = 0x00 // Message code of the validator messages
+
+	maxMessageSize  = 10 * 1024 * 1024 // Maximum cap on the size of a protocol message
+	inmemoryPeerMsg = 1024             // Number of recent messages to remember per peer
+	inmemoryMsg     = 4096             // Number of recent messages to remember overall
+)
+
+var (
+	errMsgTooLarge     = errors.New("message too long")
+	errInvalidMsgCode  = errors.New("invalid message code")
+	errPeerRegistered  = errors.New("peer already registered")
+	errPeerNotRegister = errors.New("peer not registered")
+)
+
+// peer is a remote node speaking the consensus sub-protocol.
+type peer struct {
+	id    string
+	rw    p2p.MsgReadWriter
+	known *lru.ARCCache // Messages known to the peer, to avoid sending them back
+}
+
+// peerSet is the collection of peers speaking the consensus sub-protocol.
+type peerSet struct {
+	peers map[string]*peer
+	seen  *lru.ARCCache // Messages seen locally, to gossip each only once
+	lock  sync.RWMutex
+}
+
+// newPeerSet creates an empty set of consensus peers.
+func newPeerSet() *peerSet {
+	seen, _ := lru.NewARC(inmemoryMsg)
+	return &peerSet{
+		peers: make(map[string]*peer),
+		seen:  seen,
+	}
+}
+
+// register injects a new peer into the working set.
+func (ps *peerSet) register(p *peer) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if _, ok := ps.peers[p.id]; ok {
+		return errPeerRegistered
+	}
+	ps.peers[p.id] = p
+	return nil
+}
+
+// unregister removes a remote peer from the working set.
+func (ps *peerSet) unregister(id string) error {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if _, ok := ps.peers[id]; !ok {
+		return errPeerNotRegister
+	}
+	delete(ps.peers, id)
+	return nil
+}
+
+// peersWithoutMessage retrieves a list of peers that do not know of the message
+// with the given hash, marking it as known to them.
+func (ps *peerSet) peersWithoutMessage(hash common.Hash) []*peer {
+	ps.lock.RLock()
+	defer ps.lock.RUnlock()
+
+	list := make([]*peer, 0, len(ps.peers))
+	for _, p := range ps.peers {
+		if !p.known.Contains(hash) {
+			p.known.Add(hash, struct{}{})
+			list = append(list, p)
+		}
+	}
+	return list
+}
+
+// markSeen marks a message as seen locally, returning whether it was already.
+func (ps *peerSet) markSeen(hash common.Hash) bool {
+	ps.lock.Lock()
+	defer ps.lock.Unlock()
+
+	if ps.seen.Contains(hash) {
+		return true
+	}
+	ps.seen.Add(hash, struct{}{})
+	return false
+}
+
+// Protocol returns the p2p sub-protocol the validators exchange their consensus
+// messages over.
+func (c *Ibft) Protocol() p2p.Protocol {
+	return p2p.Protocol{
+		Name:    protocolName,
+		Version: protocolVersion,
+		Length:  protocolLength,
+		Run: func(p *p2p.Peer, rw p2p.MsgReadWriter) error {
+			return c.handlePeer(p.ID().String(), rw)
+		},
+	}
+}
+
+// Start attaches the engine to the local chain, following its head to agree on
+// the next blocks. Blocks committed on remote proposals are handed to commitFn
+// for import.
+func (c *Ibft) Start(chain consensus.ChainReader, commitFn func(*types.Block)) {
+	c.lock.Lock()
+	c.commitFn = commitFn
+	c.lock.Unlock()
+
+	c.rounds.start(chain)
+}
+
+// Stop terminates the round state machine.
+func (c *Ibft) Stop() {
+	c.rounds.stop()
+}
+
+// handlePeer is the callback invoked to manage the life cycle of a consensus
+// peer. When this function terminates, the peer is disconnected.
+func (c *Ibft) handlePeer(id string, rw p2p.MsgReadWriter) error {
+	known, _ := lru.NewARC(inmemoryPeerMsg)
+	p := &peer{id: id, rw: rw, known: known}
+
+	if err := c.peers.register(p); err != nil {
+		return err
+	}
+	defer c.peers.unregister(id)
+
+	for {
+		msg, err := rw.ReadMsg()
+		if err != nil {
+			return err
+		}
+		if msg.Size > maxMessageSize {
+			msg.Discard()
+			return errMsgTooLarge
+		}
+		if msg.Code != consensusMsg {
+			msg.Discard()
+			return errInvalidMsgCode
+		}
+		m := new(message)
+		if err := msg.Decode(m); err != nil {
+			return err
+		}
+		hash := m.hash()
+		p.known.Add(hash, struct{}{})
+
+		if c.peers.markSeen(hash) {
+			continue
+		}
+		// Only relay the messages of validators that passed validation
+		if err := c.rounds.handle(m); err != nil {
+			log.Trace("Discarded consensus message", "peer", id, "code", m.Code, "sequence", m.Sequence, "round", m.Round, "err", err)
+			continue
+		}
+		c.gossip(m)
+	}
+}
+
+// gossip sends a consensus message to all peers not yet knowing about it.
+func (c *Ibft) gossip(msg *message) {
+	hash := msg.hash()
+	c.peers.markSeen(hash)
+
+	for _, p := range c.peers.peersWithoutMessage(hash) {
+		go func(p *peer) {
+			if err := p2p.Send(p.rw, consensusMsg, msg); err != nil {
+				log.Trace("Failed to send consensus message", "peer", p.id, "err", err)
+			}
+		}(p)
+	}
+}
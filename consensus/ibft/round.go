@@ -0,0 +1,551 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package ibft
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/rlp"
+)
+
+const (
+	maxBacklog        = 1024 // Maximum number of future messages to keep around
+	maxFutureSequence = 1    // Maximum number of sequences a message may be ahead by
+	maxFutureRound    = 16   // Maximum number of rounds a message may be ahead by
+	maxTimeoutShift   = 8    // Maximum number of doublings of the round timeout
+)
+
+var (
+	// errOldMessage is returned if a message concerns an already finished
+	// sequence or round.
+	errOldMessage = errors.New("old consensus message")
+
+	// errFutureMessage is returned if a message concerns a sequence or round too
+	// far ahead of the current one to be kept around.
+	errFutureMessage = errors.New("consensus message too far in the future")
+
+	// errNotStarted is returned if a message arrives before the state machine is
+	// attached to the local chain.
+	errNotStarted = errors.New("consensus not started")
+
+	// errInvalidProposal is returned if a pre-prepared block is not valid for
+	// the current sequence.
+	errInvalidProposal = errors.New("invalid proposal")
+
+	// errLockedProposal is returned if a different block than the one committed
+	// to in an earlier round is pre-prepared.
+	errLockedProposal = errors.New("proposal differs from locked one")
+)
+
+// roundState is the round state machine through which the validators agree on the
+// block of each sequence. All its methods are safe for concurrent use.
+type roundState struct {
+	ibft *Ibft
+	lock sync.Mutex
+
+	chain     consensus.ChainReader // Local chain to verify the proposals against
+	snap      *Snapshot             // Validator set agreeing on the current sequence
+	sequence  uint64                // Number of the block being agreed on
+	round     uint64                // Current round within the sequence
+	requested uint64                // Highest round a change to was requested locally
+	committed bool                  // Whether the current sequence is committed already
+
+	pending *types.Block      // Local block to propose in the current sequence
+	result  chan *types.Block // Channel to deliver the local block on once committed
+	locked  *types.Block      // Proposal committed to, to be re-proposed in later rounds
+
+	proposal   *types.Block                           // Block pre-prepared in the current round
+	prepares   map[common.Address]common.Hash         // Prepared digests of the current round
+	commits    map[common.Address]*commitPayload      // Commits of the current round
+	changes    map[uint64]map[common.Address]struct{} // Requested round changes per future round
+	sentCommit bool                                   // Whether the local commit was sent in the current round
+
+	backlog []*message  // Messages of future sequences or rounds
+	timer   *time.Timer // Timer to request a round change if the round doesn't commit
+}
+
+// newRoundState creates an idle round state machine for the given engine.
+func newRoundState(ibft *Ibft) *roundState {
+	return &roundState{
+		ibft:     ibft,
+		prepares: make(map[common.Address]common.Hash),
+		commits:  make(map[common.Address]*commitPayload),
+		changes:  make(map[uint64]map[common.Address]struct{}),
+	}
+}
+
+// start attaches the local chain the sequences follow.
+func (c *roundState) start(chain consensus.ChainReader) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.chain = chain
+	c.sync()
+}
+
+// stop aborts any running round timer.
+func (c *roundState) stop() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// request schedules a locally sealed block to be proposed in the current
+// sequence, returning the channel the block is delivered on once committed.
+func (c *roundState) request(chain consensus.ChainReader, block *types.Block, snap *Snapshot) (<-chan *types.Block, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.chain == nil {
+		c.chain = chain
+	}
+	c.sync()
+
+	result := make(chan *types.Block, 1)
+	if block.NumberU64() != c.sequence || block.ParentHash() != c.snap.Hash || c.committed {
+		log.Debug("Discarding stale proposal", "number", block.Number(), "hash", block.Hash(), "sequence", c.sequence)
+		return result, nil
+	}
+	c.pending, c.result = block, result
+	c.propose()
+
+	return result, nil
+}
+
+// handle processes a consensus message received from a remote validator.
+func (c *roundState) handle(msg *message) error {
+	if _, err := msg.recover(); err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.sync()
+	return c.process(msg)
+}
+
+// sync moves the state machine to the sequence following the local chain head.
+func (c *roundState) sync() {
+	if c.chain == nil {
+		return
+	}
+	head := c.chain.CurrentHeader()
+	if next := head.Number.Uint64() + 1; c.snap == nil || next > c.sequence {
+		snap, err := c.ibft.snapshot(c.chain, head.Number.Uint64(), head.Hash(), nil)
+		if err != nil {
+			log.Warn("Failed to retrieve validator snapshot", "number", head.Number, "hash", head.Hash(), "err", err)
+			return
+		}
+		c.startSequence(next, snap)
+	}
+}
+
+// startSequence resets the state machine to agree on a new block.
+func (c *roundState) startSequence(sequence uint64, snap *Snapshot) {
+	c.sequence, c.snap = sequence, snap
+	c.requested, c.committed = 0, false
+	c.pending, c.result, c.locked = nil, nil, nil
+	c.changes = make(map[uint64]map[common.Address]struct{})
+
+	// Drop the backlogged messages of finished sequences or unknown validators
+	var backlog []*message
+	for _, msg := range c.backlog {
+		if _, ok := snap.Validators[msg.sender]; ok && msg.Sequence >= sequence {
+			backlog = append(backlog, msg)
+		}
+	}
+	c.backlog = backlog
+
+	c.startRound(0)
+}
+
+// startRound moves the state machine to a new round of the current sequence,
+// proposing a block if the local validator is the proposer of the round.
+func (c *roundState) startRound(round uint64) {
+	log.Trace("Starting consensus round", "sequence", c.sequence, "round", round)
+
+	c.round = round
+	c.proposal, c.sentCommit = nil, false
+	c.prepares = make(map[common.Address]common.Hash)
+	c.commits = make(map[common.Address]*commitPayload)
+	for r := range c.changes {
+		if r <= round {
+			delete(c.changes, r)
+		}
+	}
+	c.resetTimer()
+	c.propose()
+	c.replay()
+}
+
+// resetTimer schedules a round change request if the current round does not
+// commit in time. The timeout doubles with every requested round change.
+func (c *roundState) resetTimer() {
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	if !c.isValidator() {
+		return
+	}
+	shift := c.round
+	if c.requested > shift {
+		shift = c.requested
+	}
+	if shift > maxTimeoutShift {
+		shift = maxTimeoutShift
+	}
+	timeout := time.Duration(c.ibft.config.RequestTimeout) * time.Millisecond << shift
+
+	sequence, round := c.sequence, c.round
+	c.timer = time.AfterFunc(timeout, func() {
+		c.lock.Lock()
+		defer c.lock.Unlock()
+
+		if c.sequence != sequence || c.round != round || c.committed {
+			return
+		}
+		next := c.round + 1
+		if c.requested >= next {
+			next = c.requested + 1
+		}
+		log.Debug("Consensus round timed out", "sequence", sequence, "round", round, "request", next)
+		c.sendRoundChange(next)
+		c.resetTimer()
+	})
+}
+
+// isValidator returns whether the local signer is a validator of the current
+// sequence.
+func (c *roundState) isValidator() bool {
+	c.ibft.lock.RLock()
+	signer := c.ibft.signer
+	c.ibft.lock.RUnlock()
+
+	_, ok := c.snap.Validators[signer]
+	return ok
+}
+
+// isProposer returns whether the local signer is the proposer of the current
+// round.
+func (c *roundState) isProposer() bool {
+	c.ibft.lock.RLock()
+	signer := c.ibft.signer
+	c.ibft.lock.RUnlock()
+
+	return signer != (common.Address{}) && c.snap.proposer(c.round) == signer
+}
+
+// propose broadcasts the block of the local validator if it is the proposer of
+// the current round, preferring the block locked in an earlier round.
+func (c *roundState) propose() {
+	if c.committed || c.proposal != nil || !c.isProposer() {
+		return
+	}
+	block := c.locked
+	if block == nil {
+		block = c.pending
+	}
+	if block == nil {
+		return
+	}
+	payload, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		log.Error("Failed to encode proposal", "err", err)
+		return
+	}
+	log.Debug("Proposing block", "number", block.Number(), "hash", block.Hash(), "round", c.round)
+	c.broadcast(msgPreprepare, c.round, payload)
+}
+
+// process dispatches a message, backlogging it if it concerns a future sequence
+// or round. Future messages are only kept if they were sent by a validator of the
+// current sequence and are within a small window ahead of it.
+func (c *roundState) process(msg *message) error {
+	if c.snap == nil {
+		return errNotStarted
+	}
+	if msg.Sequence < c.sequence {
+		return errOldMessage
+	}
+	round := c.round
+	if msg.Sequence > c.sequence {
+		round = 0
+	}
+	if msg.Sequence > c.sequence+maxFutureSequence || msg.Round > round+maxFutureRound {
+		return errFutureMessage
+	}
+	if _, ok := c.snap.Validators[msg.sender]; !ok {
+		return errUnauthorized
+	}
+	if msg.Sequence > c.sequence {
+		c.store(msg)
+		return nil
+	}
+	if c.committed {
+		return nil
+	}
+	if msg.Code == msgRoundChange {
+		return c.handleRoundChange(msg)
+	}
+	if msg.Round > c.round {
+		c.store(msg)
+		return nil
+	}
+	if msg.Round < c.round {
+		return errOldMessage
+	}
+	switch msg.Code {
+	case msgPreprepare:
+		return c.handlePreprepare(msg)
+	case msgPrepare:
+		return c.handlePrepare(msg)
+	case msgCommit:
+		return c.handleCommit(msg)
+	}
+	return errInvalidMessage
+}
+
+// store adds a message of a future sequence or round to the backlog.
+func (c *roundState) store(msg *message) {
+	if len(c.backlog) >= maxBacklog {
+		log.Trace("Dropping future consensus message", "sequence", msg.Sequence, "round", msg.Round)
+		return
+	}
+	c.backlog = append(c.backlog, msg)
+}
+
+// replay processes the backlogged messages that became current.
+func (c *roundState) replay() {
+	backlog := c.backlog
+	c.backlog = nil
+
+	for _, msg := range backlog {
+		switch {
+		case msg.Sequence < c.sequence:
+			continue
+		case msg.Sequence > c.sequence, msg.Code != msgRoundChange && msg.Round > c.round:
+			c.backlog = append(c.backlog, msg)
+		default:
+			c.process(msg)
+		}
+	}
+}
+
+// handlePreprepare verifies the block proposed in the current round, preparing
+// it if valid.
+func (c *roundState) handlePreprepare(msg *message) error {
+	if msg.sender != c.snap.proposer(c.round) {
+		return errUnauthorized
+	}
+	if c.proposal != nil {
+		return nil
+	}
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(msg.Payload, block); err != nil {
+		return errInvalidMessage
+	}
+	if block.NumberU64() != c.sequence || block.ParentHash() != c.snap.Hash {
+		return errInvalidProposal
+	}
+	if c.locked != nil && block.Hash() != c.locked.Hash() {
+		return errLockedProposal
+	}
+	if err := c.ibft.verifyProposal(c.chain, block.Header(), nil); err != nil {
+		return err
+	}
+	if hash := types.DeriveSha(block.Transactions()); hash != block.TxHash() {
+		return errInvalidProposal
+	}
+	c.proposal = block
+	c.broadcast(msgPrepare, c.round, block.Hash().Bytes())
+	c.check()
+
+	return nil
+}
+
+// handlePrepare records a validator's acceptance of a proposal.
+func (c *roundState) handlePrepare(msg *message) error {
+	digest, err := msg.digest()
+	if err != nil {
+		return err
+	}
+	c.prepares[msg.sender] = digest
+	c.check()
+
+	return nil
+}
+
+// handleCommit records a validator's commitment to a proposal.
+func (c *roundState) handleCommit(msg *message) error {
+	commit := new(commitPayload)
+	if err := rlp.DecodeBytes(msg.Payload, commit); err != nil {
+		return errInvalidMessage
+	}
+	if signer, err := recoverAddress(commitHash(commit.Digest), commit.Seal); err != nil || signer != msg.sender {
+		return errInvalidCommittedSeals
+	}
+	c.commits[msg.sender] = commit
+	c.check()
+
+	return nil
+}
+
+// handleRoundChange records a validator's request to move to a higher round.
+// The local validator joins a request backed by more than f validators, and the
+// round is changed once a quorum requested it.
+func (c *roundState) handleRoundChange(msg *message) error {
+	round := msg.Round
+	if round <= c.round {
+		return errOldMessage
+	}
+	if c.changes[round] == nil {
+		c.changes[round] = make(map[common.Address]struct{})
+	}
+	c.changes[round][msg.sender] = struct{}{}
+
+	if len(c.changes[round]) > c.snap.faulty() && c.requested < round {
+		c.sendRoundChange(round)
+	}
+	if round > c.round && len(c.changes[round]) >= c.snap.quorum() {
+		c.startRound(round)
+	}
+	return nil
+}
+
+// check commits to the current proposal once a quorum prepared it, and finishes
+// the sequence once a quorum committed to it.
+func (c *roundState) check() {
+	if c.proposal == nil || c.committed {
+		return
+	}
+	hash := c.proposal.Hash()
+
+	if !c.sentCommit {
+		prepared := 0
+		for _, digest := range c.prepares {
+			if digest == hash {
+				prepared++
+			}
+		}
+		if prepared >= c.snap.quorum() {
+			c.sentCommit = true
+			c.locked = c.proposal
+
+			seal, err := c.ibft.sign(commitHash(hash))
+			if err != nil {
+				log.Error("Failed to sign commit", "err", err)
+				return
+			}
+			payload, err := rlp.EncodeToBytes(&commitPayload{Digest: hash, Seal: seal})
+			if err != nil {
+				log.Error("Failed to encode commit", "err", err)
+				return
+			}
+			c.broadcast(msgCommit, c.round, payload)
+			if c.committed {
+				return
+			}
+		}
+	}
+	var seals [][]byte
+	for _, validator := range c.snap.validators() {
+		if commit := c.commits[validator]; commit != nil && commit.Digest == hash {
+			seals = append(seals, commit.Seal)
+		}
+	}
+	if len(seals) >= c.snap.quorum() {
+		c.commit(seals)
+	}
+}
+
+// commit finishes the current sequence by sealing the proposal with the given
+// committed seals, delivering it to the local sealer if it was its block, or to
+// the chain importer otherwise.
+func (c *roundState) commit(seals [][]byte) {
+	header := c.proposal.Header()
+	extra, err := types.ExtractIBFTExtra(header)
+	if err != nil {
+		log.Error("Failed to decode committed proposal", "err", err)
+		return
+	}
+	extra.CommittedSeal = seals
+	if header.Extra, err = types.EncodeIBFTExtra(header.Extra[:types.IBFTExtraVanity], extra); err != nil {
+		log.Error("Failed to encode committed seals", "err", err)
+		return
+	}
+	block := c.proposal.WithSeal(header)
+
+	c.committed, c.locked = true, nil
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	log.Debug("Committed block", "number", block.Number(), "hash", block.Hash(), "round", c.round, "seals", len(seals))
+
+	if c.pending != nil && c.pending.Hash() == block.Hash() {
+		c.result <- block
+		return
+	}
+	c.ibft.lock.RLock()
+	commitFn := c.ibft.commitFn
+	c.ibft.lock.RUnlock()
+
+	if commitFn != nil {
+		go commitFn(block)
+	}
+}
+
+// sendRoundChange broadcasts a request to move to the given round.
+func (c *roundState) sendRoundChange(round uint64) {
+	c.requested = round
+	c.broadcast(msgRoundChange, round, nil)
+}
+
+// broadcast signs a message of the local validator, gossips it to the peers and
+// processes it locally.
+func (c *roundState) broadcast(code uint64, round uint64, payload []byte) {
+	if !c.isValidator() {
+		return
+	}
+	msg := &message{
+		Code:     code,
+		Sequence: c.sequence,
+		Round:    round,
+		Payload:  payload,
+	}
+	sig, err := c.ibft.sign(msg.sigHash())
+	if err != nil {
+		log.Error("Failed to sign consensus message", "err", err)
+		return
+	}
+	msg.Signature = sig
+
+	c.ibft.lock.RLock()
+	msg.sender = c.ibft.signer
+	c.ibft.lock.RUnlock()
+
+	c.ibft.gossip(msg)
+	c.process(msg)
+}
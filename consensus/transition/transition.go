@@ -0,0 +1,225 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package transition implements a consensus engine switching a proof-of-work
+// chain over to proof-of-authority at a configured block.
+package transition
+
+import (
+	"math/big"
+	"sort"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/consensus/clique"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rpc"
+)
+
+// Transition is a consensus engine delegating the blocks before the clique
+// block of the chain config to a proof-of-work engine, and the ones after to
+// clique.
+type Transition struct {
+	config *params.ChainConfig // Chain config holding the switch-over block
+	pow    consensus.Engine    // Engine sealing the blocks before the switch
+	clique *clique.Clique      // Engine sealing the blocks from the switch on
+}
+
+// New creates a consensus engine switching from pow to clique at the clique
+// block of the given chain config.
+func New(config *params.ChainConfig, pow consensus.Engine, clique *clique.Clique) *Transition {
+	return &Transition{
+		config: config,
+		pow:    pow,
+		clique: clique,
+	}
+}
+
+// PoW returns the proof-of-work engine sealing the blocks before the switch.
+func (t *Transition) PoW() consensus.Engine {
+	return t.pow
+}
+
+// engine returns the consensus engine responsible for the block with the given
+// number.
+func (t *Transition) engine(number *big.Int) consensus.Engine {
+	if t.config.IsClique(number) {
+		return t.clique
+	}
+	return t.pow
+}
+
+// Author implements consensus.Engine, returning the header's coinbase before
+// the switch and the signer afterwards.
+func (t *Transition) Author(header *types.Header) (common.Address, error) {
+	return t.engine(header.Number).Author(header)
+}
+
+// VerifyHeader implements consensus.Engine, checking the header against the
+// consensus rules of the engine responsible for it.
+func (t *Transition) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return t.engine(header.Number).VerifyHeader(chain, header, seal)
+}
+
+// VerifyHeaders implements consensus.Engine, verifying a batch of headers with
+// the engines responsible for them. Batches straddling the switch are split, the
+// clique part being able to access the proof-of-work headers of the batch.
+func (t *Transition) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	split := sort.Search(len(headers), func(i int) bool {
+		return t.config.IsClique(headers[i].Number)
+	})
+	switch split {
+	case 0:
+		return t.clique.VerifyHeaders(chain, headers, seals)
+	case len(headers):
+		return t.pow.VerifyHeaders(chain, headers, seals)
+	}
+	powAbort, powResults := t.pow.VerifyHeaders(chain, headers[:split], seals[:split])
+	cliqueAbort, cliqueResults := t.clique.VerifyHeaders(&batchChainReader{chain, headers[:split]}, headers[split:], seals[split:])
+
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		defer close(powAbort)
+		defer close(cliqueAbort)
+
+		for i := range headers {
+			var err error
+			if i < split {
+				err = <-powResults
+			} else {
+				err = <-cliqueResults
+			}
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// VerifyUncles implements consensus.Engine, verifying the uncles of a block
+// with the engine responsible for it.
+func (t *Transition) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	return t.engine(block.Number()).VerifyUncles(chain, block)
+}
+
+// VerifySeal implements consensus.Engine, verifying the seal of a header with
+// the engine responsible for it.
+func (t *Transition) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return t.engine(header.Number).VerifySeal(chain, header)
+}
+
+// Prepare implements consensus.Engine, initializing the consensus fields of a
+// header with the engine responsible for it.
+func (t *Transition) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	return t.engine(header.Number).Prepare(chain, header)
+}
+
+// Finalize implements consensus.Engine, running the post-transaction state
+// modifications of the engine responsible for the block.
+func (t *Transition) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	return t.engine(header.Number).Finalize(chain, header, state, txs, uncles, receipts)
+}
+
+// Seal implements consensus.Engine, sealing a block with the engine responsible
+// for it.
+func (t *Transition) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	return t.engine(block.Number()).Seal(chain, block, stop)
+}
+
+// CalcDifficulty implements consensus.Engine, calculating the difficulty of the
+// block following parent with the engine responsible for it.
+func (t *Transition) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	return t.engine(new(big.Int).Add(parent.Number, common.Big1)).CalcDifficulty(chain, time, parent)
+}
+
+// APIs implements consensus.Engine, returning the RPC APIs of both engines.
+func (t *Transition) APIs(chain consensus.ChainReader) []rpc.API {
+	return append(t.pow.APIs(chain), t.clique.APIs(chain)...)
+}
+
+// Authorize injects a private key into the clique engine to mint new blocks
+// with after the switch.
+func (t *Transition) Authorize(signer common.Address, signFn clique.SignerFn) {
+	t.clique.Authorize(signer, signFn)
+}
+
+// threaded is the thread control of proof-of-work engines mining on the CPU.
+type threaded interface {
+	Threads() int
+	SetThreads(threads int)
+}
+
+// Threads returns the number of mining threads of the proof-of-work engine
+// sealing the blocks before the switch.
+func (t *Transition) Threads() int {
+	if th, ok := t.pow.(threaded); ok {
+		return th.Threads()
+	}
+	return 0
+}
+
+// SetThreads updates the number of mining threads of the proof-of-work engine
+// sealing the blocks before the switch.
+func (t *Transition) SetThreads(threads int) {
+	if th, ok := t.pow.(threaded); ok {
+		th.SetThreads(threads)
+	}
+}
+
+// Hashrate implements consensus.PoW, returning the hashrate of the proof-of-work
+// engine sealing the blocks before the switch.
+func (t *Transition) Hashrate() float64 {
+	if pow, ok := t.pow.(consensus.PoW); ok {
+		return pow.Hashrate()
+	}
+	return 0
+}
+
+// batchChainReader is a chain reader also serving the headers of a batch being
+// verified, which are not yet part of the local chain.
+type batchChainReader struct {
+	consensus.ChainReader
+	headers []*types.Header
+}
+
+// GetHeader retrieves a block header from the batch or the database by hash and
+// number.
+func (r *batchChainReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	for _, header := range r.headers {
+		if header.Number.Uint64() == number && header.Hash() == hash {
+			return header
+		}
+	}
+	return r.ChainReader.GetHeader(hash, number)
+}
+
+// GetHeaderByHash retrieves a block header from the batch or the database by
+// its hash.
+func (r *batchChainReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range r.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return r.ChainReader.GetHeaderByHash(hash)
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package transition
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/accounts"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/consensus/clique"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rpc"
+)
+
+// Tests that a chain sealed by proof-of-work switches over to clique at the
+// configured block, with the initial signers taken from the chain config.
+func TestTransition(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	config := *params.AllDosashProtocolChanges
+	config.CliqueBlock = big.NewInt(3)
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000, Signers: []common.Address{signer}}
+
+	newEngine := func(db dosdb.Database) *Transition {
+		engine := New(&config, dosash.NewFaker(), clique.New(config.Clique, db))
+		engine.Authorize(signer, func(account accounts.Account, hash []byte) ([]byte, error) {
+			return crypto.Sign(hash, key)
+		})
+		return engine
+	}
+	newChain := func() (*core.BlockChain, *types.Block, dosdb.Database) {
+		db := dosdb.NewMemDatabase()
+		genesis := (&core.Genesis{Config: &config}).MustCommit(db)
+
+		chain, err := core.NewBlockChain(db, nil, &config, newEngine(db), vm.Config{})
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		return chain, genesis, db
+	}
+	chain, genesis, db := newChain()
+	defer chain.Stop()
+
+	// Mine the proof-of-work blocks before the switch and import them
+	blocks, _ := core.GenerateChain(&config, genesis, dosash.NewFaker(), db, 2, nil)
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import proof-of-work blocks: %v", err)
+	}
+	// Seal the clique blocks after the switch and import them one by one
+	engine := chain.Engine()
+	for i := 0; i < 2; i++ {
+		parent := chain.CurrentBlock()
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number(), common.Big1),
+			GasLimit:   core.CalcGasLimit(parent),
+		}
+		if err := engine.Prepare(chain, header); err != nil {
+			t.Fatalf("block %d: failed to prepare header: %v", header.Number, err)
+		}
+		statedb, err := chain.State()
+		if err != nil {
+			t.Fatalf("block %d: failed to retrieve state: %v", header.Number, err)
+		}
+		block, err := engine.Finalize(chain, header, statedb, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("block %d: failed to finalize block: %v", header.Number, err)
+		}
+		if block, err = engine.Seal(chain, block, make(chan struct{})); err != nil {
+			t.Fatalf("block %d: failed to seal block: %v", header.Number, err)
+		}
+		if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+			t.Fatalf("block %d: failed to import clique block: %v", header.Number, err)
+		}
+		if author, err := engine.Author(block.Header()); err != nil || author != signer {
+			t.Errorf("block %d: author mismatch: have %x, %v, want %x", header.Number, author, err, signer)
+		}
+		blocks = append(blocks, block)
+	}
+	// Import the whole chain in a single batch, straddling the switch
+	fresh, _, _ := newChain()
+	defer fresh.Stop()
+
+	if _, err := fresh.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to import straddling chain: %v", err)
+	}
+	if head := fresh.CurrentBlock().NumberU64(); head != 4 {
+		t.Errorf("head mismatch: have %d, want %d", head, 4)
+	}
+	// Ensure the proof-of-work rewards are only reported before the switch
+	var api *dosash.API
+	for _, service := range fresh.Engine().APIs(fresh) {
+		if service.Namespace == "dosash" {
+			api = service.Service.(*dosash.API)
+		}
+	}
+	for number, mined := range map[rpc.BlockNumber]bool{1: true, 2: true, 3: false, 4: false} {
+		if _, err := api.GetRewards(&number); (err == nil) != mined {
+			t.Errorf("block %d: rewards error mismatch: have %v, want mined %v", number, err, mined)
+		}
+	}
+	// Ensure proof-of-work blocks are rejected after the switch
+	forked, genesis, db := newChain()
+	defer forked.Stop()
+
+	blocks, _ = core.GenerateChain(&config, genesis, dosash.NewFaker(), db, 3, nil)
+	if _, err := forked.InsertChain(blocks); err == nil {
+		t.Errorf("proof-of-work block accepted after the switch")
+	}
+}
+
+// Tests that the proof-of-work engine wrapped by the transition can be driven
+// through the thread controls the miner APIs use, mining the blocks before the
+// switch.
+func TestTransitionMining(t *testing.T) {
+	config := *params.AllDosashProtocolChanges
+	config.CliqueBlock = big.NewInt(3)
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+	pow := dosash.NewTester()
+	pow.SetThreads(-1) // CPU mining disabled on startup, as by the node
+	engine := New(&config, pow, clique.New(config.Clique, dosdb.NewMemDatabase()))
+
+	// Enable mining the way miner_start does
+	th, ok := interface{}(engine).(interface {
+		SetThreads(threads int)
+	})
+	if !ok {
+		t.Fatalf("transition engine doesn't expose thread control")
+	}
+	th.SetThreads(1)
+	if threads := engine.Threads(); threads != 1 {
+		t.Fatalf("thread count mismatch: have %d, want %d", threads, 1)
+	}
+	if _, ok := interface{}(engine).(consensus.PoW); !ok {
+		t.Fatalf("transition engine doesn't report its hashrate")
+	}
+	// Mine a block before the switch
+	stop := make(chan struct{})
+	timer := time.AfterFunc(10*time.Second, func() { close(stop) })
+	defer timer.Stop()
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	block, err := engine.Seal(nil, types.NewBlockWithHeader(header), stop)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	if block == nil {
+		t.Fatalf("block sealing timed out")
+	}
+	if err := engine.VerifySeal(nil, block.Header()); err != nil {
+		t.Errorf("invalid proof-of-work seal: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+pragma solidity ^0.4.24;
+
+/**
+ * @title CheckpointOracle
+ * @dev Registrar of the trusted checkpoints light clients and fast syncing nodes
+ * start off. A checkpoint is only registered if signed by a threshold of admins.
+ */
+contract CheckpointOracle {
+    /*
+        Events
+    */
+
+    // NewCheckpointVote is emitted for each admin signature a new checkpoint was
+    // registered with.
+    event NewCheckpointVote(uint64 indexed index, bytes32 checkpointHash, uint8 v, bytes32 r, bytes32 s);
+
+    /*
+        Public Functions
+    */
+    constructor(address[] _adminlist, uint _threshold) public {
+        for (uint i = 0; i < _adminlist.length; i++) {
+            admins[_adminlist[i]] = true;
+            adminList.push(_adminlist[i]);
+        }
+        threshold = _threshold;
+    }
+
+    /**
+     * @dev Get latest stable checkpoint information.
+     * @return section index
+     * @return checkpoint hash
+     * @return block height associated with checkpoint
+     */
+    function GetLatestCheckpoint()
+    view
+    public
+    returns(uint64, bytes32, uint) {
+        return (sectionIndex, hash, height);
+    }
+
+    /**
+     * @dev Register a new checkpoint, signed by a threshold of admins. The
+     * signatures follow EIP 191 version 0x00, the oracle being the intended
+     * validator: keccak256(0x19 || 0x00 || oracle || sectionIndex || hash).
+     * @param _hash the checkpoint hash
+     * @param _sectionIndex the section index of the checkpoint
+     * @param _v the recovery ids of the signatures
+     * @param _r the r values of the signatures
+     * @param _s the s values of the signatures
+     * @return whether the checkpoint was registered
+     */
+    function SetCheckpoint(
+        bytes32 _hash,
+        uint64 _sectionIndex,
+        uint8[] _v,
+        bytes32[] _r,
+        bytes32[] _s
+    )
+    public
+    returns (bool)
+    {
+        // Ensure the sender is authorized.
+        require(admins[msg.sender]);
+
+        // Ensure the checkpoint is newer than the registered one.
+        require(height == 0 || _sectionIndex > sectionIndex);
+
+        // Ensure a threshold of signatures was provided.
+        require(_v.length == _r.length && _v.length == _s.length);
+        require(_v.length >= threshold);
+
+        bytes32 signedHash = keccak256(abi.encodePacked(byte(0x19), byte(0), this, _sectionIndex, _hash));
+
+        // Signers must be sorted in ascending order, rejecting duplicate votes.
+        address lastVoter = address(0);
+        for (uint idx = 0; idx < _v.length; idx++) {
+            address signer = ecrecover(signedHash, _v[idx], _r[idx], _s[idx]);
+            require(admins[signer]);
+            require(uint256(signer) > uint256(lastVoter));
+            lastVoter = signer;
+            emit NewCheckpointVote(_sectionIndex, _hash, _v[idx], _r[idx], _s[idx]);
+        }
+        sectionIndex = _sectionIndex;
+        hash = _hash;
+        height = block.number;
+        return true;
+    }
+
+    /**
+     * @dev Get all admin addresses
+     * @return address list
+     */
+    function GetAllAdmin()
+    public
+    view
+    returns(address[])
+    {
+        address[] memory ret = new address[](adminList.length);
+        for (uint i = 0; i < adminList.length; i++) {
+            ret[i] = adminList[i];
+        }
+        return ret;
+    }
+
+    /*
+        Fields
+    */
+    // A map of admin users who have the permission to register checkpoints.
+    mapping(address => bool) admins;
+
+    // A list of admin users so that we can obtain all admin users.
+    address[] adminList;
+
+    // Latest registered checkpoint information.
+    uint64 sectionIndex;
+    uint height;
+    bytes32 hash;
+
+    // The number of admin signatures required to register a checkpoint.
+    uint threshold;
+}
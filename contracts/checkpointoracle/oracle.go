@@ -0,0 +1,187 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package checkpointoracle is a wrapper of the checkpoint oracle contract, which
+// registers the trusted checkpoints signed by a threshold of admins.
+package checkpointoracle
+
+//go:generate abigen --sol contract/oracle.sol --pkg contract --out contract/oracle.go
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/doslink/dos/accounts/abi/bind"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/contracts/checkpointoracle/contract"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/params"
+)
+
+var (
+	errInvalidSignature = errors.New("invalid checkpoint signature")
+	errUnknownSigner    = errors.New("checkpoint signed by unknown admin")
+	errDuplicateSigner  = errors.New("checkpoint signed twice by the same admin")
+)
+
+// CheckpointOracle is a Go wrapper around an on-chain checkpoint oracle contract.
+type CheckpointOracle struct {
+	address  common.Address
+	contract *contract.CheckpointOracle
+}
+
+// NewCheckpointOracle binds checkpoint contract and returns a registrar instance.
+func NewCheckpointOracle(contractAddr common.Address, backend bind.ContractBackend) (*CheckpointOracle, error) {
+	c, err := contract.NewCheckpointOracle(contractAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckpointOracle{address: contractAddr, contract: c}, nil
+}
+
+// ContractAddr returns the address of contract.
+func (oracle *CheckpointOracle) ContractAddr() common.Address {
+	return oracle.address
+}
+
+// Contract returns the underlying contract instance.
+func (oracle *CheckpointOracle) Contract() *contract.CheckpointOracle {
+	return oracle.contract
+}
+
+// LatestCheckpoint returns the section index and hash of the latest checkpoint
+// registered in the oracle, along with the block it was registered at.
+func (oracle *CheckpointOracle) LatestCheckpoint(opts *bind.CallOpts) (uint64, common.Hash, *big.Int, error) {
+	index, hash, height, err := oracle.contract.GetLatestCheckpoint(opts)
+	if err != nil {
+		return 0, common.Hash{}, nil, err
+	}
+	return index, common.Hash(hash), height, nil
+}
+
+// RegisterCheckpoint registers a checkpoint signed by a threshold of admins in
+// the oracle. The signatures are ordered by signer as expected by the contract.
+func (oracle *CheckpointOracle) RegisterCheckpoint(opts *bind.TransactOpts, index uint64, hash common.Hash, sigs [][]byte) (*types.Transaction, error) {
+	sigs = append([][]byte(nil), sigs...)
+
+	signers := make([]common.Address, len(sigs))
+	for i, sig := range sigs {
+		signer, err := recoverSigner(oracle.address, index, hash, sig)
+		if err != nil {
+			return nil, err
+		}
+		signers[i] = signer
+	}
+	sort.Sort(votes{signers, sigs})
+
+	var (
+		v    []uint8
+		r, s [][32]byte
+	)
+	for _, sig := range sigs {
+		v = append(v, sig[64])
+		r = append(r, common.BytesToHash(sig[:32]))
+		s = append(s, common.BytesToHash(sig[32:64]))
+	}
+	return oracle.contract.SetCheckpoint(opts, hash, index, v, r, s)
+}
+
+// SignatureHash returns the hash admins sign to vote for a checkpoint. It follows
+// EIP 191 version 0x00, the oracle being the intended validator:
+// keccak256(0x19 || 0x00 || oracle || index || hash).
+func SignatureHash(oracle common.Address, index uint64, hash common.Hash) common.Hash {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, index)
+	return crypto.Keccak256Hash([]byte{0x19, 0x00}, oracle.Bytes(), buf, hash.Bytes())
+}
+
+// Sign signs a vote for the checkpoint registered with the given oracle. The
+// recovery id of the returned signature is offset by 27, as ecrecover expects.
+func Sign(oracle common.Address, checkpoint *params.TrustedCheckpoint, key *ecdsa.PrivateKey) ([]byte, error) {
+	sig, err := crypto.Sign(SignatureHash(oracle, checkpoint.SectionIndex, checkpoint.Hash()).Bytes(), key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// VerifySignatures ensures that a checkpoint was signed by a threshold of distinct
+// admins of the oracle, returning the signers.
+func VerifySignatures(config *params.CheckpointOracleConfig, checkpoint *params.TrustedCheckpoint, sigs [][]byte) ([]common.Address, error) {
+	admins := make(map[common.Address]bool)
+	for _, admin := range config.Signers {
+		admins[admin] = true
+	}
+	signed := make(map[common.Address]bool)
+
+	signers := make([]common.Address, 0, len(sigs))
+	for _, sig := range sigs {
+		signer, err := recoverSigner(config.Address, checkpoint.SectionIndex, checkpoint.Hash(), sig)
+		if err != nil {
+			return nil, err
+		}
+		if !admins[signer] {
+			return nil, fmt.Errorf("%v: %x", errUnknownSigner, signer)
+		}
+		if signed[signer] {
+			return nil, fmt.Errorf("%v: %x", errDuplicateSigner, signer)
+		}
+		signed[signer] = true
+		signers = append(signers, signer)
+	}
+	if uint64(len(signers)) < config.Threshold {
+		return signers, fmt.Errorf("not enough signatures: have %d, want %d", len(signers), config.Threshold)
+	}
+	return signers, nil
+}
+
+// recoverSigner retrieves the admin who signed a vote for a checkpoint.
+func recoverSigner(oracle common.Address, index uint64, hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != 65 || (sig[64] != 27 && sig[64] != 28) {
+		return common.Address{}, errInvalidSignature
+	}
+	plain := make([]byte, 65)
+	copy(plain, sig)
+	plain[64] -= 27
+
+	pubkey, err := crypto.SigToPub(SignatureHash(oracle, index, hash).Bytes(), plain)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pubkey), nil
+}
+
+// votes implements sort.Interface, ordering checkpoint signatures by signer.
+type votes struct {
+	signers []common.Address
+	sigs    [][]byte
+}
+
+func (v votes) Len() int { return len(v.signers) }
+func (v votes) Less(i, j int) bool {
+	return bytes.Compare(v.signers[i][:], v.signers[j][:]) < 0
+}
+func (v votes) Swap(i, j int) {
+	v.signers[i], v.signers[j] = v.signers[j], v.signers[i]
+	v.sigs[i], v.sigs[j] = v.sigs[j], v.sigs[i]
+}
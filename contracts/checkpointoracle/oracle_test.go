@@ -0,0 +1,188 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package checkpointoracle
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/doslink/dos/accounts/abi/bind"
+	"github.com/doslink/dos/accounts/abi/bind/backends"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/contracts/checkpointoracle/contract"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/params"
+)
+
+// Tests that checkpoint votes are only accepted if signed by a threshold of
+// distinct admins of the oracle.
+func TestVerifySignatures(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	config := &params.CheckpointOracleConfig{
+		Address:   common.HexToAddress("0x0100000000000000000000000000000000000000"),
+		Signers:   []common.Address{crypto.PubkeyToAddress(keys[0].PublicKey), crypto.PubkeyToAddress(keys[1].PublicKey), crypto.PubkeyToAddress(keys[2].PublicKey)},
+		Threshold: 2,
+	}
+	checkpoint := &params.TrustedCheckpoint{
+		SectionIndex: 1,
+		SectionHead:  common.HexToHash("0x01"),
+		CHTRoot:      common.HexToHash("0x02"),
+		BloomRoot:    common.HexToHash("0x03"),
+	}
+	sign := func(key int, checkpoint *params.TrustedCheckpoint) []byte {
+		sig, err := Sign(config.Address, checkpoint, keys[key])
+		if err != nil {
+			t.Fatalf("failed to sign checkpoint: %v", err)
+		}
+		return sig
+	}
+	other := *checkpoint
+	other.SectionIndex++
+
+	tests := []struct {
+		sigs [][]byte
+		ok   bool
+	}{
+		{[][]byte{sign(0, checkpoint), sign(1, checkpoint)}, true},                      // Threshold of admins
+		{[][]byte{sign(2, checkpoint), sign(1, checkpoint), sign(0, checkpoint)}, true}, // All admins, unordered
+		{[][]byte{sign(0, checkpoint)}, false},                                          // Below threshold
+		{[][]byte{sign(0, checkpoint), sign(0, checkpoint)}, false},                     // Duplicate admin
+		{[][]byte{sign(0, checkpoint), sign(3, checkpoint)}, false},                     // Unknown signer
+		{[][]byte{sign(0, checkpoint), sign(1, &other)}, false},                         // Vote for another checkpoint
+		{[][]byte{sign(0, checkpoint), make([]byte, 65)}, false},                        // Malformed signature
+	}
+	for i, tt := range tests {
+		signers, err := VerifySignatures(config, checkpoint, tt.sigs)
+		if tt.ok && (err != nil || len(signers) != len(tt.sigs)) {
+			t.Errorf("test %d: checkpoint rejected: %v", i, err)
+		}
+		if !tt.ok && err == nil {
+			t.Errorf("test %d: invalid checkpoint accepted", i)
+		}
+	}
+}
+
+// Tests that checkpoints signed in Go are accepted by the oracle contract, which
+// recomputes the signed hash and requires the signers in ascending order.
+func TestRegisterCheckpoint(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	admins := make([]common.Address, len(keys))
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		admins[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{admins[0]: {Balance: big.NewInt(1000000000000000000)}})
+	auth := bind.NewKeyedTransactor(keys[0])
+
+	address, _, _, err := contract.DeployCheckpointOracle(auth, backend, admins, big.NewInt(2))
+	if err != nil {
+		t.Fatalf("failed to deploy oracle: %v", err)
+	}
+	backend.Commit()
+
+	oracle, err := NewCheckpointOracle(address, backend)
+	if err != nil {
+		t.Fatalf("failed to bind oracle: %v", err)
+	}
+	if have, err := oracle.Contract().GetAllAdmin(nil); err != nil || !reflect.DeepEqual(have, admins) {
+		t.Fatalf("admin list mismatch: have %x, want %x, err %v", have, admins, err)
+	}
+	checkpoint := &params.TrustedCheckpoint{
+		SectionIndex: 1,
+		SectionHead:  common.HexToHash("0x01"),
+		CHTRoot:      common.HexToHash("0x02"),
+		BloomRoot:    common.HexToHash("0x03"),
+	}
+	var (
+		sigs    [][]byte
+		signers []common.Address
+	)
+	for _, key := range keys[1:] {
+		sig, err := Sign(address, checkpoint, key)
+		if err != nil {
+			t.Fatalf("failed to sign checkpoint: %v", err)
+		}
+		sigs, signers = append(sigs, sig), append(signers, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	// Submit the votes with the signers in descending order, directly to the contract
+	if bytes.Compare(signers[0][:], signers[1][:]) < 0 {
+		sigs[0], sigs[1] = sigs[1], sigs[0]
+	}
+	var (
+		v    []uint8
+		r, s [][32]byte
+	)
+	for _, sig := range sigs {
+		v = append(v, sig[64])
+		r = append(r, common.BytesToHash(sig[:32]))
+		s = append(s, common.BytesToHash(sig[32:64]))
+	}
+	auth.GasLimit = 1000000
+	tx, err := oracle.Contract().SetCheckpoint(auth, checkpoint.Hash(), checkpoint.SectionIndex, v, r, s)
+	if err != nil {
+		t.Fatalf("failed to submit unsorted votes: %v", err)
+	}
+	backend.Commit()
+	if receipt, _ := backend.TransactionReceipt(context.Background(), tx.Hash()); receipt.Status != types.ReceiptStatusFailed {
+		t.Fatalf("checkpoint registered with unsorted signers")
+	}
+	// Register the votes through the wrapper, sorting them by signer
+	if tx, err = oracle.RegisterCheckpoint(auth, checkpoint.SectionIndex, checkpoint.Hash(), sigs); err != nil {
+		t.Fatalf("failed to register checkpoint: %v", err)
+	}
+	backend.Commit()
+	if receipt, _ := backend.TransactionReceipt(context.Background(), tx.Hash()); receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("checkpoint registration failed")
+	}
+	index, hash, height, err := oracle.LatestCheckpoint(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve latest checkpoint: %v", err)
+	}
+	if index != checkpoint.SectionIndex || hash != checkpoint.Hash() || height.Uint64() != 3 {
+		t.Errorf("latest checkpoint mismatch: have #%d %x at %v, want #%d %x at 3", index, hash, height, checkpoint.SectionIndex, checkpoint.Hash())
+	}
+	votes, err := oracle.Contract().FilterNewCheckpointVote(&bind.FilterOpts{}, []uint64{checkpoint.SectionIndex})
+	if err != nil {
+		t.Fatalf("failed to filter votes: %v", err)
+	}
+	count := 0
+	for ; votes.Next(); count++ {
+		if votes.Event.CheckpointHash != checkpoint.Hash() {
+			t.Errorf("vote %d: checkpoint hash mismatch: have %x, want %x", count, votes.Event.CheckpointHash, checkpoint.Hash())
+		}
+	}
+	if count != len(sigs) {
+		t.Errorf("vote count mismatch: have %d, want %d", count, len(sigs))
+	}
+	// Ensure the same section can't be registered twice
+	if tx, err = oracle.RegisterCheckpoint(auth, checkpoint.SectionIndex, checkpoint.Hash(), sigs); err != nil {
+		t.Fatalf("failed to resubmit checkpoint: %v", err)
+	}
+	backend.Commit()
+	if receipt, _ := backend.TransactionReceipt(context.Background(), tx.Hash()); receipt.Status != types.ReceiptStatusFailed {
+		t.Errorf("checkpoint section registered twice")
+	}
+}
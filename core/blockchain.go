@@ -32,6 +32,7 @@ import (
 	"github.com/doslink/dos/consensus"
 	"github.com/doslink/dos/core/rawdb"
 	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/state/snapshot"
 	"github.com/doslink/dos/core/types"
 	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/crypto"
@@ -67,9 +68,11 @@ const (
 // CacheConfig contains the configuration values for the trie caching/pruning
 // that's resident in a blockchain.
 type CacheConfig struct {
-	Disabled      bool          // Whether to disable trie write caching (archive node)
-	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
-	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+	Disabled        bool          // Whether to disable trie write caching (archive node)
+	TrieNodeLimit   int           // Memory limit (MB) at which to flush the current in-memory trie to disk
+	TrieTimeLimit   time.Duration // Time limit after which to flush the current in-memory trie to disk
+	Snapshot        bool          // Whether to maintain a flat snapshot of the state for fast access
+	SnapshotRebuild bool          // Whether to discard the persisted snapshot and regenerate it
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -112,6 +115,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Snapshot tree for fast trie leaf access, nil if disabled
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -190,6 +194,10 @@ func NewBlockChain(db dosdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Load any existing snapshot, regenerating it if loading failed
+	if bc.cacheConfig.Snapshot {
+		bc.snaps = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.CurrentBlock().Root(), bc.cacheConfig.SnapshotRebuild)
+	}
 	// Take ownership of this particular state
 	go bc.update()
 	return bc, nil
@@ -308,6 +316,11 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	rawdb.WriteHeadBlockHash(bc.db, currentBlock.Hash())
 	rawdb.WriteHeadFastBlockHash(bc.db, currentFastBlock.Hash())
 
+	// The snapshot layers might be gone for the rewound head, rebuild if so
+	if bc.snaps != nil && bc.snaps.Snapshot(currentBlock.Root()) == nil {
+		log.Warn("Snapshot unavailable for rewound head, rebuilding", "number", currentBlock.Number(), "root", currentBlock.Root())
+		bc.snaps.Rebuild(currentBlock.Root())
+	}
 	return bc.loadLastState()
 }
 
@@ -383,7 +396,7 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
 }
 
 // Reset purges the entire blockchain, restoring it to its genesis state.
@@ -653,6 +666,14 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Flatten all the snapshot layers into the disk one, so that it matches the
+	// head state persisted below and can be reused after a restart.
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(bc.CurrentBlock().Root(), 0); err != nil {
+			log.Error("Failed to persist state snapshot", "err", err)
+		}
+		bc.snaps.Stop()
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -988,6 +1009,19 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+
+		// Keep the snapshot diff layers in line with the tries kept in memory, so
+		// the disk layer always has its trie available for background generation.
+		// If the new state could not be linked into the snapshot tree (e.g. reorg
+		// beneath the disk layer), regenerate it from scratch.
+		if bc.snaps != nil {
+			if bc.snaps.Snapshot(root) == nil {
+				log.Warn("Snapshot unavailable for chain head, rebuilding", "number", block.Number(), "root", root)
+				bc.snaps.Rebuild(root)
+			} else if err := bc.snaps.Cap(root, triesInMemory-1); err != nil {
+				log.Error("Failed to cap snapshot tree", "root", root, "err", err)
+			}
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
@@ -1136,7 +1170,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		} else {
 			parent = chain[i-1]
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.NewWithSnapshot(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return i, events, coalescedLogs, err
 		}
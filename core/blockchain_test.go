@@ -1450,3 +1450,49 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
+
+// Tests that importing blocks with the state snapshot enabled keeps the snapshot
+// tree in sync with the state tries, and persists it on shutdown.
+func TestSnapshotChainImport(t *testing.T) {
+	var (
+		db      = dosdb.NewMemDatabase()
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		funds   = big.NewInt(1000000000)
+		gspec   = &Genesis{Config: params.TestChainConfig, Alloc: GenesisAlloc{address: {Balance: funds}}}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	blocks, _ := GenerateChain(gspec.Config, genesis, dosash.NewFaker(), db, 8, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{byte(i + 1)}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block.AddTx(tx)
+	})
+	cacheConfig := &CacheConfig{TrieNodeLimit: 256 * 1024 * 1024, TrieTimeLimit: 5 * time.Minute, Snapshot: true}
+	blockchain, _ := NewBlockChain(db, cacheConfig, gspec.Config, dosash.NewFaker(), vm.Config{})
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	head := blockchain.CurrentBlock()
+	if blockchain.snaps.Snapshot(head.Root()) == nil {
+		t.Fatalf("snapshot missing for chain head")
+	}
+	// Compare the snapshot backed state with the trie backed one
+	snapState, _ := blockchain.State()
+	trieState, _ := state.New(head.Root(), blockchain.stateCache)
+	for _, addr := range []common.Address{address, {1}, {8}, {9}} {
+		if have, want := snapState.GetBalance(addr), trieState.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Errorf("balance mismatch for %x: have %v, want %v", addr, have, want)
+		}
+		if have, want := snapState.GetNonce(addr), trieState.GetNonce(addr); have != want {
+			t.Errorf("nonce mismatch for %x: have %v, want %v", addr, have, want)
+		}
+	}
+	blockchain.Stop()
+
+	if root := rawdb.ReadSnapshotRoot(db); root != head.Root() {
+		t.Fatalf("persisted snapshot root mismatch: have %x, want %x", root, head.Root())
+	}
+}
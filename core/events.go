@@ -24,6 +24,14 @@ import (
 // TxPreEvent is posted when a transaction enters the transaction pool.
 type TxPreEvent struct{ Tx *types.Transaction }
 
+// TxLifecycleEvent is posted when a transaction is dropped from the transaction
+// pool, or moved back from its pending set into the future queue.
+type TxLifecycleEvent struct {
+	Tx          *types.Transaction
+	Reason      TxLifecycleReason
+	Replacement *types.Transaction // Transaction superseding Tx, if replaced
+}
+
 // PendingLogsEvent is posted pre mining and notifies of pending logs.
 type PendingLogsEvent struct {
 	Logs []*types.Log
@@ -51,6 +51,9 @@ func TestSetupGenesis(t *testing.T) {
 			},
 		}
 		oldcustomg = customg
+		badcfg     = &params.ChainConfig{HomesteadBlock: big.NewInt(3), Dosash: &params.DosashConfig{
+			RewardEras: []params.RewardEra{{Block: big.NewInt(20), Reward: big.NewInt(5)}, {Block: big.NewInt(10), Reward: big.NewInt(3)}},
+		}}
 	)
 	oldcustomg.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(2)}
 	tests := []struct {
@@ -94,6 +97,17 @@ func TestSetupGenesis(t *testing.T) {
 			wantHash:   customghash,
 			wantConfig: customg.Config,
 		},
+		{
+			name: "invalid custom config in DB, genesis == nil",
+			fn: func(db dosdb.Database) (*params.ChainConfig, common.Hash, error) {
+				customg.MustCommit(db)
+				rawdb.WriteChainConfig(db, customghash, badcfg)
+				return SetupGenesisBlock(db, nil)
+			},
+			wantErr:    badcfg.CheckConfig(),
+			wantHash:   customghash,
+			wantConfig: badcfg,
+		},
 		{
 			name: "custom block in DB, genesis == testnet",
 			fn: func(db dosdb.Database) (*params.ChainConfig, common.Hash, error) {
@@ -427,6 +427,12 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 	for i := height; i > head; i-- {
 		rawdb.DeleteCanonicalHash(hc.chainDb, i)
 	}
+	// Drop any frozen blocks above the new head, they are not immutable any more
+	if adb, ok := hc.chainDb.(rawdb.AncientWriter); ok {
+		if err := adb.TruncateAncients(head + 1); err != nil {
+			log.Crit("Failed to truncate ancient store", "head", head, "err", err)
+		}
+	}
 	// Clear out any stale content from the caches
 	hc.headerCache.Purge()
 	hc.tdCache.Purge()
@@ -29,7 +29,10 @@ import (
 
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
-	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), headerHashSuffix...))
+	data, _ := db.Get(headerHashKey(number))
+	if len(data) == 0 {
+		data = readAncient(db, freezerHashTable, number)
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -129,17 +132,19 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
-	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	data, _ := db.Get(headerKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientByHash(db, freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
-	key := append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
-	if has, err := db.Has(key); !has || err != nil {
-		return false
+	if has, err := db.Has(headerKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return hasAncientByHash(db, freezerHeaderTable, hash, number)
 }
 
 // ReadHeader retrieves the block header corresponding to the hash.
@@ -192,7 +197,10 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
-	data, _ := db.Get(append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+	data, _ := db.Get(blockBodyKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientByHash(db, freezerBodiesTable, hash, number)
+	}
 	return data
 }
 
@@ -206,11 +214,10 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
-	key := append(append(blockBodyPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
-	if has, err := db.Has(key); !has || err != nil {
-		return false
+	if has, err := db.Has(blockBodyKey(number, hash)); has && err == nil {
+		return true
 	}
-	return true
+	return hasAncientByHash(db, freezerBodiesTable, hash, number)
 }
 
 // ReadBody retrieves the block body corresponding to the hash.
@@ -245,7 +252,10 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), headerTDSuffix...))
+	data, _ := db.Get(headerTDKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientByHash(db, freezerDifficultyTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -279,7 +289,10 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
-	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	data, _ := db.Get(blockReceiptsKey(number, hash))
+	if len(data) == 0 {
+		data = readAncientByHash(db, freezerReceiptTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -379,3 +392,36 @@ func FindCommonAncestor(db DatabaseReader, a, b *types.Header) *types.Header {
 	}
 	return a
 }
+
+// readAncient retrieves a frozen item from the ancient store, if the database
+// has one attached.
+func readAncient(db DatabaseReader, kind string, number uint64) []byte {
+	if adb, ok := db.(AncientReader); ok {
+		data, _ := adb.Ancient(kind, number)
+		return data
+	}
+	return nil
+}
+
+// readAncientByHash retrieves a frozen item from the ancient store, provided the
+// block frozen at the given height is the one requested by hash.
+func readAncientByHash(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if frozen := readAncient(db, freezerHashTable, number); len(frozen) == 0 || common.BytesToHash(frozen) != hash {
+		return nil
+	}
+	return readAncient(db, kind, number)
+}
+
+// hasAncientByHash verifies the existence of a frozen item in the ancient store,
+// provided the block frozen at the given height is the one requested by hash.
+func hasAncientByHash(db DatabaseReader, kind string, hash common.Hash, number uint64) bool {
+	adb, ok := db.(AncientReader)
+	if !ok {
+		return false
+	}
+	if frozen := readAncient(db, freezerHashTable, number); len(frozen) == 0 || common.BytesToHash(frozen) != hash {
+		return false
+	}
+	has, err := adb.HasAncient(kind, number)
+	return has && err == nil
+}
@@ -117,3 +117,38 @@ func WriteBloomBits(db DatabaseWriter, bit uint, section uint64, head common.Has
 		log.Crit("Failed to store bloom bits", "err", err)
 	}
 }
+
+// traceIndexKey = traceIndexPrefix + section (uint64 big endian) + head + address
+func traceIndexKey(section uint64, head common.Hash, addr common.Address) []byte {
+	key := append(append(traceIndexPrefix, make([]byte, 8)...), head.Bytes()...)
+	binary.BigEndian.PutUint64(key[1:], section)
+
+	return append(key, addr.Bytes()...)
+}
+
+// ReadTraceIndex retrieves the offsets of the blocks within the given section in
+// which the address took part in a call, either as the sender or the recipient.
+func ReadTraceIndex(db DatabaseReader, section uint64, head common.Hash, addr common.Address) []uint64 {
+	data, _ := db.Get(traceIndexKey(section, head, addr))
+	if len(data) == 0 {
+		return nil
+	}
+	var offsets []uint64
+	if err := rlp.DecodeBytes(data, &offsets); err != nil {
+		log.Error("Invalid trace index RLP", "section", section, "address", addr, "err", err)
+		return nil
+	}
+	return offsets
+}
+
+// WriteTraceIndex stores the offsets of the blocks within the given section in
+// which the address took part in a call.
+func WriteTraceIndex(db DatabaseWriter, section uint64, head common.Hash, addr common.Address, offsets []uint64) {
+	data, err := rlp.EncodeToBytes(offsets)
+	if err != nil {
+		log.Crit("Failed to encode trace index", "err", err)
+	}
+	if err := db.Put(traceIndexKey(section, head, addr), data); err != nil {
+		log.Crit("Failed to store trace index", "err", err)
+	}
+}
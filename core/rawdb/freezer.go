@@ -0,0 +1,407 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/metrics"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+var (
+	// errUnknownTable is returned if the user attempts to read from a table that is
+	// not tracked by the freezer.
+	errUnknownTable = errors.New("unknown table")
+)
+
+const (
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// prefixIteratee is implemented by key-value stores able to iterate over a
+// subset of their content, used to find side chain data at frozen heights.
+type prefixIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// freezer is an append-only database to store immutable chain data into flat
+// files, one data and index file pair per data kind:
+//
+// - The append only nature ensures that disk writes are minimized.
+// - Moving deep chain data out of leveldb keeps its compactions small and fast,
+//   and lets the ancient files live on cheaper storage than the live database.
+type freezer struct {
+	frozen    uint64 // Number of blocks already frozen (atomic, first for alignment)
+	threshold uint64 // Number of recent blocks to keep in the key-value store
+
+	tables map[string]*freezerTable // Data tables for storing everything
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers.
+func newFreezer(datadir string, namespace string, threshold uint64) (*freezer, error) {
+	var (
+		readMeter  = metrics.NewRegisteredMeter(namespace+"ancient/read", nil)
+		writeMeter = metrics.NewRegisteredMeter(namespace+"ancient/write", nil)
+	)
+	freezer := &freezer{
+		threshold: threshold,
+		tables:    make(map[string]*freezerTable),
+		quit:      make(chan struct{}),
+	}
+	for _, name := range freezerTables {
+		table, err := newTable(datadir, name, readMeter, writeMeter)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		for _, table := range freezer.tables {
+			table.Close()
+		}
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "frozen", freezer.frozen)
+	return freezer, nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *freezer) Close() error {
+	select {
+	case <-f.quit:
+	default:
+		close(f.quit)
+	}
+	f.wg.Wait()
+
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.has(number), nil
+	}
+	return false, nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, errUnknownTable
+}
+
+// Ancients returns the length of the frozen items.
+func (f *freezer) Ancients() (uint64, error) {
+	return atomic.LoadUint64(&f.frozen), nil
+}
+
+// AppendAncient injects all binary blobs belong to block at the end of the
+// append-only immutable table files.
+//
+// Notably, this function is lock free but kind of thread-safe. All out-of-order
+// injection will be rejected. But if two injections with same number happen at
+// the same time, we can get into the trouble.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) (err error) {
+	// Ensure the binary blobs we are appending is continuous with freezer.
+	if atomic.LoadUint64(&f.frozen) != number {
+		return errOutOrderInsertion
+	}
+	// Rollback all inserted data if any insertion below failed to ensure
+	// the tables won't out of sync.
+	defer func() {
+		if err != nil {
+			rerr := f.repair()
+			if rerr != nil {
+				log.Crit("Failed to repair freezer", "err", rerr)
+			}
+			log.Info("Append ancient failed", "number", number, "err", err)
+		}
+	}()
+	blobs := map[string][]byte{
+		freezerHashTable:       hash,
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+	}
+	for _, name := range freezerTables {
+		if err := f.tables[name].Append(number, blobs[name]); err != nil {
+			log.Error("Failed to append ancient item", "table", name, "number", number, "err", err)
+			return err
+		}
+	}
+	atomic.AddUint64(&f.frozen, 1) // Only modify atomically
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	if atomic.LoadUint64(&f.frozen) <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, items)
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// repair truncates all data tables to the same length.
+func (f *freezer) repair() error {
+	min := uint64(0)
+	for i, name := range freezerTables {
+		if items := atomic.LoadUint64(&f.tables[name].items); i == 0 || items < min {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// freeze is a background thread that periodically checks the blockchain for any
+// import progress and moves ancient data from the fast database into the freezer.
+//
+// This functionality is deliberately broken off from block importing to avoid
+// incurring additional data shuffling delays on block propagation.
+func (f *freezer) freeze(db dosdb.Database) {
+	defer f.wg.Done()
+
+	for {
+		// Retrieve the freezing threshold. In theory we're interested only in full
+		// blocks post-sync, but that would keep the live database enormous during
+		// dormant fast-syncs.
+		hash := ReadHeadBlockHash(db)
+		if hash == (common.Hash{}) {
+			log.Debug("Current full block hash unavailable") // new chain, empty database
+			if !f.wait() {
+				return
+			}
+			continue
+		}
+		number := ReadHeaderNumber(db, hash)
+		frozen := atomic.LoadUint64(&f.frozen)
+		switch {
+		case number == nil:
+			log.Error("Current full block number unavailable", "hash", hash)
+			if !f.wait() {
+				return
+			}
+			continue
+
+		case *number < f.threshold:
+			log.Debug("Current full block not old enough", "number", *number, "hash", hash, "delay", f.threshold)
+			if !f.wait() {
+				return
+			}
+			continue
+
+		case *number-f.threshold < frozen:
+			log.Debug("Ancient blocks frozen already", "number", *number, "hash", hash, "frozen", frozen)
+			if !f.wait() {
+				return
+			}
+			continue
+		}
+		// Seems we have data ready to be frozen, process in usable batches
+		limit := *number - f.threshold
+		if limit-frozen > freezerBatchLimit {
+			limit = frozen + freezerBatchLimit
+		}
+		var (
+			start    = time.Now()
+			first    = frozen
+			ancients = make([]common.Hash, 0, limit-frozen)
+		)
+		for {
+			if frozen = atomic.LoadUint64(&f.frozen); frozen > limit {
+				break
+			}
+			// Retrieves all the components of the canonical block
+			hash := ReadCanonicalHash(db, frozen)
+			if hash == (common.Hash{}) {
+				log.Error("Canonical hash missing, can't freeze", "number", frozen)
+				break
+			}
+			header := ReadHeaderRLP(db, hash, frozen)
+			if len(header) == 0 {
+				log.Error("Block header missing, can't freeze", "number", frozen, "hash", hash)
+				break
+			}
+			body := ReadBodyRLP(db, hash, frozen)
+			if len(body) == 0 {
+				log.Error("Block body missing, can't freeze", "number", frozen, "hash", hash)
+				break
+			}
+			receipts, _ := db.Get(blockReceiptsKey(frozen, hash))
+			if len(receipts) == 0 {
+				log.Error("Block receipts missing, can't freeze", "number", frozen, "hash", hash)
+				break
+			}
+			td, _ := db.Get(headerTDKey(frozen, hash))
+			if len(td) == 0 {
+				log.Error("Total difficulty missing, can't freeze", "number", frozen, "hash", hash)
+				break
+			}
+			// Inject all the components into the relevant data tables
+			if err := f.AppendAncient(frozen, hash[:], header, body, receipts, td); err != nil {
+				break
+			}
+			ancients = append(ancients, hash)
+		}
+		// Batch of blocks have been frozen, flush them before wiping from leveldb
+		if err := f.Sync(); err != nil {
+			log.Crit("Failed to flush frozen tables", "err", err)
+		}
+		// Wipe out all data from the active database. The genesis block is kept in
+		// the key-value store too, it is needed by tools opening the raw database.
+		for i, hash := range ancients {
+			number := first + uint64(i)
+			if number == 0 {
+				continue
+			}
+			deleteFrozenBlock(db, hash, number)
+		}
+		// Wipe out side chains at the frozen heights, they can never become canonical
+		var dangling int
+		if it, ok := db.(prefixIteratee); ok {
+			for i, keep := range ancients {
+				number := first + uint64(i)
+				if number == 0 {
+					continue
+				}
+				prefix := append(headerPrefix, encodeBlockNumber(number)...)
+				iter := it.NewIteratorWithPrefix(prefix)
+				var hashes []common.Hash
+				for iter.Next() {
+					if key := iter.Key(); len(key) == len(prefix)+common.HashLength {
+						if hash := common.BytesToHash(key[len(prefix):]); hash != keep {
+							hashes = append(hashes, hash)
+						}
+					}
+				}
+				iter.Release()
+
+				for _, hash := range hashes {
+					DeleteBlock(db, hash, number)
+					dangling++
+				}
+			}
+		}
+		// Log something friendly for the user
+		context := []interface{}{
+			"blocks", frozen - first, "elapsed", common.PrettyDuration(time.Since(start)), "number", frozen - 1,
+		}
+		if dangling > 0 {
+			context = append(context, []interface{}{"dangling", dangling}...)
+		}
+		if n := len(ancients); n > 0 {
+			context = append(context, []interface{}{"hash", ancients[n-1]}...)
+		}
+		log.Info("Deep froze chain segment", context...)
+
+		// Avoid database thrashing with tiny writes
+		if frozen-first < freezerBatchLimit {
+			if !f.wait() {
+				return
+			}
+		}
+	}
+}
+
+// wait blocks until the next recheck is due, returning false if the freezer is
+// being shut down in the meantime.
+func (f *freezer) wait() bool {
+	select {
+	case <-time.After(freezerRecheckInterval):
+		return true
+	case <-f.quit:
+		log.Info("Freezer shutting down")
+		return false
+	}
+}
+
+// deleteFrozenBlock removes all the key-value data of a block that has been moved
+// into the freezer, retaining only the hash to number mapping needed to locate it.
+func deleteFrozenBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
+	for _, key := range [][]byte{
+		headerKey(number, hash),
+		headerTDKey(number, hash),
+		headerHashKey(number),
+		blockBodyKey(number, hash),
+		blockReceiptsKey(number, hash),
+	} {
+		if err := db.Delete(key); err != nil {
+			log.Crit("Failed to delete frozen block data", "number", number, "hash", hash, "err", err)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/metrics"
+)
+
+var (
+	// errClosed is returned if an operation attempts to read from or write to the
+	// freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+// indexEntrySize is the size of a single freezer index entry: the end offset
+// of the item within the data file, as a big endian uint64.
+const indexEntrySize = 8
+
+// freezerTable represents a single chained data table within the freezer (e.g.
+// blocks). It consists of an append-only data file, holding the raw blobs back
+// to back, and an index file, holding the end offset of every blob.
+type freezerTable struct {
+	items uint64 // Number of items stored in the table (atomic, first for alignment)
+
+	data  *os.File // File descriptor for the data content of the table
+	index *os.File // File descriptor for the indexEntry file of the table
+	size  uint64   // Total size of the data file, tracked to avoid stat calls
+
+	readMeter  metrics.Meter // Meter for measuring the effective amount of data read
+	writeMeter metrics.Meter // Meter for measuring the effective amount of data written
+
+	logger log.Logger   // Logger with database path and table name ambedded
+	lock   sync.RWMutex // Mutex protecting the data file descriptors
+}
+
+// newTable opens a freezer table, creating the data and index files if they
+// are non existent. Both files are truncated to the shortest common length to
+// ensure they don't go out of sync.
+func newTable(path string, name string, readMeter metrics.Meter, writeMeter metrics.Meter) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(path, name+".ridx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(path, name+".rdat"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	tab := &freezerTable{
+		data:       data,
+		index:      index,
+		readMeter:  readMeter,
+		writeMeter: writeMeter,
+		logger:     log.New("database", path, "table", name),
+	}
+	if err := tab.repair(); err != nil {
+		tab.Close()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// repair cross checks the data and index files and truncates them to be in
+// sync with each other after a potential crash / data loss.
+func (t *freezerTable) repair() error {
+	// Truncate the index file to a whole number of entries
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	indexSize := stat.Size()
+	if overflow := indexSize % indexEntrySize; overflow != 0 {
+		indexSize -= overflow
+		if err := t.index.Truncate(indexSize); err != nil {
+			return err
+		}
+	}
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	// Drop any index entries pointing past the end of the data file
+	var end uint64
+	for indexSize > 0 {
+		if end, err = t.readIndex(uint64(indexSize/indexEntrySize) - 1); err != nil {
+			return err
+		}
+		if end <= dataSize {
+			break
+		}
+		indexSize -= indexEntrySize
+		end = 0
+	}
+	if err := t.index.Truncate(indexSize); err != nil {
+		return err
+	}
+	// Drop any data not referenced by the index file
+	if dataSize > end {
+		t.logger.Warn("Truncating dangling freezer data", "indexed", end, "stored", dataSize)
+		if err := t.data.Truncate(int64(end)); err != nil {
+			return err
+		}
+	}
+	t.size = end
+	atomic.StoreUint64(&t.items, uint64(indexSize/indexEntrySize))
+
+	// Ensure all repairs are persisted before continuing
+	if err := t.index.Sync(); err != nil {
+		return err
+	}
+	return t.data.Sync()
+}
+
+// readIndex retrieves the end offset of the n-th item from the index file.
+func (t *freezerTable) readIndex(n uint64) (uint64, error) {
+	buf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buf, int64(n*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// truncate discards any recent data above the provided threshold number.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	existing := atomic.LoadUint64(&t.items)
+	if existing <= items {
+		return nil
+	}
+	t.logger.Warn("Truncating freezer table", "items", existing, "limit", items)
+
+	var end uint64
+	if items > 0 {
+		var err error
+		if end, err = t.readIndex(items - 1); err != nil {
+			return err
+		}
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.size = end
+	atomic.StoreUint64(&t.items, items)
+	return nil
+}
+
+// Close closes all opened files.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.index = nil
+	}
+	if t.data != nil {
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.data = nil
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Append injects a binary blob at the end of the freezer table. The item number
+// is a precautionary parameter to ensure data correctness, but the table will
+// reject already existing data.
+//
+// Note, this method will *not* flush any data to disk so be sure to explicitly
+// fsync before irreversibly deleting data from the database.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if atomic.LoadUint64(&t.items) != item {
+		return errOutOrderInsertion
+	}
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	t.size += uint64(len(blob))
+
+	buf := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(buf, t.size)
+	if _, err := t.index.WriteAt(buf, int64(item*indexEntrySize)); err != nil {
+		return err
+	}
+	t.writeMeter.Mark(int64(len(blob) + indexEntrySize))
+	atomic.AddUint64(&t.items, 1)
+	return nil
+}
+
+// Retrieve looks up the data offset of an item with the given number and
+// retrieves the raw binary blob from the data file.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return nil, errClosed
+	}
+	if atomic.LoadUint64(&t.items) <= item {
+		return nil, errOutOfBounds
+	}
+	var start uint64
+	if item > 0 {
+		var err error
+		if start, err = t.readIndex(item - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, err := t.readIndex(item)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	t.readMeter.Mark(int64(len(blob) + 2*indexEntrySize))
+	return blob, nil
+}
+
+// has returns an indicator whether the specified number data exists in the
+// freezer table.
+func (t *freezerTable) has(number uint64) bool {
+	return atomic.LoadUint64(&t.items) > number
+}
+
+// Sync pushes any pending data from memory out to disk. This is an expensive
+// operation, so use it with care.
+func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
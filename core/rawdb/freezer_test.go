@@ -0,0 +1,163 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/metrics"
+	"github.com/doslink/dos/rlp"
+)
+
+// Tests that freezer tables can be appended to, read back and survive a restart
+// with a torn write at the end of the data file.
+func TestFreezerTableRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{})
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := table.Append(uint64(i), bytes.Repeat([]byte{byte(i)}, i+1)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	if err := table.Append(5, []byte{0x00}); err != errOutOrderInsertion {
+		t.Fatalf("out of order append error mismatch: have %v, want %v", err, errOutOrderInsertion)
+	}
+	table.Close()
+
+	// Simulate a crash half way through writing a new item
+	data, err := os.OpenFile(filepath.Join(dir, "test.rdat"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("failed to open data file: %v", err)
+	}
+	data.Write([]byte{0xff, 0xff, 0xff})
+	data.Close()
+
+	if table, err = newTable(dir, "test", metrics.NilMeter{}, metrics.NilMeter{}); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer table.Close()
+
+	if table.items != 10 {
+		t.Fatalf("item count mismatch: have %d, want %d", table.items, 10)
+	}
+	for i := 0; i < 10; i++ {
+		blob, err := table.Retrieve(uint64(i))
+		if err != nil {
+			t.Fatalf("failed to retrieve item %d: %v", i, err)
+		}
+		if want := bytes.Repeat([]byte{byte(i)}, i+1); !bytes.Equal(blob, want) {
+			t.Fatalf("item %d mismatch: have %x, want %x", i, blob, want)
+		}
+	}
+	if _, err := table.Retrieve(10); err != errOutOfBounds {
+		t.Fatalf("out of bounds retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+	}
+	// Truncate the table and ensure the dropped items are gone
+	if err := table.truncate(4); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if _, err := table.Retrieve(4); err != errOutOfBounds {
+		t.Fatalf("truncated retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+	}
+	if err := table.Append(4, []byte{0x44}); err != nil {
+		t.Fatalf("failed to append after truncation: %v", err)
+	}
+}
+
+// Tests that the chain accessors transparently fall back to the ancient store
+// for blocks that were moved out of the key-value database.
+func TestAncientStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	freezer, err := newFreezer(dir, "", 0)
+	if err != nil {
+		t.Fatalf("failed to create freezer: %v", err)
+	}
+	db := &freezerdb{Database: dosdb.NewMemDatabase(), freezer: freezer}
+	defer db.Close()
+
+	// Create a test block and freeze it without touching the key-value store
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:      big.NewInt(0),
+		Extra:       []byte("test block"),
+		UncleHash:   types.EmptyUncleHash,
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+	})
+	hash, number := block.Hash(), block.NumberU64()
+
+	if HasHeader(db, hash, number) || HasBody(db, hash, number) {
+		t.Fatalf("Non existent block reported")
+	}
+	header, _ := rlp.EncodeToBytes(block.Header())
+	body, _ := rlp.EncodeToBytes(block.Body())
+	receipts, _ := rlp.EncodeToBytes([]*types.ReceiptForStorage{})
+	td, _ := rlp.EncodeToBytes(big.NewInt(605))
+
+	if err := db.AppendAncient(number, hash[:], header, body, receipts, td); err != nil {
+		t.Fatalf("failed to append ancient block: %v", err)
+	}
+	if frozen, _ := db.Ancients(); frozen != 1 {
+		t.Fatalf("frozen block count mismatch: have %d, want %d", frozen, 1)
+	}
+	if have := ReadCanonicalHash(db, number); have != hash {
+		t.Fatalf("canonical hash mismatch: have %x, want %x", have, hash)
+	}
+	if !HasHeader(db, hash, number) || !HasBody(db, hash, number) {
+		t.Fatalf("Frozen block not found")
+	}
+	if entry := ReadBlock(db, hash, number); entry == nil || entry.Hash() != hash {
+		t.Fatalf("Retrieved block mismatch: have %v, want %v", entry, block)
+	}
+	if entry := ReadTd(db, hash, number); entry == nil || entry.Cmp(big.NewInt(605)) != 0 {
+		t.Fatalf("Retrieved TD mismatch: have %v, want %v", entry, 605)
+	}
+	if entry := ReadReceipts(db, hash, number); entry == nil || len(entry) != 0 {
+		t.Fatalf("Retrieved receipts mismatch: have %v", entry)
+	}
+	// Ensure a different block at the same height is not served from the freezer
+	fake := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(0), Extra: []byte("side block")})
+	if entry := ReadHeader(db, fake.Hash(), number); entry != nil {
+		t.Fatalf("Side chain header served from freezer: %v", entry)
+	}
+	// Truncate the freezer and ensure the block is gone
+	if err := db.TruncateAncients(0); err != nil {
+		t.Fatalf("failed to truncate freezer: %v", err)
+	}
+	if entry := ReadHeader(db, hash, number); entry != nil {
+		t.Fatalf("Truncated header returned: %v", entry)
+	}
+}
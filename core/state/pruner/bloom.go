@@ -0,0 +1,159 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/doslink/dos/common"
+)
+
+// stateBloomHashes is the number of bit positions set for every inserted key.
+// Trie node keys are keccak hashes, so disjoint 8 byte slices of them are used
+// directly as independent hash functions.
+const stateBloomHashes = 4
+
+// errInvalidBloom is returned if a persisted bloom filter cannot be loaded back.
+var errInvalidBloom = errors.New("invalid state bloom file")
+
+// stateBloom is a bloom filter marking all the live trie nodes and contract
+// codes of a state. False positives only mean some dead nodes survive pruning,
+// false negatives are not possible so live data is never deleted.
+type stateBloom struct {
+	bits []uint64
+}
+
+// newStateBloom creates a bloom filter of the given size in megabytes.
+func newStateBloom(size uint64) *stateBloom {
+	if size == 0 {
+		size = 1
+	}
+	return &stateBloom{bits: make([]uint64, size*1024*1024/8)}
+}
+
+// loadStateBloom reads back a bloom filter committed to disk, along with the
+// state root it was generated for.
+func loadStateBloom(path string) (*stateBloom, common.Hash, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	defer reader.Close()
+
+	buffered := bufio.NewReader(reader)
+
+	var (
+		root  common.Hash
+		words uint64
+	)
+	if _, err := io.ReadFull(buffered, root[:]); err != nil {
+		return nil, common.Hash{}, errInvalidBloom
+	}
+	if err := binary.Read(buffered, binary.BigEndian, &words); err != nil || words == 0 {
+		return nil, common.Hash{}, errInvalidBloom
+	}
+	bloom := &stateBloom{bits: make([]uint64, words)}
+
+	buf := make([]byte, 8)
+	for i := range bloom.bits {
+		if _, err := io.ReadFull(buffered, buf); err != nil {
+			return nil, common.Hash{}, errInvalidBloom
+		}
+		bloom.bits[i] = binary.BigEndian.Uint64(buf)
+	}
+	return bloom, root, nil
+}
+
+// Commit flushes the bloom filter into the given file, tagged with the state
+// root it marks. The data is written into a temporary file first and moved to
+// its final place atomically, so an existing file is always complete.
+func (bloom *stateBloom) Commit(path string, root common.Hash) error {
+	tmp := path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	writer, _ := gzip.NewWriterLevel(file, gzip.BestSpeed)
+	buffered := bufio.NewWriter(writer)
+
+	if _, err := buffered.Write(root[:]); err != nil {
+		file.Close()
+		return err
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(len(bloom.bits)))
+	if _, err := buffered.Write(buf); err != nil {
+		file.Close()
+		return err
+	}
+	for _, word := range bloom.bits {
+		binary.BigEndian.PutUint64(buf, word)
+		if _, err := buffered.Write(buf); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Put marks a key as live in the bloom filter.
+func (bloom *stateBloom) Put(key []byte) {
+	size := uint64(len(bloom.bits)) * 64
+	for i := 0; i < stateBloomHashes; i++ {
+		bit := binary.BigEndian.Uint64(key[i*8:]) % size
+		bloom.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// Contain reports whether a key might have been marked as live.
+func (bloom *stateBloom) Contain(key []byte) bool {
+	size := uint64(len(bloom.bits)) * 64
+	for i := 0; i < stateBloomHashes; i++ {
+		bit := binary.BigEndian.Uint64(key[i*8:]) % size
+		if bloom.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package pruner implements offline deletion of stale state trie nodes.
+package pruner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	// maxRootSearchDepth is the number of blocks to go back from the chain head
+	// looking for a state fully persisted to disk, matching the number of tries
+	// a running node keeps in memory.
+	maxRootSearchDepth = 128
+
+	// logInterval is the frequency of the progress reports.
+	logInterval = 8 * time.Second
+)
+
+var (
+	// errNotIterable is returned if the database backing the chain cannot
+	// enumerate its content, so the dead trie nodes cannot be found.
+	errNotIterable = errors.New("database not iterable")
+
+	// errNoPersistedState is returned if no recent block has its state stored
+	// on disk, so there's nothing safe to prune to.
+	errNoPersistedState = errors.New("no recent persisted state found")
+)
+
+// iteratee wraps the NewIterator method of a backing data store able to walk
+// all of its content.
+type iteratee interface {
+	NewIterator() iterator.Iterator
+}
+
+// Pruner is an offline tool to prune the stale state with the help of a bloom
+// filter. The workflow of pruner is very simple:
+//
+// - walk the state trie of the target root, marking every live trie node and
+//   contract code in the bloom filter
+// - persist the bloom filter next to the database
+// - iterate the database and delete every trie node not contained in the filter
+//
+// Since the bloom filter is committed before anything is deleted, pruning can
+// be interrupted at any point after that and resumed later on: deleting dead
+// nodes again is harmless. False positives of the bloom filter only leave some
+// dead nodes behind, live nodes are never removed.
+type Pruner struct {
+	db        dosdb.Database // Chain database holding the state tries
+	kv        iteratee       // Key-value store backing the database
+	bloomPath string         // File to persist the bloom filter into
+	bloomSize uint64         // Size of the bloom filter in megabytes
+}
+
+// NewPruner creates the pruner instance.
+func NewPruner(db dosdb.Database, bloomPath string, bloomSize uint64) (*Pruner, error) {
+	kv, ok := rawdb.KeyValueStore(db).(iteratee)
+	if !ok {
+		return nil, errNotIterable
+	}
+	return &Pruner{
+		db:        db,
+		kv:        kv,
+		bloomPath: bloomPath,
+		bloomSize: bloomSize,
+	}, nil
+}
+
+// Prune deletes all historical state nodes except the nodes belonging to the
+// specified state root and the genesis state. If the root is not specified,
+// the most recent state persisted to disk is retained.
+//
+// If a previous pruning was interrupted half way through, it's resumed first.
+func (p *Pruner) Prune(root common.Hash) error {
+	if common.FileExist(p.bloomPath) {
+		bloom, marked, err := loadStateBloom(p.bloomPath)
+		if err != nil {
+			return fmt.Errorf("failed to load state bloom %s: %v", p.bloomPath, err)
+		}
+		if root != (common.Hash{}) && root != marked {
+			return fmt.Errorf("interrupted pruning of state %x must be finished first", marked)
+		}
+		log.Info("Resuming interrupted state pruning", "root", marked)
+		return p.sweep(bloom, marked)
+	}
+	if root == (common.Hash{}) {
+		header, err := p.findRecentState()
+		if err != nil {
+			return err
+		}
+		root = header.Root
+		log.Info("Selected most recent persisted state", "number", header.Number, "hash", header.Hash(), "root", root)
+	} else if blob, _ := p.db.Get(root[:]); len(blob) == 0 {
+		return fmt.Errorf("state %x is not available", root)
+	}
+	// Mark all the live state and persist the markers before deleting anything
+	bloom := newStateBloom(p.bloomSize)
+	if err := markState(p.db, root, bloom); err != nil {
+		return err
+	}
+	if genesis := rawdb.ReadHeader(p.db, rawdb.ReadCanonicalHash(p.db, 0), 0); genesis != nil {
+		if blob, _ := p.db.Get(genesis.Root[:]); len(blob) > 0 && genesis.Root != root {
+			if err := markState(p.db, genesis.Root, bloom); err != nil {
+				return err
+			}
+		}
+	}
+	if err := bloom.Commit(p.bloomPath, root); err != nil {
+		return err
+	}
+	return p.sweep(bloom, root)
+}
+
+// findRecentState looks up the most recent canonical block with its state fully
+// available on disk.
+func (p *Pruner) findRecentState() (*types.Header, error) {
+	hash := rawdb.ReadHeadBlockHash(p.db)
+	if hash == (common.Hash{}) {
+		return nil, errNoPersistedState
+	}
+	number := rawdb.ReadHeaderNumber(p.db, hash)
+	if number == nil {
+		return nil, errNoPersistedState
+	}
+	header := rawdb.ReadHeader(p.db, hash, *number)
+	for i := 0; header != nil && i < maxRootSearchDepth; i++ {
+		if blob, _ := p.db.Get(header.Root[:]); len(blob) > 0 {
+			return header, nil
+		}
+		if header.Number.Sign() == 0 {
+			break
+		}
+		header = rawdb.ReadHeader(p.db, header.ParentHash, header.Number.Uint64()-1)
+	}
+	return nil, errNoPersistedState
+}
+
+// markState walks the entire state of the given root, including all storage
+// tries and contract codes, marking every node in the bloom filter.
+func markState(db dosdb.Database, root common.Hash, bloom *stateBloom) error {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	var (
+		nodes  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+		// Embedded nodes don't have a hash of their own, they live in their parent
+		if it.Hash == (common.Hash{}) {
+			continue
+		}
+		bloom.Put(it.Hash[:])
+		nodes++
+
+		if time.Since(logged) > logInterval {
+			log.Info("Marking live state", "root", root, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if it.Error != nil {
+		return it.Error
+	}
+	log.Info("Marked live state", "root", root, "nodes", nodes, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// sweep iterates over the entire database, deleting every trie node and code
+// not marked in the bloom filter. Once done, the bloom filter is discarded and
+// the database compacted to actually release the disk space.
+func (p *Pruner) sweep(bloom *stateBloom, root common.Hash) error {
+	var (
+		nodes  int
+		size   common.StorageSize
+		start  = time.Now()
+		logged = time.Now()
+		batch  = p.db.NewBatch()
+		iter   = p.kv.NewIterator()
+	)
+	for iter.Next() {
+		// Trie nodes and contract codes are the only entries keyed by a bare hash
+		key := iter.Key()
+		if len(key) != common.HashLength || bloom.Contain(key) {
+			continue
+		}
+		size += common.StorageSize(len(key) + len(iter.Value()))
+		nodes++
+
+		batch.Delete(key)
+		if batch.ValueSize() >= dosdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				iter.Release()
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > logInterval {
+			done := binary.BigEndian.Uint64(key[:8])
+			if done == 0 {
+				done = 1
+			}
+			var (
+				elapsed = time.Since(start)
+				eta     = time.Duration(float64(elapsed) * (float64(math.MaxUint64)/float64(done) - 1))
+			)
+			log.Info("Pruning state data", "nodes", nodes, "size", size, "elapsed", common.PrettyDuration(elapsed), "eta", common.PrettyDuration(eta))
+			logged = time.Now()
+		}
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "root", root, "nodes", nodes, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// All dead nodes are gone, the bloom filter is not needed to resume any more
+	if err := os.Remove(p.bloomPath); err != nil {
+		return err
+	}
+	// Compact the database to actually reclaim the freed disk space
+	ldb, ok := rawdb.KeyValueStore(p.db).(*dosdb.LDBDatabase)
+	if !ok {
+		return nil
+	}
+	before := dirSize(ldb.Path())
+
+	cstart := time.Now()
+	log.Info("Compacting database", "path", ldb.Path())
+	if err := ldb.LDB().CompactRange(util.Range{}); err != nil {
+		return err
+	}
+	after := dirSize(ldb.Path())
+
+	reclaimed := common.StorageSize(0)
+	if before > after {
+		reclaimed = common.StorageSize(before - after)
+	}
+	log.Info("Compacted database", "before", common.StorageSize(before), "after", common.StorageSize(after), "reclaimed", reclaimed, "elapsed", common.PrettyDuration(time.Since(cstart)))
+	return nil
+}
+
+// dirSize sums up the size of all the regular files within a folder.
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/dosdb"
+)
+
+// makeTestState creates two consecutive versions of a small state, committing
+// both of them to disk, and returns their roots.
+func makeTestState(t *testing.T, db dosdb.Database) (common.Hash, common.Hash) {
+	sdb := state.NewDatabase(db)
+	statedb, _ := state.New(common.Hash{}, sdb)
+	for i := byte(0); i < 32; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.AddBalance(addr, big.NewInt(int64(i)))
+		statedb.SetState(addr, common.BytesToHash([]byte{i}), common.BytesToHash([]byte{i, i}))
+		if i%4 == 0 {
+			statedb.SetCode(addr, []byte{i, i, i})
+		}
+	}
+	old, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(old, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	statedb, _ = state.New(old, sdb)
+	for i := byte(0); i < 32; i += 2 {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.AddBalance(addr, big.NewInt(1))
+		statedb.SetState(addr, common.BytesToHash([]byte{i}), common.BytesToHash([]byte{i, i, i}))
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return old, root
+}
+
+// checkState iterates over the entire state, failing if any node is missing.
+func checkState(db dosdb.Database, root common.Hash) error {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	return it.Error
+}
+
+// Tests that pruning deletes the stale state while retaining the target one,
+// and that an interrupted pruning can be resumed from the persisted bloom.
+func TestPruneState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pruner")
+	if err != nil {
+		t.Fatalf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := dosdb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 16, 16)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	old, root := makeTestState(t, db)
+	if err := checkState(db, old); err != nil {
+		t.Fatalf("old state incomplete before pruning: %v", err)
+	}
+	bloomPath := filepath.Join(dir, "statebloom.bf.gz")
+	pruner, err := NewPruner(db, bloomPath, 1)
+	if err != nil {
+		t.Fatalf("failed to create pruner: %v", err)
+	}
+	// Simulate a pruning interrupted right after the bloom was committed
+	bloom := newStateBloom(1)
+	if err := markState(db, root, bloom); err != nil {
+		t.Fatalf("failed to mark state: %v", err)
+	}
+	if err := bloom.Commit(bloomPath, root); err != nil {
+		t.Fatalf("failed to commit bloom: %v", err)
+	}
+	if err := pruner.Prune(old); err == nil {
+		t.Fatalf("pruning a different root succeeded while interrupted")
+	}
+	// Resume the pruning and ensure only the target state is left
+	if err := pruner.Prune(common.Hash{}); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if common.FileExist(bloomPath) {
+		t.Fatalf("state bloom not removed after pruning")
+	}
+	if err := checkState(db, root); err != nil {
+		t.Fatalf("target state incomplete after pruning: %v", err)
+	}
+	if blob, _ := db.Get(old[:]); len(blob) != 0 {
+		t.Fatalf("stale state root retained after pruning")
+	}
+	if err := checkState(db, old); err == nil {
+		t.Fatalf("stale state still complete after pruning")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/trie"
+)
+
+// diskLayer is a low level persistent snapshot built on top of a key-value store.
+type diskLayer struct {
+	diskdb dosdb.Database // Key-value store containing the base snapshot
+	triedb *trie.Database // Trie node cache for reconstruction purposes
+	root   common.Hash    // Root hash of the base snapshot
+	stale  bool           // Signals that the layer became stale (state progressed)
+
+	genMarker []byte                     // Marker for the state that's indexed during initial layer generation
+	genAbort  chan chan *generatorStatus // Notification channel to abort generating the snapshot in this layer
+
+	lock sync.RWMutex
+}
+
+// Root returns root hash for which this snapshot was made.
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent always returns nil as there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot {
+	return nil
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+// markStale flags the layer as stale, failing any subsequent data access.
+func (dl *diskLayer) markStale() {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.stale = true
+}
+
+// AccountRLP directly retrieves the account RLP associated with a particular
+// hash in the snapshot.
+func (dl *diskLayer) AccountRLP(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	// If the layer was flattened into, consider it invalid (any live reference to
+	// the original should be marked as unusable).
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	// If the layer is being generated, ensure the requested hash has already been
+	// covered by the generator.
+	if !covered(dl.genMarker, hash[:]) {
+		return nil, ErrNotCoveredYet
+	}
+	return rawdb.ReadAccountSnapshot(dl.diskdb, hash), nil
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	if !covered(dl.genMarker, append(accountHash[:], storageHash[:]...)) {
+		return nil, ErrNotCoveredYet
+	}
+	return rawdb.ReadStorageSnapshot(dl.diskdb, accountHash, storageHash), nil
+}
+
+// covered returns whether the snapshot item with the given key (account hash,
+// optionally followed by the storage slot hash) was already generated, given
+// the generation progress marker. A nil marker means the generation is done,
+// an empty one that it has not yet started.
+func covered(marker []byte, key []byte) bool {
+	if marker == nil {
+		return true
+	}
+	if len(marker) == 0 {
+		return false
+	}
+	// An account marker covers all the storage slots of that account too
+	if len(key) > len(marker) {
+		key = key[:len(marker)]
+	}
+	return bytes.Compare(key, marker) <= 0
+}
+
+// diffToDisk merges a bottom-most diff into the persistent disk layer underneath
+// it, returning a new disk layer representing the diff's state. The old disk
+// layer and the diff are both marked stale. If the snapshot is being generated,
+// only the items already covered by the generator (as per the stopped status)
+// are persisted, the rest will be picked up from the trie when the generation
+// is resumed on top of the new layer.
+func diffToDisk(bottom *diffLayer, base *diskLayer, status *generatorStatus) *diskLayer {
+	var marker []byte
+	if status != nil {
+		marker = status.Marker
+		if status.Wiping {
+			marker = []byte{}
+		}
+	}
+	batch := base.diskdb.NewBatch()
+
+	// flush writes out the batch if it grew large enough
+	flush := func() {
+		if batch.ValueSize() > dosdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write state snapshot", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	// Destroy all the destructed accounts along with their storage
+	for hash := range bottom.destructSet {
+		if !covered(marker, hash[:]) {
+			continue
+		}
+		rawdb.DeleteAccountSnapshot(batch, hash)
+		wipeKeys(base.diskdb, batch, append(append([]byte{}, rawdb.SnapshotStoragePrefix...), hash[:]...), nil)
+		flush()
+	}
+	// Push all updated accounts into the database
+	for hash, data := range bottom.accountData {
+		if !covered(marker, hash[:]) {
+			continue
+		}
+		if len(data) > 0 {
+			rawdb.WriteAccountSnapshot(batch, hash, data)
+		} else {
+			rawdb.DeleteAccountSnapshot(batch, hash)
+		}
+		flush()
+	}
+	// Push all the storage slots into the database
+	for accountHash, storage := range bottom.storageData {
+		if !covered(marker, accountHash[:]) {
+			continue
+		}
+		for storageHash, data := range storage {
+			if !covered(marker, append(accountHash[:], storageHash[:]...)) {
+				continue
+			}
+			if len(data) > 0 {
+				rawdb.WriteStorageSnapshot(batch, accountHash, storageHash, data)
+			} else {
+				rawdb.DeleteStorageSnapshot(batch, accountHash, storageHash)
+			}
+		}
+		flush()
+	}
+	// Update the snapshot block marker and write any remainder data
+	rawdb.WriteSnapshotRoot(batch, bottom.root)
+	if status != nil {
+		writeGenerator(batch, status)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write leftover snapshot", "err", err)
+	}
+	base.markStale()
+	bottom.markStale()
+
+	return &diskLayer{
+		diskdb:    base.diskdb,
+		triedb:    base.triedb,
+		root:      bottom.root,
+		genMarker: marker,
+	}
+}
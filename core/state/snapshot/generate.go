@@ -0,0 +1,359 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/rlp"
+	"github.com/doslink/dos/trie"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+)
+
+var (
+	// emptyRoot is the known root hash of an empty trie.
+	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+	// logInterval is the frequency of the generation progress reports.
+	logInterval = 8 * time.Second
+)
+
+// account is the consensus representation of accounts, mirroring state.Account
+// to be able to find the storage tries while walking the account trie.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// generatorStatus is the persisted progress of the snapshot generation.
+type generatorStatus struct {
+	Wiping bool   // Whether the stale snapshot data is still being deleted
+	Marker []byte // Key of the last item fully generated, empty if none yet
+}
+
+// prefixIteratee is implemented by key-value stores able to iterate over a
+// subset of their content.
+type prefixIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) iterator.Iterator
+}
+
+// loadGenerator retrieves the progress of an interrupted snapshot generation,
+// or nil if the persisted snapshot is complete.
+func loadGenerator(db dosdb.Database) *generatorStatus {
+	blob := rawdb.ReadSnapshotGenerator(db)
+	if len(blob) == 0 {
+		return nil
+	}
+	status := new(generatorStatus)
+	if err := rlp.DecodeBytes(blob, status); err != nil {
+		log.Warn("Failed to decode snapshot generator", "err", err)
+		return &generatorStatus{Wiping: true, Marker: []byte{}}
+	}
+	if status.Marker == nil {
+		status.Marker = []byte{}
+	}
+	return status
+}
+
+// writeGenerator persists the progress of the snapshot generation.
+func writeGenerator(db dosdb.Putter, status *generatorStatus) {
+	blob, err := rlp.EncodeToBytes(status)
+	if err != nil {
+		log.Crit("Failed to encode snapshot generator", "err", err)
+	}
+	rawdb.WriteSnapshotGenerator(db, blob)
+}
+
+// loadSnapshot loads the persisted snapshot if it matches the given root, or
+// starts generating a new one otherwise.
+func loadSnapshot(diskdb dosdb.Database, triedb *trie.Database, root common.Hash, rebuild bool) *diskLayer {
+	if !rebuild {
+		persisted := rawdb.ReadSnapshotRoot(diskdb)
+		if persisted == root {
+			base := &diskLayer{
+				diskdb: diskdb,
+				triedb: triedb,
+				root:   root,
+			}
+			status := loadGenerator(diskdb)
+			if status == nil {
+				log.Info("Loaded state snapshot", "root", root)
+				return base
+			}
+			log.Info("Resuming state snapshot generation", "root", root, "wiping", status.Wiping, "at", common.ToHex(status.Marker))
+			base.genMarker = status.Marker
+			if status.Wiping {
+				base.genMarker = []byte{}
+			}
+			base.startGeneration(status)
+			return base
+		}
+		if persisted != (common.Hash{}) {
+			log.Warn("State snapshot does not match chain head, rebuilding", "snapshot", persisted, "head", root)
+		}
+	}
+	return generateSnapshot(diskdb, triedb, root)
+}
+
+// generateSnapshot invalidates any previously persisted snapshot and starts
+// regenerating it in the background from the state trie of the given root.
+func generateSnapshot(diskdb dosdb.Database, triedb *trie.Database, root common.Hash) *diskLayer {
+	status := &generatorStatus{Wiping: true, Marker: []byte{}}
+
+	batch := diskdb.NewBatch()
+	rawdb.WriteSnapshotRoot(batch, root)
+	writeGenerator(batch, status)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write snapshot generator", "err", err)
+	}
+	base := &diskLayer{
+		diskdb:    diskdb,
+		triedb:    triedb,
+		root:      root,
+		genMarker: []byte{},
+	}
+	log.Info("Started state snapshot generation", "root", root)
+	base.startGeneration(status)
+	return base
+}
+
+// startGeneration spins up the background generation of the snapshot, resuming
+// from the given progress.
+func (dl *diskLayer) startGeneration(status *generatorStatus) {
+	dl.genAbort = make(chan chan *generatorStatus)
+	go dl.generate(status)
+}
+
+// stopGeneration terminates the background generation of the snapshot, if any,
+// returning the progress it reached. Nil is returned if the snapshot is complete.
+func (dl *diskLayer) stopGeneration() *generatorStatus {
+	if dl.genAbort == nil {
+		return nil
+	}
+	abort := make(chan *generatorStatus)
+	dl.genAbort <- abort
+	dl.genAbort = nil
+
+	return <-abort
+}
+
+// generate is a background thread that iterates over the state and storage
+// tries of the disk layer's root, constructing the flat snapshot for them. The
+// generator can be aborted any time, after which it reports the progress made
+// to be resumed on top of a newer disk layer.
+func (dl *diskLayer) generate(status *generatorStatus) {
+	var (
+		accounts int
+		slots    int
+		start    = time.Now()
+		logged   = time.Now()
+		marker   = status.Marker
+		last     = status.Marker
+		batch    = dl.diskdb.NewBatch()
+		requests = dl.genAbort
+		abort    chan *generatorStatus
+	)
+	// aborted checks whether the generator was requested to stop
+	aborted := func() bool {
+		select {
+		case abort = <-requests:
+			return true
+		default:
+			return false
+		}
+	}
+	// Delete any leftover stale data before generating anything
+	if status.Wiping {
+		if !wipeSnapshot(dl.diskdb, aborted) {
+			abort <- &generatorStatus{Wiping: true, Marker: []byte{}}
+			return
+		}
+		writeGenerator(dl.diskdb, &generatorStatus{Marker: []byte{}})
+		log.Info("Wiped stale state snapshot", "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	// checkpoint persists the generated data along with the progress marker if the
+	// batch grew large enough, the generator was aborted or it's forced to
+	checkpoint := func(force bool) bool {
+		stop := aborted()
+		if batch.ValueSize() > dosdb.IdealBatchSize || stop || force {
+			writeGenerator(batch, &generatorStatus{Marker: last})
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write state snapshot", "err", err)
+			}
+			batch.Reset()
+
+			dl.lock.Lock()
+			dl.genMarker = last
+			dl.lock.Unlock()
+		}
+		if stop {
+			abort <- &generatorStatus{Marker: last}
+		}
+		return stop
+	}
+	// fail persists the progress made and waits until the generator is aborted,
+	// which will restart it on top of a newer, hopefully available state
+	fail := func(err error) {
+		log.Warn("State snapshot generation failed", "root", dl.root, "err", err)
+		if !checkpoint(true) {
+			abort = <-requests
+			abort <- &generatorStatus{Marker: last}
+		}
+	}
+	accTrie, err := trie.New(dl.root, dl.triedb)
+	if err != nil {
+		fail(err)
+		return
+	}
+	var origin []byte
+	if len(marker) > 0 {
+		origin = marker[:common.HashLength]
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(origin))
+	for accIt.Next() {
+		accountHash := common.BytesToHash(accIt.Key)
+
+		// Skip the account the generator finished with in its previous run
+		if len(marker) == common.HashLength && bytes.Equal(accountHash[:], marker) {
+			continue
+		}
+		rawdb.WriteAccountSnapshot(batch, accountHash, accIt.Value)
+		accounts++
+
+		var acc account
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			log.Crit("Invalid account encountered during snapshot creation", "err", err)
+		}
+		if acc.Root != emptyRoot {
+			// Resume the storage iteration if the previous run stopped within it
+			var origin []byte
+			if len(marker) == 2*common.HashLength && bytes.Equal(accountHash[:], marker[:common.HashLength]) {
+				origin = marker[common.HashLength:]
+			}
+			storeTrie, err := trie.New(acc.Root, dl.triedb)
+			if err != nil {
+				fail(err)
+				return
+			}
+			storeIt := trie.NewIterator(storeTrie.NodeIterator(origin))
+			for storeIt.Next() {
+				rawdb.WriteStorageSnapshot(batch, accountHash, common.BytesToHash(storeIt.Key), storeIt.Value)
+				slots++
+
+				last = append(common.CopyBytes(accountHash[:]), storeIt.Key...)
+				if checkpoint(false) {
+					return
+				}
+			}
+			if storeIt.Err != nil {
+				fail(storeIt.Err)
+				return
+			}
+		}
+		last = common.CopyBytes(accountHash[:])
+		if checkpoint(false) {
+			return
+		}
+		if time.Since(logged) > logInterval {
+			log.Info("Generating state snapshot", "root", dl.root, "at", accountHash, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if accIt.Err != nil {
+		fail(accIt.Err)
+		return
+	}
+	// Snapshot fully generated, persist the remainder and mark it complete
+	rawdb.DeleteSnapshotGenerator(batch)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write state snapshot", "err", err)
+	}
+	log.Info("Generated state snapshot", "root", dl.root, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	dl.lock.Lock()
+	dl.genMarker = nil
+	dl.lock.Unlock()
+
+	// Someone will be looking for us, wait it out
+	abort = <-requests
+	abort <- nil
+}
+
+// wipeSnapshot deletes all the account and storage entries of the persisted
+// snapshot. It returns false if the wiping was aborted half way through.
+func wipeSnapshot(db dosdb.Database, aborted func() bool) bool {
+	batch := db.NewBatch()
+	for _, prefix := range [][]byte{rawdb.SnapshotAccountPrefix, rawdb.SnapshotStoragePrefix} {
+		if !wipeKeys(db, batch, prefix, aborted) {
+			return false
+		}
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to wipe state snapshot", "err", err)
+	}
+	return true
+}
+
+// wipeKeys deletes all the keys with the given prefix from the database through
+// the batch, flushing it whenever it grows large. The optional abort callback
+// is polled after every flush, returning false if it requested a stop.
+func wipeKeys(db dosdb.Database, batch dosdb.Batch, prefix []byte, aborted func() bool) bool {
+	// flush writes out the batch if it grew large enough
+	flush := func() bool {
+		if batch.ValueSize() > dosdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to wipe state snapshot", "err", err)
+			}
+			batch.Reset()
+
+			if aborted != nil && aborted() {
+				return false
+			}
+		}
+		return true
+	}
+	switch kv := rawdb.KeyValueStore(db).(type) {
+	case prefixIteratee:
+		it := kv.NewIteratorWithPrefix(prefix)
+		defer it.Release()
+
+		for it.Next() {
+			batch.Delete(common.CopyBytes(it.Key()))
+			if !flush() {
+				return false
+			}
+		}
+	case *dosdb.MemDatabase:
+		for _, key := range kv.Keys() {
+			if bytes.HasPrefix(key, prefix) {
+				batch.Delete(key)
+				if !flush() {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
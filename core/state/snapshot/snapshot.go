@@ -0,0 +1,242 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package snapshot implements a flat, layered dump of the state trie.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/log"
+	"github.com/doslink/dos/trie"
+)
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
+	// layer had been invalidated due to the chain progressing forward far enough
+	// to not maintain the layer's original state.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the underlying snapshot
+	// is being generated currently and the requested data item is not yet in the
+	// range of accounts covered.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	// errSnapshotCycle is returned if a snapshot is attempted to be inserted
+	// that forms a cycle in the snapshot tree.
+	errSnapshotCycle = errors.New("snapshot cycle")
+)
+
+// Snapshot represents the functionality supported by a snapshot storage layer.
+// All the data is returned in the same encoding the state tries use for their
+// leaves, a nil blob meaning the item does not exist.
+type Snapshot interface {
+	// Root returns the root hash for which this snapshot was made.
+	Root() common.Hash
+
+	// AccountRLP directly retrieves the account RLP associated with a particular
+	// hash in the snapshot.
+	AccountRLP(hash common.Hash) ([]byte, error)
+
+	// Storage directly retrieves the storage data associated with a particular
+	// hash, within a particular account.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+}
+
+// snapshot is the internal version of the snapshot data layer that supports
+// some additional methods compared to the public API.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the subsequent layer of a snapshot, or nil if the base was
+	// reached.
+	Parent() snapshot
+
+	// Stale return whether this layer has become stale (was flattened across) or
+	// if it's still live.
+	Stale() bool
+}
+
+// Tree is a Doslink state snapshot tree. It consists of one persistent base
+// layer backed by a key-value store, on top of which arbitrarily many in-memory
+// diff layers are topped. The memory diffs can form a tree with branching, but
+// the disk layer is singleton and common to all. If a reorg goes deeper than
+// the disk layer, everything needs to be regenerated.
+//
+// The goal of a state snapshot is twofold: to allow direct access to account
+// and storage data to avoid expensive multi-level trie lookups; and to allow
+// sorted, cheap iteration of the account/storage tries for sync aid.
+type Tree struct {
+	diskdb dosdb.Database           // Persistent database to store the snapshot
+	triedb *trie.Database           // In-memory cache to access the trie through
+	layers map[common.Hash]snapshot // Collection of all known layers
+	lock   sync.RWMutex
+}
+
+// New attempts to load an already existing snapshot from a persistent key-value
+// store, ensuring that the head of the snapshot matches the expected one.
+//
+// If the snapshot is missing, stale or the caller explicitly requests it, the
+// snapshot is wiped and regenerated in the background from the state trie of
+// the given root. Until the generation finishes, accesses to the not yet
+// covered items fail with ErrNotCoveredYet.
+func New(diskdb dosdb.Database, triedb *trie.Database, root common.Hash, rebuild bool) *Tree {
+	base := loadSnapshot(diskdb, triedb, root, rebuild)
+	return &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: map[common.Hash]snapshot{base.root: base},
+	}
+}
+
+// Snapshot retrieves a snapshot belonging to the given block root, or nil if no
+// snapshot is maintained for that block.
+func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if layer, ok := t.layers[blockRoot]; ok {
+		return layer
+	}
+	return nil
+}
+
+// Update adds a new snapshot into the tree, if that can be linked to an existing
+// old parent. It is disallowed to insert a disk layer (the origin of all).
+func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) error {
+	// Reject noop updates to avoid self-loops in the snapshot tree. This is a
+	// special case that can only happen for clique networks where empty blocks
+	// don't modify the state (0 block subsidy).
+	if blockRoot == parentRoot {
+		return errSnapshotCycle
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	if _, ok := t.layers[blockRoot]; ok {
+		return nil
+	}
+	t.layers[blockRoot] = newDiffLayer(parent, blockRoot, destructs, accounts, storage)
+	return nil
+}
+
+// Cap traverses downwards the snapshot tree from a head block hash until the
+// number of allowed layers are crossed. All layers beyond the permitted number
+// are flattened downwards into the disk layer. Layers on other branches which
+// are not descendants of the new disk layer are dropped as stale.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	// Collect the diff layers from the head down to the disk layer
+	var chain []*diffLayer
+	for layer := snap; ; layer = layer.Parent() {
+		diff, ok := layer.(*diffLayer)
+		if !ok {
+			break
+		}
+		chain = append(chain, diff)
+	}
+	if len(chain) <= layers {
+		return nil
+	}
+	// Stop any background generation and flatten the excess layers into the disk
+	base := chain[len(chain)-1].Parent().(*diskLayer)
+	status := base.stopGeneration()
+
+	for i := len(chain) - 1; i >= layers; i-- {
+		base = diffToDisk(chain[i], base, status)
+	}
+	if layers > 0 {
+		chain[layers-1].setParent(base)
+	}
+	if status != nil {
+		base.startGeneration(status)
+	}
+	// Remove all the layers not building on top of the new disk layer
+	for hash, layer := range t.layers {
+		if !descendsFrom(layer, base) {
+			if diff, ok := layer.(*diffLayer); ok {
+				diff.markStale()
+			}
+			delete(t.layers, hash)
+		}
+	}
+	t.layers[base.root] = base
+	return nil
+}
+
+// Rebuild wipes all available snapshot data from the persistent database and
+// discards all the in-memory layers, starting the generation of a new snapshot
+// in the background from the state trie of the given root.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		switch layer := layer.(type) {
+		case *diskLayer:
+			layer.stopGeneration()
+			layer.markStale()
+		case *diffLayer:
+			layer.markStale()
+		}
+	}
+	base := generateSnapshot(t.diskdb, t.triedb, root)
+	t.layers = map[common.Hash]snapshot{root: base}
+}
+
+// Stop terminates the background generation of the snapshot, if running, after
+// persisting its progress so that it can be resumed after a restart.
+func (t *Tree) Stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		if base, ok := layer.(*diskLayer); ok {
+			if status := base.stopGeneration(); status != nil {
+				log.Info("Paused state snapshot generation", "root", base.root, "at", common.ToHex(status.Marker))
+			}
+			base.markStale()
+		}
+	}
+}
+
+// descendsFrom returns whether the given base layer is an ancestor of the layer
+// (or the layer itself).
+func descendsFrom(layer snapshot, base *diskLayer) bool {
+	for ; layer != nil; layer = layer.Parent() {
+		if layer == snapshot(base) {
+			return true
+		}
+		if layer.Stale() {
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/rlp"
+	"github.com/doslink/dos/trie"
+)
+
+// makeTestState creates a small state with a few accounts, one of them holding
+// some storage slots, committing it to disk. The account and slot keys are the
+// hashes of their index.
+func makeTestState(t *testing.T, db dosdb.Database) (*trie.Database, common.Hash) {
+	triedb := trie.NewDatabase(db)
+
+	storeTrie, _ := trie.New(common.Hash{}, triedb)
+	for i := byte(1); i <= 3; i++ {
+		value, _ := rlp.EncodeToBytes([]byte{i})
+		storeTrie.Update(crypto.Keccak256([]byte{i}), value)
+	}
+	storeRoot, err := storeTrie.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit storage trie: %v", err)
+	}
+	accTrie, _ := trie.New(common.Hash{}, triedb)
+	for i := byte(1); i <= 3; i++ {
+		acc := account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: emptyRoot, CodeHash: crypto.Keccak256(nil)}
+		if i == 1 {
+			acc.Root = storeRoot
+		}
+		blob, _ := rlp.EncodeToBytes(acc)
+		accTrie.Update(crypto.Keccak256([]byte{i}), blob)
+	}
+	root, err := accTrie.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	if err := triedb.Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	return triedb, root
+}
+
+// waitGeneration blocks until the background generation of the disk layer of
+// the tree completes.
+func waitGeneration(t *testing.T, tree *Tree, root common.Hash) {
+	base := tree.Snapshot(root).(*diskLayer)
+	for i := 0; i < 500; i++ {
+		base.lock.RLock()
+		done := base.genMarker == nil
+		base.lock.RUnlock()
+
+		if done {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("snapshot generation timed out")
+}
+
+func hashOf(i byte) common.Hash {
+	return crypto.Keccak256Hash([]byte{i})
+}
+
+// Tests that a snapshot can be generated from a state trie, that diff layers on
+// top shadow the disk data and that flattening them persists the changes.
+func TestSnapshotLayers(t *testing.T) {
+	db := dosdb.NewMemDatabase()
+	triedb, root := makeTestState(t, db)
+
+	// Leave some junk behind which the generator needs to wipe
+	rawdb.WriteAccountSnapshot(db, hashOf(9), []byte{0x09})
+
+	tree := New(db, triedb, root, false)
+	waitGeneration(t, tree, root)
+
+	base := tree.Snapshot(root)
+	if blob, err := base.AccountRLP(hashOf(9)); err != nil || blob != nil {
+		t.Fatalf("stale account retained: %x, %v", blob, err)
+	}
+	for i := byte(1); i <= 3; i++ {
+		blob, err := base.AccountRLP(hashOf(i))
+		if err != nil {
+			t.Fatalf("account %d: failed to retrieve: %v", i, err)
+		}
+		var acc account
+		if err := rlp.DecodeBytes(blob, &acc); err != nil || acc.Nonce != uint64(i) {
+			t.Fatalf("account %d: mismatch: %v (err %v)", i, acc, err)
+		}
+		want, _ := rlp.EncodeToBytes([]byte{i})
+		if blob, _ := base.Storage(hashOf(1), hashOf(i)); !bytes.Equal(blob, want) {
+			t.Fatalf("slot %d: mismatch: have %x, want %x", i, blob, want)
+		}
+	}
+	// Stack a diff layer on top, modifying, deleting and recreating items
+	diffRoot := common.HexToHash("0x01")
+	err := tree.Update(diffRoot, root,
+		map[common.Hash]struct{}{hashOf(1): {}, hashOf(2): {}},
+		map[common.Hash][]byte{hashOf(1): {0x01}, hashOf(3): {0x03}},
+		map[common.Hash]map[common.Hash][]byte{hashOf(1): {hashOf(2): {0x02}}},
+	)
+	if err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+	if err := tree.Update(root, root, nil, nil, nil); err != errSnapshotCycle {
+		t.Fatalf("cyclic update error mismatch: have %v, want %v", err, errSnapshotCycle)
+	}
+	check := func(snap Snapshot) {
+		if blob, _ := snap.AccountRLP(hashOf(1)); !bytes.Equal(blob, []byte{0x01}) {
+			t.Errorf("recreated account mismatch: %x", blob)
+		}
+		if blob, _ := snap.AccountRLP(hashOf(2)); blob != nil {
+			t.Errorf("deleted account retained: %x", blob)
+		}
+		if blob, _ := snap.AccountRLP(hashOf(3)); !bytes.Equal(blob, []byte{0x03}) {
+			t.Errorf("updated account mismatch: %x", blob)
+		}
+		if blob, _ := snap.Storage(hashOf(1), hashOf(1)); blob != nil {
+			t.Errorf("destructed slot retained: %x", blob)
+		}
+		if blob, _ := snap.Storage(hashOf(1), hashOf(2)); !bytes.Equal(blob, []byte{0x02}) {
+			t.Errorf("recreated slot mismatch: %x", blob)
+		}
+	}
+	diff := tree.Snapshot(diffRoot)
+	check(diff)
+
+	if blob, _ := base.Storage(hashOf(1), hashOf(1)); len(blob) == 0 {
+		t.Fatalf("disk layer modified by diff layer")
+	}
+	// Flatten the diff into the disk and ensure the data got persisted
+	if err := tree.Cap(diffRoot, 0); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	if _, err := base.AccountRLP(hashOf(1)); err != ErrSnapshotStale {
+		t.Fatalf("stale disk layer error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	if _, err := diff.AccountRLP(hashOf(1)); err != ErrSnapshotStale {
+		t.Fatalf("stale diff layer error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	if tree.Snapshot(root) != nil {
+		t.Fatalf("flattened layer still available")
+	}
+	check(tree.Snapshot(diffRoot))
+
+	// Reload the snapshot from disk and ensure it doesn't need regeneration
+	if persisted := rawdb.ReadSnapshotRoot(db); persisted != diffRoot {
+		t.Fatalf("persisted root mismatch: have %x, want %x", persisted, diffRoot)
+	}
+	tree = New(db, triedb, diffRoot, false)
+	if snap := tree.Snapshot(diffRoot).(*diskLayer); snap.genMarker != nil {
+		t.Fatalf("complete snapshot regenerated on load")
+	}
+	check(tree.Snapshot(diffRoot))
+}
+
+// Tests that flattening layers into a disk layer still being generated only
+// persists the data already covered, and the generation continues on top.
+func TestSnapshotGenerationInterrupt(t *testing.T) {
+	db := dosdb.NewMemDatabase()
+	triedb, root := makeTestState(t, db)
+
+	tree := New(db, triedb, root, false)
+	waitGeneration(t, tree, root)
+
+	// Pretend the generator was paused right after the first account
+	first, second := hashOf(1), hashOf(2)
+	if bytes.Compare(first[:], second[:]) > 0 {
+		first, second = second, first
+	}
+	rawdb.DeleteAccountSnapshot(db, second)
+
+	base := tree.Snapshot(root).(*diskLayer)
+	base.genMarker = first[:]
+	base.genAbort = make(chan chan *generatorStatus)
+	go func() {
+		abort := <-base.genAbort
+		abort <- &generatorStatus{Marker: first[:]}
+	}()
+	// Modify both accounts in the trie and flatten the change into the disk layer
+	accTrie, _ := trie.New(root, triedb)
+	blobs := make(map[common.Hash][]byte)
+	for _, hash := range []common.Hash{first, second} {
+		blobs[hash], _ = rlp.EncodeToBytes(account{Nonce: 10, Balance: new(big.Int), Root: emptyRoot, CodeHash: crypto.Keccak256(nil)})
+		accTrie.Update(hash[:], blobs[hash])
+	}
+	diffRoot, _ := accTrie.Commit(nil)
+	if err := triedb.Commit(diffRoot, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	if err := tree.Update(diffRoot, root, nil, map[common.Hash][]byte{first: {0xff}, second: {0xff}}, nil); err != nil {
+		t.Fatalf("failed to update snapshot tree: %v", err)
+	}
+	if err := tree.Cap(diffRoot, 0); err != nil {
+		t.Fatalf("failed to flatten snapshot tree: %v", err)
+	}
+	waitGeneration(t, tree, diffRoot)
+
+	// The covered account must come from the diff, the rest from the new trie
+	snap := tree.Snapshot(diffRoot)
+	if blob, _ := snap.AccountRLP(first); !bytes.Equal(blob, []byte{0xff}) {
+		t.Errorf("covered account mismatch: have %x, want %x", blob, []byte{0xff})
+	}
+	if blob, _ := snap.AccountRLP(second); !bytes.Equal(blob, blobs[second]) {
+		t.Errorf("generated account mismatch: have %x, want %x", blob, blobs[second])
+	}
+	tree.Stop()
+}
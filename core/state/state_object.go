@@ -77,8 +77,10 @@ type stateObject struct {
 	trie Trie // storage trie, which becomes non-nil on first access
 	code Code // contract bytecode, which gets set when code is loaded
 
+	originStorage Storage // Storage entries as of the last commit, to track the original values
 	cachedStorage Storage // Storage entry cache to avoid duplicate reads
 	dirtyStorage  Storage // Storage entries that need to be flushed to disk
+	fakeStorage   Storage // Fake committed storage replacing the real one, for call simulations only
 
 	// Cache flags.
 	// When an object is marked suicided it will be delete from the trie
@@ -86,6 +88,7 @@ type stateObject struct {
 	dirtyCode bool // true if the code was updated
 	suicided  bool
 	deleted   bool
+	created   bool // true if the account was created anew, its storage is not in the snapshot
 }
 
 // empty returns whether the account is considered empty.
@@ -115,6 +118,7 @@ func newObject(db *StateDB, address common.Address, data Account) *stateObject {
 		address:       address,
 		addrHash:      crypto.Keccak256Hash(address[:]),
 		data:          data,
+		originStorage: make(Storage),
 		cachedStorage: make(Storage),
 		dirtyStorage:  make(Storage),
 	}
@@ -165,11 +169,36 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 	if exists {
 		return value
 	}
-	// Load from DB in case it is missing.
-	enc, err := self.getTrie(db).TryGet(key[:])
-	if err != nil {
-		self.setError(err)
-		return common.Hash{}
+	value = self.GetCommittedState(db, key)
+	self.cachedStorage[key] = value
+	return value
+}
+
+// GetCommittedState retrieves a value from the committed account storage trie,
+// ignoring any modifications made since the last commit.
+func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	// If the fake storage is set, it's the committed state, don't touch the trie
+	if self.fakeStorage != nil {
+		return self.fakeStorage[key]
+	}
+	value, exists := self.originStorage[key]
+	if exists {
+		return value
+	}
+	// If the object was loaded from the database, attempt to use snapshots
+	var (
+		enc []byte
+		err error
+	)
+	if self.db.snap != nil && !self.created {
+		enc, err = self.db.snap.Storage(self.addrHash, crypto.Keccak256Hash(key[:]))
+	}
+	// If snapshot unavailable or reading from it failed, load from the database
+	if self.db.snap == nil || self.created || err != nil {
+		if enc, err = self.getTrie(db).TryGet(key[:]); err != nil {
+			self.setError(err)
+			return common.Hash{}
+		}
 	}
 	if len(enc) > 0 {
 		_, content, _, err := rlp.Split(enc)
@@ -178,7 +207,7 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		}
 		value.SetBytes(content)
 	}
-	self.cachedStorage[key] = value
+	self.originStorage[key] = value
 	return value
 }
 
@@ -197,18 +226,59 @@ func (self *stateObject) setState(key, value common.Hash) {
 	self.dirtyStorage[key] = value
 }
 
+// SetStorage replaces the entire storage of the account with the given one.
+// Afterwards all the original state is ignored and the fake storage acts as the
+// committed one, with later writes journalled on top as usual. The replacement
+// itself is not journalled and never committed to the database, so it must only
+// be used on throwaway states, e.g. for call simulations.
+func (self *stateObject) SetStorage(storage map[common.Hash]common.Hash) {
+	if self.fakeStorage == nil {
+		self.fakeStorage = make(Storage)
+	}
+	for key, value := range storage {
+		self.fakeStorage[key] = value
+	}
+	// Drop any cached or pending values of the replaced storage
+	self.cachedStorage = make(Storage)
+	self.dirtyStorage = make(Storage)
+}
+
 // updateTrie writes cached storage modifications into the object's storage trie.
 func (self *stateObject) updateTrie(db Database) Trie {
+	// Fake storage is never flushed, its modifications become the new original
+	if self.fakeStorage != nil {
+		for key, value := range self.dirtyStorage {
+			delete(self.dirtyStorage, key)
+			self.fakeStorage[key] = value
+		}
+		return self.getTrie(db)
+	}
+	// If state snapshotting is active, gather the storage changes for the commit
+	var storage map[common.Hash][]byte
+	if self.db.snap != nil && len(self.dirtyStorage) > 0 {
+		if storage = self.db.snapStorage[self.addrHash]; storage == nil {
+			storage = make(map[common.Hash][]byte)
+			self.db.snapStorage[self.addrHash] = storage
+		}
+	}
 	tr := self.getTrie(db)
 	for key, value := range self.dirtyStorage {
 		delete(self.dirtyStorage, key)
+		self.originStorage[key] = value
+
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
+			if storage != nil {
+				storage[crypto.Keccak256Hash(key[:])] = nil
+			}
 			continue
 		}
 		// Encoding []byte cannot fail, ok to ignore the error.
 		v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
 		self.setError(tr.TryUpdate(key[:], v))
+		if storage != nil {
+			storage[crypto.Keccak256Hash(key[:])] = v
+		}
 	}
 	return tr
 }
@@ -280,9 +350,14 @@ func (self *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.code = self.code
 	stateObject.dirtyStorage = self.dirtyStorage.Copy()
 	stateObject.cachedStorage = self.dirtyStorage.Copy()
+	stateObject.originStorage = self.originStorage.Copy()
+	if self.fakeStorage != nil {
+		stateObject.fakeStorage = self.fakeStorage.Copy()
+	}
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
+	stateObject.created = self.created
 	return stateObject
 }
 
@@ -27,12 +27,17 @@ import (
 	"strings"
 	"testing"
 	"testing/quick"
+	"time"
 
 	check "gopkg.in/check.v1"
 
 	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/state/snapshot"
 	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
 	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/rlp"
+	"github.com/doslink/dos/trie"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -435,3 +440,163 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that a state backed by a snapshot tree serves its reads from the flat
+// layers and pushes the modifications into the tree on commit.
+func TestSnapshotTreeAccess(t *testing.T) {
+	var (
+		db    = dosdb.NewMemDatabase()
+		sdb   = NewDatabase(db)
+		addr1 = common.BytesToAddress([]byte{0x01})
+		addr2 = common.BytesToAddress([]byte{0x02})
+		slot  = common.BytesToHash([]byte{0x01})
+	)
+	state, _ := New(common.Hash{}, sdb)
+	state.SetBalance(addr1, big.NewInt(1))
+	state.SetState(addr1, slot, common.BytesToHash([]byte{0x01}))
+	state.SetBalance(addr2, big.NewInt(2))
+	state.SetState(addr2, slot, common.BytesToHash([]byte{0x02}))
+	root, _ := state.Commit(false)
+	if err := sdb.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	snaps := snapshot.New(db, sdb.TrieDB(), root, false)
+	for i := 0; ; i++ {
+		if _, err := snaps.Snapshot(root).AccountRLP(crypto.Keccak256Hash(addr2[:])); err == nil {
+			break
+		}
+		if i == 500 {
+			t.Fatalf("snapshot generation timed out")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// Modify one account and destroy the other, committing into the tree
+	state, _ = NewWithSnapshot(root, sdb, snaps)
+	if balance := state.GetBalance(addr1); balance.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("balance mismatch: have %v, want %v", balance, 1)
+	}
+	state.SetState(addr1, slot, common.BytesToHash([]byte{0x03}))
+	state.Suicide(addr2)
+	root, _ = state.Commit(false)
+
+	snap := snaps.Snapshot(root)
+	if snap == nil {
+		t.Fatalf("snapshot layer missing for committed state")
+	}
+	if enc, _ := snap.AccountRLP(crypto.Keccak256Hash(addr2[:])); enc != nil {
+		t.Fatalf("destructed account retained: %x", enc)
+	}
+	if enc, _ := snap.Storage(crypto.Keccak256Hash(addr2[:]), crypto.Keccak256Hash(slot[:])); enc != nil {
+		t.Fatalf("destructed slot retained: %x", enc)
+	}
+	state, _ = NewWithSnapshot(root, sdb, snaps)
+	if value := state.GetState(addr1, slot); value != common.BytesToHash([]byte{0x03}) {
+		t.Fatalf("slot mismatch: have %x, want %x", value, []byte{0x03})
+	}
+	// Recreating the destroyed account must not resurrect its old storage
+	state.CreateAccount(addr2)
+	if value := state.GetState(addr2, slot); value != (common.Hash{}) {
+		t.Fatalf("recreated account slot mismatch: have %x, want empty", value)
+	}
+	// Reusing the state after commit must not wipe the recreated storage again
+	state.SetState(addr2, slot, common.BytesToHash([]byte{0x04}))
+	root, _ = state.Commit(false)
+
+	state.SetBalance(addr2, big.NewInt(4))
+	root, _ = state.Commit(false)
+
+	if enc, _ := snaps.Snapshot(root).Storage(crypto.Keccak256Hash(addr2[:]), crypto.Keccak256Hash(slot[:])); len(enc) == 0 {
+		t.Fatalf("recreated slot wiped from the snapshot")
+	}
+}
+
+// Tests that the account and storage proofs returned by the state database can
+// be verified against the state root and the account's storage root.
+func TestGetProof(t *testing.T) {
+	sdb := NewDatabase(dosdb.NewMemDatabase())
+	state, _ := New(common.Hash{}, sdb)
+
+	addr, missing := common.BytesToAddress([]byte{0x01}), common.BytesToAddress([]byte{0x02})
+	slot := common.BytesToHash([]byte{0x01})
+	state.SetBalance(addr, big.NewInt(42))
+	state.SetState(addr, slot, common.BytesToHash([]byte{0x02}))
+	root, _ := state.Commit(false)
+	state, _ = New(root, sdb)
+
+	// verify checks a proof of the key against the given root
+	verify := func(root common.Hash, key []byte, proof [][]byte) []byte {
+		db := dosdb.NewMemDatabase()
+		for _, node := range proof {
+			db.Put(crypto.Keccak256(node), node)
+		}
+		value, err, _ := trie.VerifyProof(root, crypto.Keccak256(key), db)
+		if err != nil {
+			t.Fatalf("proof of %x failed to verify: %v", key, err)
+		}
+		return value
+	}
+	proof, err := state.GetProof(addr)
+	if err != nil {
+		t.Fatalf("failed to prove account: %v", err)
+	}
+	if value := verify(root, addr[:], proof); value == nil {
+		t.Fatalf("account proof resolved to empty value")
+	}
+	proof, err = state.GetStorageProof(addr, slot)
+	if err != nil {
+		t.Fatalf("failed to prove storage slot: %v", err)
+	}
+	enc, _ := rlp.EncodeToBytes([]byte{0x02})
+	if value := verify(state.StorageTrie(addr).Hash(), slot[:], proof); !bytes.Equal(value, enc) {
+		t.Fatalf("storage proof value mismatch: have %x, want %x", value, enc)
+	}
+	// Proofs of absence for missing accounts must verify too
+	proof, err = state.GetProof(missing)
+	if err != nil {
+		t.Fatalf("failed to prove missing account: %v", err)
+	}
+	if value := verify(root, missing[:], proof); value != nil {
+		t.Fatalf("missing account proof resolved to %x", value)
+	}
+	if _, err := state.GetStorageProof(missing, slot); err == nil {
+		t.Fatalf("storage proof of missing account succeeded")
+	}
+}
+
+// Tests that replacing the storage of an account hides all its original slots,
+// while leaving the persisted state untouched.
+func TestSetStorage(t *testing.T) {
+	sdb := NewDatabase(dosdb.NewMemDatabase())
+	state, _ := New(common.Hash{}, sdb)
+
+	addr := common.BytesToAddress([]byte{0x01})
+	slot1, slot2 := common.BytesToHash([]byte{0x01}), common.BytesToHash([]byte{0x02})
+	state.SetState(addr, slot1, common.BytesToHash([]byte{0x01}))
+	root, _ := state.Commit(false)
+
+	state, _ = New(root, sdb)
+	state.SetStorage(addr, map[common.Hash]common.Hash{slot2: common.BytesToHash([]byte{0x02})})
+	if value := state.GetState(addr, slot1); value != (common.Hash{}) {
+		t.Errorf("original slot not hidden: %x", value)
+	}
+	if value := state.GetState(addr, slot2); value != common.BytesToHash([]byte{0x02}) {
+		t.Errorf("fake slot mismatch: have %x, want %x", value, []byte{0x02})
+	}
+	// Writes on top of the fake storage are journalled, keeping it as the original
+	snapshot := state.Snapshot()
+	state.SetState(addr, slot2, common.BytesToHash([]byte{0x03}))
+	if value := state.GetState(addr, slot2); value != common.BytesToHash([]byte{0x03}) {
+		t.Errorf("written slot mismatch: have %x, want %x", value, []byte{0x03})
+	}
+	if value := state.GetCommittedState(addr, slot2); value != common.BytesToHash([]byte{0x02}) {
+		t.Errorf("committed slot mismatch: have %x, want %x", value, []byte{0x02})
+	}
+	state.RevertToSnapshot(snapshot)
+	if value := state.GetState(addr, slot2); value != common.BytesToHash([]byte{0x02}) {
+		t.Errorf("reverted slot mismatch: have %x, want %x", value, []byte{0x02})
+	}
+	state, _ = New(root, sdb)
+	if value := state.GetState(addr, slot1); value != common.BytesToHash([]byte{0x01}) {
+		t.Errorf("persisted slot mismatch: have %x, want %x", value, []byte{0x01})
+	}
+}
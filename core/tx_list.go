@@ -243,6 +243,20 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 	return l.txs.Get(tx.Nonce()) != nil
 }
 
+// ReplacementPrice returns the minimum gas price a transaction needs to replace
+// an existing one with the given price, considering the price bump percentage.
+func ReplacementPrice(price *big.Int, priceBump uint64) *big.Int {
+	threshold := new(big.Int).Div(new(big.Int).Mul(price, big.NewInt(100+int64(priceBump))), big.NewInt(100))
+
+	// Have to ensure that the new gas price is higher than the old gas
+	// price as well as checking the percentage threshold to ensure that
+	// this is accurate for low (Wei-level) gas price replacements
+	if threshold.Cmp(price) <= 0 {
+		threshold = new(big.Int).Add(price, big.NewInt(1))
+	}
+	return threshold
+}
+
 // Add tries to insert a new transaction into the list, returning whether the
 // transaction was accepted, and if yes, any previous transaction it replaced.
 //
@@ -251,14 +265,8 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
 	// If there's an older better transaction, abort
 	old := l.txs.Get(tx.Nonce())
-	if old != nil {
-		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
-		// Have to ensure that the new gas price is higher than the old gas
-		// price as well as checking the percentage threshold to ensure that
-		// this is accurate for low (Wei-level) gas price replacements
-		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
-			return false, nil
-		}
+	if old != nil && ReplacementPrice(old.GasPrice(), priceBump).Cmp(tx.GasPrice()) > 0 {
+		return false, nil
 	}
 	// Otherwise overwrite the old transaction with the current one
 	l.txs.Put(tx)
@@ -17,6 +17,7 @@
 package core
 
 import (
+	"math/big"
 	"math/rand"
 	"testing"
 
@@ -49,3 +50,24 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the replacement price of a transaction honours both the bump
+// percentage and the strict increase for low (Wei-level) prices.
+func TestReplacementPrice(t *testing.T) {
+	tests := []struct {
+		price int64
+		bump  uint64
+		want  int64
+	}{
+		{1, 10, 2},
+		{9, 10, 10},
+		{100, 10, 110},
+		{105, 10, 115},
+		{100, 100, 200},
+	}
+	for i, tt := range tests {
+		if have := ReplacementPrice(big.NewInt(tt.price), tt.bump); have.Int64() != tt.want {
+			t.Errorf("test %d: replacement price mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/core/types"
+)
+
+var (
+	// ErrBlacklisted is returned if the sender or the recipient of a transaction
+	// is blacklisted by the admission policy of the pool.
+	ErrBlacklisted = errors.New("blacklisted sender or recipient")
+
+	// ErrCreationNotAllowed is returned if a transaction attempts to create a
+	// contract, but its sender is not an allowed deployer.
+	ErrCreationNotAllowed = errors.New("contract creation not allowed")
+
+	// ErrSenderUnderpriced is returned if a transaction's gas price is below the
+	// minimum configured for its sender by the admission policy of the pool.
+	ErrSenderUnderpriced = errors.New("transaction underpriced for sender")
+)
+
+// TxAdmissionPolicy is a set of rules restricting the transactions admitted into
+// the pool on top of the validity checks, meant for permissioned deployments.
+type TxAdmissionPolicy struct {
+	Blacklist        []common.Address                `json:"blacklist"`        // Accounts barred from sending or receiving transactions
+	RestrictCreation bool                            `json:"restrictCreation"` // Whether contract creation is limited to the deployers
+	Deployers        []common.Address                `json:"deployers"`        // Accounts allowed to create contracts if restricted
+	MinGasPrices     map[common.Address]*hexutil.Big `json:"minGasPrices"`     // Minimum gas price accepted from specific senders
+}
+
+// txAdmission is an admission policy compiled into lookup tables.
+type txAdmission struct {
+	blacklist map[common.Address]struct{}
+	restrict  bool
+	deployers map[common.Address]struct{}
+	prices    map[common.Address]*big.Int
+}
+
+// compile converts the admission policy into lookup tables.
+func (policy *TxAdmissionPolicy) compile() *txAdmission {
+	admission := &txAdmission{
+		blacklist: make(map[common.Address]struct{}),
+		restrict:  policy.RestrictCreation,
+		deployers: make(map[common.Address]struct{}),
+		prices:    make(map[common.Address]*big.Int),
+	}
+	for _, addr := range policy.Blacklist {
+		admission.blacklist[addr] = struct{}{}
+	}
+	for _, addr := range policy.Deployers {
+		admission.deployers[addr] = struct{}{}
+	}
+	for addr, price := range policy.MinGasPrices {
+		if price != nil {
+			admission.prices[addr] = price.ToInt()
+		}
+	}
+	return admission
+}
+
+// check verifies whether a transaction is admitted by the policy.
+func (admission *txAdmission) check(from common.Address, tx *types.Transaction) error {
+	if admission == nil {
+		return nil
+	}
+	if _, ok := admission.blacklist[from]; ok {
+		return ErrBlacklisted
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := admission.blacklist[*to]; ok {
+			return ErrBlacklisted
+		}
+	} else if admission.restrict {
+		if _, ok := admission.deployers[from]; !ok {
+			return ErrCreationNotAllowed
+		}
+	}
+	if price := admission.prices[from]; price != nil && price.Cmp(tx.GasPrice()) > 0 {
+		return ErrSenderUnderpriced
+	}
+	return nil
+}
+
+// loadTxAdmissionPolicy reads an admission policy from disk, returning nil if
+// the file doesn't exist.
+func loadTxAdmissionPolicy(path string) (*TxAdmissionPolicy, error) {
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	policy := new(TxAdmissionPolicy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// saveTxAdmissionPolicy writes an admission policy to disk, replacing any older
+// one atomically.
+func saveTxAdmissionPolicy(path string, policy *TxAdmissionPolicy) error {
+	blob, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path+".new", blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".new", path)
+}
@@ -111,6 +111,20 @@ const (
 	TxStatusIncluded
 )
 
+// TxLifecycleReason is the reason for a transaction leaving its place in the pool.
+type TxLifecycleReason string
+
+const (
+	TxReplaced    TxLifecycleReason = "replaced"    // Replaced by a transaction with the same nonce
+	TxUnderpriced TxLifecycleReason = "underpriced" // Evicted below the price threshold or by better paying ones
+	TxUnfunded    TxLifecycleReason = "unfunded"    // Dropped as unpayable (low balance or out of gas)
+	TxRateLimited TxLifecycleReason = "ratelimited" // Dropped as exceeding the account or global allowances
+	TxExpired     TxLifecycleReason = "expired"     // Evicted after staying queued for too long
+	TxDemoted     TxLifecycleReason = "demoted"     // Moved back from pending into the future queue
+	TxPolicy      TxLifecycleReason = "policy"      // Dropped as violating the admission policy
+	TxStale       TxLifecycleReason = "stale"       // Dropped as its nonce was already used by an included transaction
+)
+
 // blockChain provides the state of blockchain and current gas limit to do
 // some pre checks in tx pool and event subscribers.
 type blockChain interface {
@@ -126,6 +140,7 @@ type TxPoolConfig struct {
 	NoLocals  bool          // Whether local transaction handling should be disabled
 	Journal   string        // Journal of local transactions to survive node restarts
 	Rejournal time.Duration // Time interval to regenerate the local transaction journal
+	Policy    string        // Admission policy of the pool to survive node restarts
 
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
@@ -143,6 +158,7 @@ type TxPoolConfig struct {
 var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
+	Policy:    "txpolicy.json",
 
 	PriceLimit: 1,
 	PriceBump:  10,
@@ -182,16 +198,17 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 // current state) and future transactions. Transactions move between those
 // two states over time as they are received and processed.
 type TxPool struct {
-	config       TxPoolConfig
-	chainconfig  *params.ChainConfig
-	chain        blockChain
-	gasPrice     *big.Int
-	txFeed       event.Feed
-	scope        event.SubscriptionScope
-	chainHeadCh  chan ChainHeadEvent
-	chainHeadSub event.Subscription
-	signer       types.Signer
-	mu           sync.RWMutex
+	config        TxPoolConfig
+	chainconfig   *params.ChainConfig
+	chain         blockChain
+	gasPrice      *big.Int
+	txFeed        event.Feed
+	lifecycleFeed event.Feed
+	scope         event.SubscriptionScope
+	chainHeadCh   chan ChainHeadEvent
+	chainHeadSub  event.Subscription
+	signer        types.Signer
+	mu            sync.RWMutex
 
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
@@ -200,12 +217,20 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	policy    *TxAdmissionPolicy // Admission policy restricting the accepted transactions
+	admission *txAdmission       // Admission policy compiled into lookup tables
+
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
 	beats   map[common.Address]time.Time       // Last heartbeat from each known account
 	all     map[common.Hash]*types.Transaction // All transactions to allow lookups
 	priced  *txPricedList                      // All transactions sorted by price
 
+	lifecycleLock  sync.Mutex         // Lock protecting the lifecycle event queue
+	lifecycleQueue []TxLifecycleEvent // Lifecycle events waiting to be sent, in order
+	lifecycleWake  chan struct{}      // Notification channel for newly queued lifecycle events
+	lifecycleQuit  chan struct{}      // Quit channel to tear down the lifecycle sender
+
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
@@ -219,21 +244,33 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 
 	// Create the transaction pool with its initial settings
 	pool := &TxPool{
-		config:      config,
-		chainconfig: chainconfig,
-		chain:       chain,
-		signer:      types.NewEIP155Signer(chainconfig.ChainId),
-		pending:     make(map[common.Address]*txList),
-		queue:       make(map[common.Address]*txList),
-		beats:       make(map[common.Address]time.Time),
-		all:         make(map[common.Hash]*types.Transaction),
-		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
-		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		config:        config,
+		chainconfig:   chainconfig,
+		chain:         chain,
+		signer:        types.NewEIP155Signer(chainconfig.ChainId),
+		pending:       make(map[common.Address]*txList),
+		queue:         make(map[common.Address]*txList),
+		beats:         make(map[common.Address]time.Time),
+		all:           make(map[common.Hash]*types.Transaction),
+		chainHeadCh:   make(chan ChainHeadEvent, chainHeadChanSize),
+		gasPrice:      new(big.Int).SetUint64(config.PriceLimit),
+		lifecycleWake: make(chan struct{}, 1),
+		lifecycleQuit: make(chan struct{}),
 	}
+	pool.wg.Add(1)
+	go pool.lifecycleLoop()
+
 	pool.locals = newAccountSet(pool.signer)
 	pool.priced = newTxPricedList(&pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
+	// If an admission policy was persisted, load it from disk
+	if config.Policy != "" {
+		if err := pool.ReloadAdmissionPolicy(); err != nil {
+			log.Warn("Failed to load transaction admission policy", "err", err)
+		}
+	}
+
 	// If local transactions and journaling is enabled, load from disk
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
@@ -318,7 +355,7 @@ func (pool *TxPool) loop() {
 				// Any non-locals old enough should be removed
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
-						pool.removeTx(tx.Hash(), true)
+						pool.removeTx(tx.Hash(), true, TxExpired)
 					}
 				}
 			}
@@ -436,6 +473,7 @@ func (pool *TxPool) Stop() {
 
 	// Unsubscribe subscriptions registered from blockchain
 	pool.chainHeadSub.Unsubscribe()
+	close(pool.lifecycleQuit)
 	pool.wg.Wait()
 
 	if pool.journal != nil {
@@ -450,6 +488,12 @@ func (pool *TxPool) SubscribeTxPreEvent(ch chan<- TxPreEvent) event.Subscription
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeTxLifecycleEvent registers a subscription of TxLifecycleEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxLifecycleEvent(ch chan<- TxLifecycleEvent) event.Subscription {
+	return pool.scope.Track(pool.lifecycleFeed.Subscribe(ch))
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -466,11 +510,75 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
-		pool.removeTx(tx.Hash(), false)
+		pool.removeTx(tx.Hash(), false, TxUnderpriced)
 	}
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// AdmissionPolicy returns the admission policy currently enforced by the pool.
+func (pool *TxPool) AdmissionPolicy() *TxAdmissionPolicy {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if pool.policy == nil {
+		return new(TxAdmissionPolicy)
+	}
+	policy := *pool.policy
+	return &policy
+}
+
+// SetAdmissionPolicy updates the admission policy enforced by the pool, persists
+// it to disk if configured and drops all transactions violating it.
+func (pool *TxPool) SetAdmissionPolicy(policy *TxAdmissionPolicy) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.config.Policy != "" {
+		if err := saveTxAdmissionPolicy(pool.config.Policy, policy); err != nil {
+			return err
+		}
+	}
+	pool.setAdmissionPolicy(policy)
+	return nil
+}
+
+// ReloadAdmissionPolicy reloads the admission policy persisted on disk and drops
+// all transactions violating it.
+func (pool *TxPool) ReloadAdmissionPolicy() error {
+	if pool.config.Policy == "" {
+		return errors.New("no admission policy file configured")
+	}
+	policy, err := loadTxAdmissionPolicy(pool.config.Policy)
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		policy = new(TxAdmissionPolicy)
+	}
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.setAdmissionPolicy(policy)
+	return nil
+}
+
+// setAdmissionPolicy installs a new admission policy, dropping all transactions
+// violating it. The pool lock must be held by the caller.
+func (pool *TxPool) setAdmissionPolicy(policy *TxAdmissionPolicy) {
+	pool.policy, pool.admission = policy, policy.compile()
+
+	dropped := 0
+	for hash, tx := range pool.all {
+		from, _ := types.Sender(pool.signer, tx) // already validated during insertion
+		if pool.admission.check(from, tx) != nil {
+			pool.removeTx(hash, true, TxPolicy)
+			dropped++
+		}
+	}
+	log.Info("Transaction pool admission policy updated", "blacklisted", len(policy.Blacklist),
+		"restricted", policy.RestrictCreation, "deployers", len(policy.Deployers), "prices", len(policy.MinGasPrices), "dropped", dropped)
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -519,6 +627,29 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool belonging to a
+// single account, returning its pending as well as queued transactions sorted
+// by nonce.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	var pending, queued types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		pending = list.Flatten()
+	}
+	if list, ok := pool.queue[addr]; ok {
+		queued = list.Flatten()
+	}
+	return pending, queued
+}
+
+// PriceBump returns the minimum price bump percentage required to replace an
+// already pooled transaction.
+func (pool *TxPool) PriceBump() uint64 {
+	return pool.config.PriceBump
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -570,6 +701,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Ensure the transaction is admitted by the policy of the pool
+	if err := pool.admission.check(from, tx); err != nil {
+		return err
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
@@ -628,7 +763,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
-			pool.removeTx(tx.Hash(), false)
+			pool.removeTx(tx.Hash(), false, TxUnderpriced)
 		}
 	}
 	// If the transaction is replacing an already pending one, do directly
@@ -645,6 +780,8 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			delete(pool.all, old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+
+			pool.notifyLifecycle(old, TxReplaced, tx)
 		}
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
@@ -692,6 +829,8 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		delete(pool.all, old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+
+		pool.notifyLifecycle(old, TxReplaced, tx)
 	}
 	if pool.all[hash] == nil {
 		pool.all[hash] = tx
@@ -729,6 +868,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.notifyLifecycle(tx, TxUnderpriced, nil)
 		return
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -737,6 +877,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.notifyLifecycle(old, TxReplaced, tx)
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all[hash] == nil {
@@ -750,6 +891,49 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	go pool.txFeed.Send(TxPreEvent{tx})
 }
 
+// notifyLifecycle notifies any subsystems that a transaction left its place in
+// the pool for the given reason, optionally superseded by a replacement.
+//
+// Events are queued and delivered by a single sender goroutine, so subscribers
+// observe them in the order the pool produced them.
+func (pool *TxPool) notifyLifecycle(tx *types.Transaction, reason TxLifecycleReason, replacement *types.Transaction) {
+	pool.lifecycleLock.Lock()
+	pool.lifecycleQueue = append(pool.lifecycleQueue, TxLifecycleEvent{Tx: tx, Reason: reason, Replacement: replacement})
+	pool.lifecycleLock.Unlock()
+
+	select {
+	case pool.lifecycleWake <- struct{}{}:
+	default:
+	}
+}
+
+// lifecycleLoop delivers the queued lifecycle events to the subscribers one by
+// one, without holding up the pool while they are being consumed.
+func (pool *TxPool) lifecycleLoop() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case <-pool.lifecycleWake:
+			for {
+				pool.lifecycleLock.Lock()
+				if len(pool.lifecycleQueue) == 0 {
+					pool.lifecycleLock.Unlock()
+					break
+				}
+				ev := pool.lifecycleQueue[0]
+				pool.lifecycleQueue[0] = TxLifecycleEvent{}
+				pool.lifecycleQueue = pool.lifecycleQueue[1:]
+				pool.lifecycleLock.Unlock()
+
+				pool.lifecycleFeed.Send(ev)
+			}
+		case <-pool.lifecycleQuit:
+			return
+		}
+	}
+}
+
 // AddLocal enqueues a single transaction into the pool if it is valid, marking
 // the sender as a local one in the mean time, ensuring it goes around the local
 // pricing constraints.
@@ -860,9 +1044,9 @@ func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 	return pool.all[hash]
 }
 
-// removeTx removes a single transaction from the queue, moving all subsequent
-// transactions back to the future queue.
-func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
+// removeTx removes a single transaction from the queue for the given reason,
+// moving all subsequent transactions back to the future queue.
+func (pool *TxPool) removeTx(hash common.Hash, outofbound bool, reason TxLifecycleReason) {
 	// Fetch the transaction we wish to delete
 	tx, ok := pool.all[hash]
 	if !ok {
@@ -875,6 +1059,8 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 	if outofbound {
 		pool.priced.Removed()
 	}
+	pool.notifyLifecycle(tx, reason, nil)
+
 	// Remove the transaction from the pending lists and reset the account nonce
 	if pending := pool.pending[addr]; pending != nil {
 		if removed, invalids := pending.Remove(tx); removed {
@@ -886,6 +1072,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 			// Postpone any invalidated transactions
 			for _, tx := range invalids {
 				pool.enqueueTx(tx.Hash(), tx)
+				pool.notifyLifecycle(tx, TxDemoted, nil)
 			}
 			// Update the account nonce if needed
 			if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
@@ -926,6 +1113,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			log.Trace("Removed old queued transaction", "hash", hash)
 			delete(pool.all, hash)
 			pool.priced.Removed()
+			pool.notifyLifecycle(tx, TxStale, nil)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas)
 		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -935,6 +1123,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			queuedNofundsCounter.Inc(1)
+			pool.notifyLifecycle(tx, TxUnfunded, nil)
 		}
 		// Gather all executable transactions and promote them
 		for _, tx := range list.Ready(pool.pendingState.GetNonce(addr)) {
@@ -949,6 +1138,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				delete(pool.all, hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
+				pool.notifyLifecycle(tx, TxRateLimited, nil)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 		}
@@ -998,6 +1188,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							if nonce := tx.Nonce(); pool.pendingState.GetNonce(offenders[i]) > nonce {
 								pool.pendingState.SetNonce(offenders[i], nonce)
 							}
+							pool.notifyLifecycle(tx, TxRateLimited, nil)
 							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 						}
 						pending--
@@ -1020,6 +1211,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 						if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
 							pool.pendingState.SetNonce(addr, nonce)
 						}
+						pool.notifyLifecycle(tx, TxRateLimited, nil)
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
 					}
 					pending--
@@ -1053,7 +1245,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Drop all transactions if they are less than the overflow
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
-					pool.removeTx(tx.Hash(), true)
+					pool.removeTx(tx.Hash(), true, TxRateLimited)
 				}
 				drop -= size
 				queuedRateLimitCounter.Inc(int64(size))
@@ -1062,7 +1254,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Otherwise drop only last few transactions
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
-				pool.removeTx(txs[i].Hash(), true)
+				pool.removeTx(txs[i].Hash(), true, TxRateLimited)
 				drop--
 				queuedRateLimitCounter.Inc(1)
 			}
@@ -1084,6 +1276,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 			delete(pool.all, hash)
 			pool.priced.Removed()
+			pool.notifyLifecycle(tx, TxStale, nil)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -1093,11 +1286,13 @@ func (pool *TxPool) demoteUnexecutables() {
 			delete(pool.all, hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
+			pool.notifyLifecycle(tx, TxUnfunded, nil)
 		}
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
 			pool.enqueueTx(hash, tx)
+			pool.notifyLifecycle(tx, TxDemoted, nil)
 		}
 		// If there's a gap in front, warn (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
@@ -1105,6 +1300,7 @@ func (pool *TxPool) demoteUnexecutables() {
 				hash := tx.Hash()
 				log.Error("Demoting invalidated transaction", "hash", hash)
 				pool.enqueueTx(hash, tx)
+				pool.notifyLifecycle(tx, TxDemoted, nil)
 			}
 		}
 		// Delete the entire queue entry if it became empty.
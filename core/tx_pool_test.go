@@ -23,10 +23,12 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
 	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/types"
 	"github.com/doslink/dos/crypto"
@@ -42,6 +44,7 @@ var testTxPoolConfig TxPoolConfig
 func init() {
 	testTxPoolConfig = DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
+	testTxPoolConfig.Policy = ""
 }
 
 type testBlockChain struct {
@@ -341,7 +344,7 @@ func TestTransactionChainFork(t *testing.T) {
 	if _, err := pool.add(tx, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
-	pool.removeTx(tx.Hash(), true)
+	pool.removeTx(tx.Hash(), true, TxUnderpriced)
 
 	// reset the pool's internal state
 	resetState()
@@ -1561,6 +1564,157 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that transactions leaving the pool are announced along with the reason
+// of their removal.
+func TestTransactionLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the lifecycle events with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(dosdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	events := make(chan TxLifecycleEvent, 32)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	// Create a few test accounts and fund them
+	keys := make([]*ecdsa.PrivateKey, 2)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	// Replace a pending transaction, and drop the replacement and a queued one by repricing
+	original := pricedTransaction(0, 100000, big.NewInt(1), keys[0])
+	replacement := pricedTransaction(0, 100000, big.NewInt(2), keys[0])
+	queued := pricedTransaction(2, 100000, big.NewInt(3), keys[1])
+	banned := pricedTransaction(0, 100000, big.NewInt(10), keys[1])
+
+	pool.AddRemotes([]*types.Transaction{original, replacement, queued, banned})
+	pool.SetGasPrice(big.NewInt(4))
+
+	// Drop the last one by restricting the admission policy
+	pool.SetAdmissionPolicy(&TxAdmissionPolicy{Blacklist: []common.Address{crypto.PubkeyToAddress(keys[1].PublicKey)}})
+
+	want := map[common.Hash]TxLifecycleReason{
+		original.Hash():    TxReplaced,
+		replacement.Hash(): TxUnderpriced,
+		queued.Hash():      TxUnderpriced,
+		banned.Hash():      TxPolicy,
+	}
+	for len(want) > 0 {
+		select {
+		case ev := <-events:
+			reason, ok := want[ev.Tx.Hash()]
+			if !ok {
+				t.Fatalf("unexpected lifecycle event: %x %s", ev.Tx.Hash(), ev.Reason)
+			}
+			if ev.Reason != reason {
+				t.Errorf("transaction %x: reason mismatch: have %s, want %s", ev.Tx.Hash(), ev.Reason, reason)
+			}
+			if reason == TxReplaced && (ev.Replacement == nil || ev.Replacement.Hash() != replacement.Hash()) {
+				t.Errorf("transaction %x: replacement mismatch: have %v, want %x", ev.Tx.Hash(), ev.Replacement, replacement.Hash())
+			}
+			delete(want, ev.Tx.Hash())
+
+		case <-time.After(time.Second):
+			t.Fatalf("lifecycle events missing: %v", want)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected lifecycle event: %x %s", ev.Tx.Hash(), ev.Reason)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// Tests that transactions dropped for a stale nonce or demoted behind a nonce gap
+// are announced in order, and that the demoted ones are reported as queued.
+func TestTransactionLifecycleGap(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	events := make(chan TxLifecycleEvent, 32)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	// Add a batch of pending transactions, then include the first one and lose
+	// the second one, opening a gap in front of the third
+	txs := []*types.Transaction{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+	pool.AddRemotes(txs)
+
+	pool.mu.Lock()
+	pool.pending[account].txs.Remove(txs[1].Nonce())
+	delete(pool.all, txs[1].Hash())
+	pool.priced.Removed()
+	pool.currentState.SetNonce(account, 1)
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+
+	want := []struct {
+		hash   common.Hash
+		reason TxLifecycleReason
+	}{
+		{txs[0].Hash(), TxStale},
+		{txs[2].Hash(), TxDemoted},
+	}
+	for i, w := range want {
+		select {
+		case ev := <-events:
+			if ev.Tx.Hash() != w.hash || ev.Reason != w.reason {
+				t.Fatalf("event %d: mismatch: have %x %s, want %x %s", i, ev.Tx.Hash(), ev.Reason, w.hash, w.reason)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: lifecycle event missing", i)
+		}
+	}
+	statuses := pool.Status([]common.Hash{txs[0].Hash(), txs[1].Hash(), txs[2].Hash()})
+	expect := []TxStatus{TxStatusUnknown, TxStatusUnknown, TxStatusQueued}
+	for i := range statuses {
+		if statuses[i] != expect[i] {
+			t.Errorf("transaction %d: status mismatch: have %v, want %v", i, statuses[i], expect[i])
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the content of a single account can be retrieved from the pool,
+// split into pending and queued transactions sorted by nonce.
+func TestTransactionContentFrom(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	other, _ := crypto.GenerateKey()
+	for _, k := range []*ecdsa.PrivateKey{key, other} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(k.PublicKey), big.NewInt(1000000000))
+	}
+	pool.AddRemotes([]*types.Transaction{
+		transaction(5, 100000, key), transaction(1, 100000, key), transaction(0, 100000, key),
+		transaction(3, 100000, key), transaction(0, 100000, other),
+	})
+	pending, queued := pool.ContentFrom(crypto.PubkeyToAddress(key.PublicKey))
+	if len(pending) != 2 || pending[0].Nonce() != 0 || pending[1].Nonce() != 1 {
+		t.Errorf("pending content mismatch: have %v, want nonces [0 1]", pending)
+	}
+	if len(queued) != 2 || queued[0].Nonce() != 3 || queued[1].Nonce() != 5 {
+		t.Errorf("queued content mismatch: have %v, want nonces [3 5]", queued)
+	}
+	if pending, queued := pool.ContentFrom(common.Address{}); len(pending)+len(queued) != 0 {
+		t.Errorf("unknown account content mismatch: have %d pending, %d queued", len(pending), len(queued))
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1730,6 +1884,93 @@ func TestTransactionStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that the admission policy of the pool rejects and drops transactions
+// violating it, and that it's persisted across pool restarts.
+func TestTransactionAdmissionPolicy(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary folder for the persisted policy
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(dosdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Policy = filepath.Join(dir, "txpolicy.json")
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+
+	// Create a few accounts with different roles and fund them
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000000))
+	}
+	user, banned, deployer, pricey := keys[0], keys[1], keys[2], keys[3]
+	bannedAddr := crypto.PubkeyToAddress(banned.PublicKey)
+
+	// Add a transaction from the soon-to-be banned account before restricting
+	if err := pool.AddRemote(transaction(0, 100000, banned)); err != nil {
+		t.Fatalf("failed to add transaction before the policy: %v", err)
+	}
+	policy := &TxAdmissionPolicy{
+		Blacklist:        []common.Address{bannedAddr},
+		RestrictCreation: true,
+		Deployers:        []common.Address{crypto.PubkeyToAddress(deployer.PublicKey)},
+		MinGasPrices:     map[common.Address]*hexutil.Big{crypto.PubkeyToAddress(pricey.PublicKey): (*hexutil.Big)(big.NewInt(10))},
+	}
+	if err := pool.SetAdmissionPolicy(policy); err != nil {
+		t.Fatalf("failed to set admission policy: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending+queued != 0 {
+		t.Fatalf("violating transactions not dropped: pending %d, queued %d", pending, queued)
+	}
+	creation := func(key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	toBanned, _ := types.SignTx(types.NewTransaction(0, bannedAddr, big.NewInt(1), 100000, big.NewInt(1), nil), types.HomesteadSigner{}, user)
+
+	tests := []struct {
+		tx  *types.Transaction
+		err error
+	}{
+		{transaction(1, 100000, banned), ErrBlacklisted},
+		{toBanned, ErrBlacklisted},
+		{creation(user), ErrCreationNotAllowed},
+		{creation(deployer), nil},
+		{pricedTransaction(0, 100000, big.NewInt(9), pricey), ErrSenderUnderpriced},
+		{pricedTransaction(0, 100000, big.NewInt(10), pricey), nil},
+	}
+	for i, tt := range tests {
+		if err := pool.AddLocal(tt.tx); err != tt.err {
+			t.Errorf("test %d: admission error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Restart the pool and ensure the policy is loaded from disk
+	pool.Stop()
+	pool = NewTxPool(config, params.TestChainConfig, blockchain)
+
+	if err := pool.AddRemote(transaction(1, 100000, banned)); err != ErrBlacklisted {
+		t.Errorf("persisted blacklist not enforced: have %v, want %v", err, ErrBlacklisted)
+	}
+	// Lift the restrictions on disk and ensure reloading applies them
+	if err := saveTxAdmissionPolicy(config.Policy, new(TxAdmissionPolicy)); err != nil {
+		t.Fatalf("failed to overwrite admission policy: %v", err)
+	}
+	if err := pool.ReloadAdmissionPolicy(); err != nil {
+		t.Fatalf("failed to reload admission policy: %v", err)
+	}
+	if err := pool.AddRemote(transaction(1, 100000, banned)); err != nil {
+		t.Errorf("reloaded policy not enforced: %v", err)
+	}
+	pool.Stop()
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
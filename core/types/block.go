@@ -99,6 +99,13 @@ type headerMarshaling struct {
 // Hash returns the block hash of the header, which is simply the keccak256 hash of its
 // RLP encoding.
 func (h *Header) Hash() common.Hash {
+	// IBFT blocks are identified without their committed seals, so that every
+	// validator agrees on the hash regardless of the commit quorum it collected
+	if h.MixDigest == IBFTDigest {
+		if filtered := IBFTFilteredHeader(h, true); filtered != nil {
+			return rlpHash(filtered)
+		}
+	}
 	return rlpHash(h)
 }
 
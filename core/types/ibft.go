@@ -0,0 +1,94 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"errors"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/rlp"
+)
+
+var (
+	// IBFTDigest is the fixed mix digest of blocks sealed by the IBFT consensus
+	// engine, marking their hashes to be calculated without the committed seals.
+	IBFTDigest = common.HexToHash("0x63746963616c2062797a616e74696e65206661756c7420746f6c6572616e6365")
+
+	// IBFTExtraVanity is the fixed number of extra-data prefix bytes reserved for
+	// validator vanity in IBFT sealed headers.
+	IBFTExtraVanity = 32
+
+	// ErrInvalidIBFTHeaderExtra is returned if the extra-data of a header is too
+	// short or its IBFT section cannot be decoded.
+	ErrInvalidIBFTHeaderExtra = errors.New("invalid ibft header extra-data")
+)
+
+// IBFTExtra is the consensus section following the vanity prefix in the extra-
+// data of IBFT sealed headers.
+type IBFTExtra struct {
+	Validators    []common.Address // Validator set, only filled on epoch checkpoints
+	Seal          []byte           // Signature of the block proposer
+	CommittedSeal [][]byte         // Commit signatures of the validator quorum
+}
+
+// ExtractIBFTExtra decodes the IBFT section from the extra-data of a header.
+func ExtractIBFTExtra(h *Header) (*IBFTExtra, error) {
+	if len(h.Extra) < IBFTExtraVanity {
+		return nil, ErrInvalidIBFTHeaderExtra
+	}
+	extra := new(IBFTExtra)
+	if err := rlp.DecodeBytes(h.Extra[IBFTExtraVanity:], extra); err != nil {
+		return nil, ErrInvalidIBFTHeaderExtra
+	}
+	return extra, nil
+}
+
+// EncodeIBFTExtra assembles the extra-data of an IBFT sealed header from the
+// given vanity, padded or truncated to IBFTExtraVanity bytes, and IBFT section.
+func EncodeIBFTExtra(vanity []byte, extra *IBFTExtra) ([]byte, error) {
+	blob, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, IBFTExtraVanity, IBFTExtraVanity+len(blob))
+	copy(data, vanity)
+
+	return append(data, blob...), nil
+}
+
+// IBFTFilteredHeader returns a copy of the header with the committed seals, and
+// unless keepSeal is set also the proposer seal, stripped from the extra-data.
+// Nil is returned if the extra-data has no valid IBFT section.
+func IBFTFilteredHeader(h *Header, keepSeal bool) *Header {
+	extra, err := ExtractIBFTExtra(h)
+	if err != nil {
+		return nil
+	}
+	if !keepSeal {
+		extra.Seal = []byte{}
+	}
+	extra.CommittedSeal = [][]byte{}
+
+	data, err := EncodeIBFTExtra(h.Extra[:IBFTExtraVanity], extra)
+	if err != nil {
+		return nil
+	}
+	cpy := CopyHeader(h)
+	cpy.Extra = data
+
+	return cpy
+}
@@ -336,12 +336,68 @@ var bn256PairingTests = []precompiledTest{
 	},
 }
 
+// EIP-152 test vectors
+var blake2FMalformedInputTests = []precompiledTest{
+	{
+		input: "",
+		name:  "vector 0: empty input",
+	},
+	{
+		input: "00000c" + blake2FInput + "01",
+		name:  "vector 1: less than 213 bytes input",
+	},
+	{
+		input: "000000000c" + blake2FInput + "01",
+		name:  "vector 2: more than 213 bytes input",
+	},
+	{
+		input: "0000000c" + blake2FInput + "02",
+		name:  "vector 3: malformed final block indicator flag",
+	},
+}
+
+// blake2FInput is the state, message and offset counter of the EIP-152 test
+// vectors, hashing "abc" in a single block.
+const blake2FInput = "48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b616263000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000003000000000000000000000000000000"
+
+// blake2FTests are the test and benchmark data for the BLAKE2b compression
+// precompiled contract.
+var blake2FTests = []precompiledTest{
+	{
+		input:    "00000000" + blake2FInput + "01",
+		expected: "08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b",
+		gas:      0,
+		name:     "vector 4",
+	},
+	{
+		input:    "0000000c" + blake2FInput + "01",
+		expected: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		gas:      12,
+		name:     "vector 5",
+	},
+	{
+		input:    "0000000c" + blake2FInput + "00",
+		expected: "75ab69d3190a562c51aef8d88f1c2775876944407270c42c9844252c26d2875298743e7f6d5ea2f2d3e8d226039cd31b4e426ac4f2d3d666a610c2116fde4735",
+		gas:      12,
+		name:     "vector 6",
+	},
+	{
+		input:    "00000001" + blake2FInput + "01",
+		expected: "b63a380cb2897d521994a85234ee2c181b5f844d2c624c002677e9703449d2fba551b3a8333bcdf5f2f7e08993d53923de3d64fcc68c034e717b9293fed7a421",
+		gas:      1,
+		name:     "vector 7",
+	},
+}
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := PrecompiledContractsIstanbul[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
 		nil, new(big.Int), p.RequiredGas(in))
 	t.Run(fmt.Sprintf("%s-Gas=%d", test.name, contract.Gas), func(t *testing.T) {
+		if test.gas != 0 && contract.Gas != test.gas {
+			t.Errorf("Expected gas %d, got %d", test.gas, contract.Gas)
+		}
 		if res, err := RunPrecompiledContract(p, in, contract); err != nil {
 			t.Error(err)
 		} else if common.Bytes2Hex(res) != test.expected {
@@ -350,11 +406,23 @@ func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
 	})
 }
 
+func testPrecompiledFailure(addr string, test precompiledTest, t *testing.T) {
+	p := PrecompiledContractsIstanbul[common.HexToAddress(addr)]
+	in := common.Hex2Bytes(test.input)
+	contract := NewContract(AccountRef(common.HexToAddress("31337")),
+		nil, new(big.Int), p.RequiredGas(in))
+	t.Run(test.name, func(t *testing.T) {
+		if _, err := RunPrecompiledContract(p, in, contract); err == nil {
+			t.Errorf("Expected error for %s", test.name)
+		}
+	})
+}
+
 func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
 	if test.noBenchmark {
 		return
 	}
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := PrecompiledContractsIstanbul[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
 	reqGas := p.RequiredGas(in)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
@@ -481,3 +549,48 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+// Tests the sample inputs from the BLAKE2b compression EIP 152.
+func TestPrecompiledBlake2F(t *testing.T) {
+	for _, test := range blake2FTests {
+		testPrecompiled("09", test, t)
+	}
+}
+
+// Benchmarks the sample inputs from the BLAKE2b compression EIP 152.
+func BenchmarkPrecompiledBlake2F(bench *testing.B) {
+	for _, test := range blake2FTests {
+		benchmarkPrecompiled("09", test, bench)
+	}
+}
+
+// Tests that malformed inputs are rejected by the BLAKE2b compression EIP 152.
+func TestPrecompileBlake2FMalformedInput(t *testing.T) {
+	for _, test := range blake2FMalformedInputTests {
+		testPrecompiledFailure("09", test, t)
+	}
+}
+
+// Tests that the elliptic curve precompiles are repriced in Istanbul (EIP 1108).
+func TestPrecompiledBn256Istanbul(t *testing.T) {
+	pairing := common.Hex2Bytes(bn256PairingTests[0].input)
+	tests := []struct {
+		addr      byte
+		input     []byte
+		byzantium uint64
+		istanbul  uint64
+	}{
+		{6, nil, 500, 150},
+		{7, nil, 40000, 6000},
+		{8, pairing, 100000 + uint64(len(pairing)/192)*80000, 45000 + uint64(len(pairing)/192)*34000},
+	}
+	for _, tt := range tests {
+		addr := common.BytesToAddress([]byte{tt.addr})
+		if gas := PrecompiledContractsByzantium[addr].RequiredGas(tt.input); gas != tt.byzantium {
+			t.Errorf("precompile %d: byzantium gas mismatch: have %d, want %d", tt.addr, gas, tt.byzantium)
+		}
+		if gas := PrecompiledContractsIstanbul[addr].RequiredGas(tt.input); gas != tt.istanbul {
+			t.Errorf("precompile %d: istanbul gas mismatch: have %d, want %d", tt.addr, gas, tt.istanbul)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/params"
 )
 
 func TestDefaults(t *testing.T) {
@@ -148,3 +149,54 @@ func BenchmarkCall(b *testing.B) {
 		}
 	}
 }
+
+func TestIstanbulOpcodes(t *testing.T) {
+	code := []byte{
+		byte(vm.CHAINID),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.SELFBALANCE),
+		byte(vm.PUSH1), 32,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 64,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	}
+	config := &params.ChainConfig{
+		ChainId:             big.NewInt(605),
+		HomesteadBlock:      new(big.Int),
+		EIP150Block:         new(big.Int),
+		EIP155Block:         new(big.Int),
+		EIP158Block:         new(big.Int),
+		ByzantiumBlock:      new(big.Int),
+		ConstantinopleBlock: new(big.Int),
+		IstanbulBlock:       big.NewInt(1),
+	}
+	newState := func() *state.StateDB {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(dosdb.NewMemDatabase()))
+		statedb.AddBalance(common.BytesToAddress([]byte("contract")), big.NewInt(1000))
+		return statedb
+	}
+	// The opcodes must be rejected before the fork
+	if _, _, err := Execute(code, nil, &Config{ChainConfig: config, BlockNumber: big.NewInt(0), State: newState()}); err == nil {
+		t.Fatal("expected pre-Istanbul execution to fail")
+	}
+	ret, _, err := Execute(code, nil, &Config{ChainConfig: config, BlockNumber: big.NewInt(1), State: newState()})
+	if err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if id := new(big.Int).SetBytes(ret[:32]); id.Cmp(config.ChainId) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", id, config.ChainId)
+	}
+	if balance := new(big.Int).SetBytes(ret[32:]); balance.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("self balance mismatch: have %v, want %v", balance, 1000)
+	}
+	// A chain without an id must report zero instead of crashing
+	config.ChainId = nil
+	if ret, _, err = Execute(code, nil, &Config{ChainConfig: config, BlockNumber: big.NewInt(1), State: newState()}); err != nil {
+		t.Fatal("didn't expect error", err)
+	}
+	if id := new(big.Int).SetBytes(ret[:32]); id.Sign() != 0 {
+		t.Errorf("missing chain id mismatch: have %v, want 0", id)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/doslink/dos/common"
 	"github.com/doslink/dos/common/hexutil"
@@ -75,7 +76,7 @@ type PublicMinerAPI struct {
 
 // NewPublicMinerAPI create a new PublicMinerAPI instance.
 func NewPublicMinerAPI(e *Doslink) *PublicMinerAPI {
-	agent := miner.NewRemoteAgent(e.BlockChain(), e.Engine())
+	agent := miner.NewRemoteAgent(e.BlockChain(), e.Engine(), e.config.MinerNotify)
 	e.Miner().Register(agent)
 
 	return &PublicMinerAPI{e, agent}
@@ -189,6 +190,23 @@ func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	return true
 }
 
+// SetRecommitInterval updates the interval, in milliseconds, after which the
+// block being mined is rebuilt with newly arrived transactions. Zero disables it.
+func (api *PrivateMinerAPI) SetRecommitInterval(interval int) error {
+	if interval < 0 {
+		return fmt.Errorf("negative recommit interval %d", interval)
+	}
+	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
+	return nil
+}
+
+// SetNoEmpty sets whether empty blocks are withheld from sealing while the
+// transaction pool has pending transactions.
+func (api *PrivateMinerAPI) SetNoEmpty(noempty bool) bool {
+	api.e.Miner().SetNoEmpty(noempty)
+	return true
+}
+
 // SetDoserbase sets the doserbase of the miner
 func (api *PrivateMinerAPI) SetDoserbase(doserbase common.Address) bool {
 	api.e.SetDoserbase(doserbase)
@@ -293,6 +311,29 @@ func (api *PrivateAdminAPI) ImportChain(file string) (bool, error) {
 	return true, nil
 }
 
+// TxPoolPolicy returns the admission policy enforced by the transaction pool.
+func (api *PrivateAdminAPI) TxPoolPolicy() *core.TxAdmissionPolicy {
+	return api.dos.TxPool().AdmissionPolicy()
+}
+
+// SetTxPoolPolicy replaces the admission policy enforced by the transaction pool,
+// persisting it into the data directory and dropping all violating transactions.
+func (api *PrivateAdminAPI) SetTxPoolPolicy(policy core.TxAdmissionPolicy) (bool, error) {
+	if err := api.dos.TxPool().SetAdmissionPolicy(&policy); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ReloadTxPoolPolicy reloads the admission policy of the transaction pool from
+// the data directory, dropping all violating transactions.
+func (api *PrivateAdminAPI) ReloadTxPoolPolicy() (bool, error) {
+	if err := api.dos.TxPool().ReloadAdmissionPolicy(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // PublicDebugAPI is the collection of Doslink full node APIs exposed
 // over the public debugging endpoint.
 type PublicDebugAPI struct {
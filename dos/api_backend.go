@@ -188,10 +188,22 @@ func (b *DosAPIBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.dos.TxPool().Content()
 }
 
+func (b *DosAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.dos.TxPool().ContentFrom(addr)
+}
+
+func (b *DosAPIBackend) TxPoolPriceBump() uint64 {
+	return b.dos.TxPool().PriceBump()
+}
+
 func (b *DosAPIBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.dos.TxPool().SubscribeTxPreEvent(ch)
 }
 
+func (b *DosAPIBackend) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return b.dos.TxPool().SubscribeTxLifecycleEvent(ch)
+}
+
 func (b *DosAPIBackend) Downloader() *downloader.Downloader {
 	return b.dos.Downloader()
 }
@@ -204,6 +216,10 @@ func (b *DosAPIBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *DosAPIBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, percentiles []float64) (*big.Int, []float64, [][]*big.Int, error) {
+	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, percentiles)
+}
+
 func (b *DosAPIBackend) ChainDb() dosdb.Database {
 	return b.dos.ChainDb()
 }
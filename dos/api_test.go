@@ -17,13 +17,27 @@
 package dos
 
 import (
+	"context"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
 	"reflect"
 	"testing"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/doslink/dos/accounts"
+	"github.com/doslink/dos/accounts/keystore"
 	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core"
 	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/internal/dosapi"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rpc"
 )
 
 var dumper = spew.ConfigState{Indent: "    "}
@@ -88,3 +102,88 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+func TestTraceCallDefaults(t *testing.T) {
+	// Create a chain with a contract returning the caller of the message
+	var (
+		db       = dosdb.NewMemDatabase()
+		contract = common.HexToAddress("0x0000000000000000000000000000000000000ca1")
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				contract: {Balance: new(big.Int), Code: common.FromHex("0x3360005260206000f3")},
+			},
+		}
+	)
+	gspec.MustCommit(db)
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, dosash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	// Create an unfunded local account to default the sender to
+	dir, err := ioutil.TempDir("", "dos-tracecall-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, manager := range []*accounts.Manager{nil, accounts.NewManager(ks)} {
+		api := NewPrivateDebugAPI(gspec.Config, &Doslink{chainConfig: gspec.Config, blockchain: blockchain, accountManager: manager})
+
+		// Trace a call without sender, gas or gas price and check it ran from the default
+		res, err := api.TraceCall(context.Background(), dosapi.CallArgs{To: &contract}, rpc.LatestBlockNumber, nil)
+		if err != nil {
+			t.Fatalf("test %d: failed to trace call: %v", i, err)
+		}
+		result := res.(*dosapi.ExecutionResult)
+		if result.Failed {
+			t.Fatalf("test %d: traced call failed", i)
+		}
+		want := common.Address{}
+		if manager != nil {
+			want = account.Address
+		}
+		if have := common.HexToAddress(result.ReturnValue); have != want {
+			t.Errorf("test %d: sender mismatch: have %x, want %x", i, have, want)
+		}
+	}
+}
+
+// Tests that traced calls are capped at the block gas limit and aborted by the
+// trace timeout, even with the default structured logger.
+func TestTraceCallLimits(t *testing.T) {
+	// Create a chain with a contract returning the gas left and an endless loop
+	var (
+		db      = dosdb.NewMemDatabase()
+		gasleft = common.HexToAddress("0x0000000000000000000000000000000000000ca1")
+		loop    = common.HexToAddress("0x0000000000000000000000000000000000000ca2")
+		gspec   = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				gasleft: {Balance: new(big.Int), Code: common.FromHex("0x5a60005260206000f3")},
+				loop:    {Balance: new(big.Int), Code: common.FromHex("0x5b600056")},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, dosash.NewFaker(), vm.Config{})
+	defer blockchain.Stop()
+
+	api := NewPrivateDebugAPI(gspec.Config, &Doslink{chainConfig: gspec.Config, blockchain: blockchain})
+
+	res, err := api.TraceCall(context.Background(), dosapi.CallArgs{To: &gasleft, Gas: hexutil.Uint64(math.MaxUint64)}, rpc.LatestBlockNumber, nil)
+	if err != nil {
+		t.Fatalf("failed to trace call: %v", err)
+	}
+	if left := new(big.Int).SetBytes(common.FromHex(res.(*dosapi.ExecutionResult).ReturnValue)); left.Uint64() >= genesis.GasLimit() {
+		t.Errorf("gas allowance not capped: have %d left, limit %d", left, genesis.GasLimit())
+	}
+	timeout := "10ms"
+	if _, err := api.TraceCall(context.Background(), dosapi.CallArgs{To: &loop}, rpc.LatestBlockNumber, &TraceConfig{Timeout: &timeout}); err == nil {
+		t.Errorf("endless call traced without timing out")
+	}
+}
@@ -0,0 +1,401 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package dos
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/dos/tracers"
+	"github.com/doslink/dos/rpc"
+)
+
+var (
+	// maxTraceFilterBlocks is the maximum number of blocks a single trace_filter
+	// request may span, as every block without an index is reexecuted.
+	maxTraceFilterBlocks uint64 = 1024
+
+	// maxTraceFilterResults is the maximum number of traces a single trace_filter
+	// request may return, larger result sets needing to be paged via after and
+	// count.
+	maxTraceFilterResults uint64 = 1000
+)
+
+// LocalizedTrace is a flattened call of a transaction along with its position
+// in the chain.
+type LocalizedTrace struct {
+	*tracers.FlatCall
+	BlockHash           common.Hash `json:"blockHash"`
+	BlockNumber         uint64      `json:"blockNumber"`
+	TransactionHash     common.Hash `json:"transactionHash"`
+	TransactionPosition uint64      `json:"transactionPosition"`
+}
+
+// TraceFilterArgs are the criteria of a trace_filter request. Traces match if
+// their sender is among the from addresses and their recipient among the to
+// addresses, an empty list matching anything.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// traceParties returns the accounts a flattened call moves execution or funds
+// between: the caller and callee of calls, the creator and created contract of
+// creations and the destructed contract and beneficiary of self destructs.
+func traceParties(trace *tracers.FlatCall) (from *common.Address, to *common.Address) {
+	switch trace.Type {
+	case "suicide":
+		return trace.Action.Address, trace.Action.RefundAddress
+	case "create":
+		if trace.Result != nil {
+			return trace.Action.From, trace.Result.Address
+		}
+		return trace.Action.From, nil
+	default:
+		return trace.Action.From, trace.Action.To
+	}
+}
+
+// matches returns whether a trace satisfies the address criteria of the filter.
+func (args *TraceFilterArgs) matches(trace *tracers.FlatCall) bool {
+	from, to := traceParties(trace)
+	return containsAddress(args.FromAddress, from) && containsAddress(args.ToAddress, to)
+}
+
+// containsAddress returns whether the address is in the list, an empty list
+// containing everything.
+func containsAddress(list []common.Address, addr *common.Address) bool {
+	if len(list) == 0 {
+		return true
+	}
+	if addr == nil {
+		return false
+	}
+	for _, item := range list {
+		if item == *addr {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicTraceAPI provides Parity style flattened call traces of the canonical
+// chain, reexecuting the blocks on top of their parent state.
+type PublicTraceAPI struct {
+	dos   *Doslink
+	debug *PrivateDebugAPI
+}
+
+// NewPublicTraceAPI creates a new call trace API for the given node.
+func NewPublicTraceAPI(dos *Doslink) *PublicTraceAPI {
+	return &PublicTraceAPI{
+		dos:   dos,
+		debug: NewPrivateDebugAPI(dos.chainConfig, dos),
+	}
+}
+
+// blockByNumber retrieves a block by number, resolving the latest and pending
+// meta block numbers.
+func (api *PublicTraceAPI) blockByNumber(number rpc.BlockNumber) (*types.Block, error) {
+	var block *types.Block
+
+	switch number {
+	case rpc.PendingBlockNumber:
+		block = api.dos.miner.PendingBlock()
+	case rpc.LatestBlockNumber:
+		block = api.dos.blockchain.CurrentBlock()
+	default:
+		block = api.dos.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return block, nil
+}
+
+// Block returns the flattened calls of all the transactions in a block.
+func (api *PublicTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*LocalizedTrace, error) {
+	block, err := api.blockByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	return api.debug.traceBlockCalls(ctx, block)
+}
+
+// Transaction returns the flattened calls of a single transaction.
+func (api *PublicTraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]*LocalizedTrace, error) {
+	tx, blockHash, blockNumber, index := rawdb.ReadTransaction(api.dos.ChainDb(), hash)
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %x not found", hash)
+	}
+	msg, vmctx, statedb, err := api.debug.computeTxEnv(blockHash, int(index), defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	calls, err := api.debug.traceCalls(msg, vmctx, statedb)
+	if err != nil {
+		return nil, err
+	}
+	traces := make([]*LocalizedTrace, len(calls))
+	for i, call := range calls {
+		traces[i] = &LocalizedTrace{
+			FlatCall:            call,
+			BlockHash:           blockHash,
+			BlockNumber:         blockNumber,
+			TransactionHash:     hash,
+			TransactionPosition: index,
+		}
+	}
+	return traces, nil
+}
+
+// Filter returns the flattened calls within a block range matching the given
+// address criteria. If the trace indexer is running, only the blocks indexed
+// as containing the requested addresses are reexecuted within the indexed
+// sections. Both the block range and the number of returned traces are capped.
+func (api *PublicTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*LocalizedTrace, error) {
+	// Resolve the block range to filter
+	head := api.dos.blockchain.CurrentBlock().NumberU64()
+
+	resolve := func(number *rpc.BlockNumber) uint64 {
+		if number == nil || *number < 0 {
+			return head
+		}
+		return uint64(*number)
+	}
+	begin, end := resolve(args.FromBlock), resolve(args.ToBlock)
+	if begin > end {
+		return nil, fmt.Errorf("invalid block range %d-%d", begin, end)
+	}
+	if end > head {
+		end = head
+	}
+	if end >= begin && end-begin >= maxTraceFilterBlocks {
+		return nil, fmt.Errorf("block range too large, max %d blocks", maxTraceFilterBlocks)
+	}
+	if args.Count != nil && *args.Count > maxTraceFilterResults {
+		return nil, fmt.Errorf("trace count too large, max %d traces", maxTraceFilterResults)
+	}
+	// Gather the accounts to look up in the index, if it can be used
+	var (
+		addresses []common.Address
+		indexed   uint64
+	)
+	if api.dos.traceIndexer != nil {
+		// Every match has one of the requested senders as a party, or one of the
+		// recipients if no senders were requested, both of which are indexed
+		addresses = args.FromAddress
+		if len(addresses) == 0 {
+			addresses = args.ToAddress
+		}
+	}
+	if len(addresses) > 0 {
+		sections, _, _ := api.dos.traceIndexer.Sections()
+		indexed = sections * traceIndexSection
+	}
+	// Iterate over the range, skipping unrelated blocks in indexed sections. The
+	// state is regenerated once for the first traced block and then carried
+	// forward through the rest of the range.
+	var (
+		traces  []*LocalizedTrace
+		skipped uint64
+
+		statedb *state.StateDB
+		current *types.Block // Block whose post state statedb holds
+	)
+	// collect traces a block and gathers its matching calls, returning whether
+	// enough of them were found
+	collect := func(number uint64) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		block := api.dos.blockchain.GetBlockByNumber(number)
+		if block == nil {
+			return false, fmt.Errorf("block #%d not found", number)
+		}
+		if len(block.Transactions()) == 0 {
+			return false, nil
+		}
+		var err error
+		if statedb, err = api.stateAtParent(block, current, statedb); err != nil {
+			return false, err
+		}
+		calls, err := api.debug.traceBlockCallsAt(ctx, block, statedb)
+		if err != nil {
+			return false, err
+		}
+		// Apply the block rewards to move the state past the traced block
+		if _, err := api.dos.engine.Finalize(api.dos.blockchain, block.Header(), statedb, block.Transactions(), block.Uncles(), nil); err != nil {
+			return false, err
+		}
+		current = block
+
+		for _, call := range calls {
+			if !args.matches(call.FlatCall) {
+				continue
+			}
+			if args.After != nil && skipped < *args.After {
+				skipped++
+				continue
+			}
+			if args.Count == nil && uint64(len(traces)) >= maxTraceFilterResults {
+				return false, fmt.Errorf("too many matching traces, max %d, page with after and count", maxTraceFilterResults)
+			}
+			traces = append(traces, call)
+			if args.Count != nil && uint64(len(traces)) >= *args.Count {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	for number := begin; number <= end; {
+		if number >= indexed {
+			done, err := collect(number)
+			if err != nil || done {
+				return traces, err
+			}
+			number++
+			continue
+		}
+		// Within an indexed section, only trace the blocks the accounts took part in
+		section := number / traceIndexSection
+		sectionHead := rawdb.ReadCanonicalHash(api.dos.chainDb, (section+1)*traceIndexSection-1)
+
+		found := make(map[uint64]struct{})
+		for _, addr := range addresses {
+			for _, offset := range rawdb.ReadTraceIndex(api.dos.chainDb, section, sectionHead, addr) {
+				found[section*traceIndexSection+offset] = struct{}{}
+			}
+		}
+		numbers := make([]uint64, 0, len(found))
+		for n := range found {
+			if n >= number && n <= end {
+				numbers = append(numbers, n)
+			}
+		}
+		sort.Slice(numbers, func(i, j int) bool { return numbers[i] < numbers[j] })
+
+		for _, n := range numbers {
+			done, err := collect(n)
+			if err != nil || done {
+				return traces, err
+			}
+		}
+		number = (section + 1) * traceIndexSection
+	}
+	return traces, nil
+}
+
+// stateAtParent returns the state the given block executes on top of. The
+// first time around it is regenerated from the parent, afterwards the state
+// of the previously traced block is processed forward up to the parent.
+func (api *PublicTraceAPI) stateAtParent(block *types.Block, current *types.Block, statedb *state.StateDB) (*state.StateDB, error) {
+	if statedb == nil {
+		parent := api.dos.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+		if parent == nil {
+			return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+		}
+		return api.debug.computeStateDB(parent, defaultTraceReexec)
+	}
+	for number := current.NumberU64() + 1; number < block.NumberU64(); number++ {
+		next := api.dos.blockchain.GetBlockByNumber(number)
+		if next == nil {
+			return nil, fmt.Errorf("block #%d not found", number)
+		}
+		if _, _, _, err := api.dos.blockchain.Processor().Process(next, statedb, vm.Config{}); err != nil {
+			return nil, err
+		}
+	}
+	return statedb, nil
+}
+
+// traceBlockCalls reexecutes all the transactions of a block on top of its
+// parent state, collecting their flattened calls.
+func (api *PrivateDebugAPI) traceBlockCalls(ctx context.Context, block *types.Block) ([]*LocalizedTrace, error) {
+	if len(block.Transactions()) == 0 {
+		return nil, nil
+	}
+	parent := api.dos.blockchain.GetBlock(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil, fmt.Errorf("parent %x not found", block.ParentHash())
+	}
+	statedb, err := api.computeStateDB(parent, defaultTraceReexec)
+	if err != nil {
+		return nil, err
+	}
+	return api.traceBlockCallsAt(ctx, block, statedb)
+}
+
+// traceBlockCallsAt reexecutes all the transactions of a block on top of the
+// given parent state, collecting their flattened calls. The state is left with
+// the transactions applied, but without the block rewards.
+func (api *PrivateDebugAPI) traceBlockCallsAt(ctx context.Context, block *types.Block, statedb *state.StateDB) ([]*LocalizedTrace, error) {
+	var (
+		signer = types.MakeSigner(api.config, block.Number())
+		traces []*LocalizedTrace
+	)
+	for i, tx := range block.Transactions() {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+		msg, _ := tx.AsMessage(signer)
+		vmctx := core.NewEVMContext(msg, block.Header(), api.dos.blockchain, nil)
+
+		calls, err := api.traceCalls(msg, vmctx, statedb)
+		if err != nil {
+			return nil, fmt.Errorf("tx %x failed: %v", tx.Hash(), err)
+		}
+		// Ensure any modifications are committed to the state
+		statedb.Finalise(api.config.IsEIP158(block.Number()))
+
+		for _, call := range calls {
+			traces = append(traces, &LocalizedTrace{
+				FlatCall:            call,
+				BlockHash:           block.Hash(),
+				BlockNumber:         block.NumberU64(),
+				TransactionHash:     tx.Hash(),
+				TransactionPosition: uint64(i),
+			})
+		}
+	}
+	return traces, nil
+}
+
+// traceCalls executes the given message in the provided environment, returning
+// all the calls it made, flattened.
+func (api *PrivateDebugAPI) traceCalls(message core.Message, vmctx vm.Context, statedb *state.StateDB) ([]*tracers.FlatCall, error) {
+	tracer := tracers.NewFlatCallTracer()
+	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
+
+	if _, _, _, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas())); err != nil {
+		return nil, fmt.Errorf("tracing failed: %v", err)
+	}
+	return tracer.Traces()
+}
@@ -0,0 +1,183 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package dos
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rpc"
+)
+
+// Tests that filtering the traces of a range of blocks, carrying the state
+// forward from block to block, yields the same calls as tracing every block
+// on top of its own parent state.
+func TestTraceFilter(t *testing.T) {
+	// Create a contract calling its previous caller and remembering the current one
+	var (
+		key, _   = crypto.GenerateKey()
+		addr     = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.HexToAddress("0x0000000000000000000000000000000000000ca1")
+		db       = dosdb.NewMemDatabase()
+		gspec    = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc: core.GenesisAlloc{
+				testBank: {Balance: big.NewInt(params.Doser)},
+				addr:     {Balance: big.NewInt(params.Doser)},
+				contract: {Balance: new(big.Int), Code: common.FromHex("0x600060006000600060006000545af15033600055")},
+			},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.NewEIP155Signer(gspec.Config.ChainId)
+		engine  = dosash.NewFaker()
+	)
+	// Call the contract in every other block, alternating between two senders
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 8, func(i int, block *core.BlockGen) {
+		if i%2 == 1 {
+			return
+		}
+		sender, senderKey := testBank, testBankKey
+		if i%4 == 2 {
+			sender, senderKey = addr, key
+		}
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(sender), contract, new(big.Int), 100000, new(big.Int), nil), signer, senderKey)
+		block.AddTx(tx)
+	})
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewPublicTraceAPI(&Doslink{chainConfig: gspec.Config, chainDb: db, blockchain: blockchain, engine: engine})
+
+	// Trace the blocks one by one as the reference
+	var want []*LocalizedTrace
+	for number := 1; number <= len(blocks); number++ {
+		traces, err := api.Block(context.Background(), rpc.BlockNumber(number))
+		if err != nil {
+			t.Fatalf("failed to trace block #%d: %v", number, err)
+		}
+		want = append(want, traces...)
+	}
+	if len(want) != 8 {
+		t.Fatalf("reference trace count mismatch: have %d, want %d", len(want), 8)
+	}
+	// Filter the whole range and a subrange starting after a contract call
+	for _, begin := range []int{1, 2} {
+		from := rpc.BlockNumber(begin)
+		traces, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &from})
+		if err != nil {
+			t.Fatalf("begin %d: failed to filter traces: %v", begin, err)
+		}
+		if begin > 1 {
+			want = want[2:]
+		}
+		if !reflect.DeepEqual(traces, want) {
+			t.Errorf("begin %d: traces mismatch:\nhave %v\nwant %v", begin, dumper.Sdump(traces), dumper.Sdump(want))
+		}
+	}
+	// Filter the calls sent by the second sender
+	first := rpc.BlockNumber(1)
+	traces, err := api.Filter(context.Background(), TraceFilterArgs{FromBlock: &first, FromAddress: []common.Address{addr}})
+	if err != nil {
+		t.Fatalf("failed to filter traces by sender: %v", err)
+	}
+	if len(traces) != 2 {
+		t.Fatalf("sender trace count mismatch: have %d, want %d", len(traces), 2)
+	}
+	for i, trace := range traces {
+		if *trace.Action.From != addr || trace.BlockNumber != uint64(4*i+3) {
+			t.Errorf("trace %d: unexpected call from %x in block #%d", i, *trace.Action.From, trace.BlockNumber)
+		}
+	}
+}
+
+// Tests that trace_filter requests spanning too many blocks or matching too
+// many traces are rejected instead of exhausting the node.
+func TestTraceFilterLimits(t *testing.T) {
+	db := dosdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc:  core.GenesisAlloc{testBank: {Balance: big.NewInt(params.Doser)}},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(gspec.Config.ChainId)
+	engine := dosash.NewFaker()
+
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, engine, db, 4, func(i int, block *core.BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(block.TxNonce(testBank), common.Address{0x01}, new(big.Int), 21000, new(big.Int), nil), signer, testBankKey)
+		block.AddTx(tx)
+	})
+	blockchain, _ := core.NewBlockChain(db, nil, gspec.Config, engine, vm.Config{})
+	defer blockchain.Stop()
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	api := NewPublicTraceAPI(&Doslink{chainConfig: gspec.Config, chainDb: db, blockchain: blockchain, engine: engine})
+
+	defer func(blocks, results uint64) {
+		maxTraceFilterBlocks, maxTraceFilterResults = blocks, results
+	}(maxTraceFilterBlocks, maxTraceFilterResults)
+	maxTraceFilterBlocks, maxTraceFilterResults = 3, 2
+
+	var (
+		first  = rpc.BlockNumber(1)
+		second = rpc.BlockNumber(2)
+		third  = rpc.BlockNumber(3)
+		two    = uint64(2)
+		three  = uint64(3)
+	)
+	tests := []struct {
+		args  TraceFilterArgs
+		count int
+		fail  bool
+	}{
+		{TraceFilterArgs{FromBlock: &first}, 0, true},                  // Range too large
+		{TraceFilterArgs{FromBlock: &first, ToBlock: &third}, 0, true}, // Too many traces
+		{TraceFilterArgs{FromBlock: &first, Count: &three}, 0, true},   // Count too large
+		{TraceFilterArgs{FromBlock: &first, ToBlock: &third, Count: &two}, 2, false},
+		{TraceFilterArgs{FromBlock: &second, ToBlock: &third}, 2, false},
+	}
+	for i, tt := range tests {
+		traces, err := api.Filter(context.Background(), tt.args)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected error, got %d traces", i, len(traces))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to filter traces: %v", i, err)
+			continue
+		}
+		if len(traces) != tt.count {
+			t.Errorf("test %d: trace count mismatch: have %d, want %d", i, len(traces), tt.count)
+		}
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	"github.com/doslink/dos/common"
 	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/common/math"
 	"github.com/doslink/dos/core"
 	"github.com/doslink/dos/core/rawdb"
 	"github.com/doslink/dos/core/state"
@@ -550,35 +551,84 @@ func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, hash common.Ha
 	return api.traceTx(ctx, msg, vmctx, statedb, config)
 }
 
+// TraceCall lets you trace a given dos_call. It collects the structured logs
+// created during the execution of EVM if the given transaction was added on
+// top of the provided block and returns them as a JSON object. The pending
+// block is traced on top of the miner's pending state.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args dosapi.CallArgs, number rpc.BlockNumber, config *TraceConfig) (interface{}, error) {
+	// Fetch the block and the state that we want to trace on top of
+	var (
+		block   *types.Block
+		statedb *state.StateDB
+		err     error
+	)
+	switch number {
+	case rpc.PendingBlockNumber:
+		block, statedb = api.dos.miner.Pending()
+	case rpc.LatestBlockNumber:
+		block = api.dos.blockchain.CurrentBlock()
+	default:
+		block = api.dos.blockchain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	if statedb == nil {
+		reexec := defaultTraceReexec
+		if config != nil && config.Reexec != nil {
+			reexec = *config.Reexec
+		}
+		if statedb, err = api.computeStateDB(block, reexec); err != nil {
+			return nil, err
+		}
+	}
+	// Set sender address or use a default if none specified
+	if args.From == (common.Address{}) && api.dos.AccountManager() != nil {
+		if wallets := api.dos.AccountManager().Wallets(); len(wallets) > 0 {
+			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+				args.From = accounts[0].Address
+			}
+		}
+	}
+	// Cap the gas allowance at the block's, as the call is run on the node itself
+	if args.Gas == 0 || uint64(args.Gas) > block.GasLimit() {
+		args.Gas = hexutil.Uint64(block.GasLimit())
+	}
+	// Execute the trace, funding the sender like a regular call would
+	msg := args.ToMessage()
+	statedb.SetBalance(msg.From(), math.MaxBig256)
+
+	vmctx := core.NewEVMContext(msg, block.Header(), api.dos.blockchain, nil)
+
+	return api.traceTx(ctx, msg, vmctx, statedb, config)
+}
+
 // traceTx configures a new tracer according to the provided configuration, and
 // executes the given message in the provided environment. The return value will
 // be tracer dependent.
 func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, vmctx vm.Context, statedb *state.StateDB, config *TraceConfig) (interface{}, error) {
+	// Define a meaningful timeout of a single transaction trace
+	var (
+		timeout = defaultTraceTimeout
+		err     error
+	)
+	if config != nil && config.Timeout != nil {
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
 	// Assemble the structured logger or the JavaScript tracer
 	var (
-		tracer vm.Tracer
-		err    error
+		tracer   vm.Tracer
+		txTracer tracers.TxTracer
 	)
 	switch {
 	case config != nil && config.Tracer != nil:
-		// Define a meaningful timeout of a single transaction trace
-		timeout := defaultTraceTimeout
-		if config.Timeout != nil {
-			if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
-				return nil, err
-			}
-		}
-		// Constuct the JavaScript tracer to execute with
-		if tracer, err = tracers.New(*config.Tracer); err != nil {
+		// Constuct the native or JavaScript tracer to execute with
+		if txTracer, err = tracers.NewTxTracer(*config.Tracer); err != nil {
 			return nil, err
 		}
-		// Handle timeouts and RPC cancellations
-		deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
-		go func() {
-			<-deadlineCtx.Done()
-			tracer.(*tracers.Tracer).Stop(errors.New("execution timeout"))
-		}()
-		defer cancel()
+		tracer = txTracer
 
 	case config == nil:
 		tracer = vm.NewStructLogger(nil)
@@ -589,6 +639,17 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	// Run the transaction with tracing enabled.
 	vmenv := vm.NewEVM(vmctx, statedb, api.config, vm.Config{Debug: true, Tracer: tracer})
 
+	// Handle timeouts and RPC cancellations
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if txTracer != nil {
+			txTracer.Stop(errors.New("execution timeout"))
+		}
+		vmenv.Cancel()
+	}()
+	defer cancel()
+
 	ret, gas, failed, err := core.ApplyMessage(vmenv, message, new(core.GasPool).AddGas(message.Gas()))
 	if err != nil {
 		return nil, fmt.Errorf("tracing failed: %v", err)
@@ -596,6 +657,9 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 	// Depending on the tracer type, format and return the output
 	switch tracer := tracer.(type) {
 	case *vm.StructLogger:
+		if deadlineCtx.Err() != nil {
+			return nil, fmt.Errorf("tracing failed: execution aborted (timeout = %v)", timeout)
+		}
 		return &dosapi.ExecutionResult{
 			Gas:         gas,
 			Failed:      failed,
@@ -603,7 +667,7 @@ func (api *PrivateDebugAPI) traceTx(ctx context.Context, message core.Message, v
 			StructLogs:  dosapi.FormatLogs(tracer.StructLogs()),
 		}, nil
 
-	case *tracers.Tracer:
+	case tracers.TxTracer:
 		return tracer.GetResult()
 
 	default:
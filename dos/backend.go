@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -31,6 +32,8 @@ import (
 	"github.com/doslink/dos/consensus"
 	"github.com/doslink/dos/consensus/clique"
 	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/consensus/ibft"
+	"github.com/doslink/dos/consensus/transition"
 	"github.com/doslink/dos/core"
 	"github.com/doslink/dos/core/bloombits"
 	"github.com/doslink/dos/core/rawdb"
@@ -81,10 +84,12 @@ type Doslink struct {
 
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
+	traceIndexer  *core.ChainIndexer             // Call trace indexer operating during block imports (optional)
 
 	APIBackend *DosAPIBackend
 
 	miner     *miner.Miner
+	stratum   *miner.StratumServer // Stratum server pushing work to external miners (optional)
 	gasPrice  *big.Int
 	doserbase common.Address
 
@@ -108,7 +113,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Doslink, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateDBWithFreezer(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +149,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Doslink, error) {
 	}
 	var (
 		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, Snapshot: config.Snapshot, SnapshotRebuild: config.SnapshotRebuild}
 	)
 	dos.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, dos.chainConfig, dos.engine, vmConfig)
 	if err != nil {
@@ -158,9 +163,20 @@ func New(ctx *node.ServiceContext, config *Config) (*Doslink, error) {
 	}
 	dos.bloomIndexer.Start(dos.blockchain)
 
+	if config.TraceIndex {
+		if !config.NoPruning {
+			log.Warn("Trace indexing requires an archive node, sections with pruned state will fail")
+		}
+		dos.traceIndexer = NewTraceIndexer(chainDb, NewPrivateDebugAPI(dos.chainConfig, dos))
+		dos.traceIndexer.Start(dos.blockchain)
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
+	if config.TxPool.Policy != "" {
+		config.TxPool.Policy = ctx.ResolvePath(config.TxPool.Policy)
+	}
 	dos.txPool = core.NewTxPool(config.TxPool, dos.chainConfig, dos.blockchain)
 
 	if dos.protocolManager, err = NewProtocolManager(dos.chainConfig, config.SyncMode, config.NetworkId, dos.eventMux, dos.txPool, dos.engine, dos.blockchain, chainDb); err != nil {
@@ -168,6 +184,22 @@ func New(ctx *node.ServiceContext, config *Config) (*Doslink, error) {
 	}
 	dos.miner = miner.New(dos, dos.chainConfig, dos.EventMux(), dos.engine)
 	dos.miner.SetExtra(makeExtraData(config.ExtraData))
+	dos.miner.SetRecommitInterval(config.MinerRecommit)
+	dos.miner.SetNoEmpty(config.MinerNoEmpty)
+
+	txPolicy, err := miner.NewTxPolicy(config.MinerTxOrder, config.MinerPrioritySenders, config.MinerSenderTxLimit)
+	if err != nil {
+		return nil, err
+	}
+	dos.miner.SetTxPolicy(txPolicy)
+
+	if config.MinerStratum != "" {
+		agent := miner.NewRemoteAgent(dos.blockchain, dos.engine, nil)
+		if dos.stratum, err = miner.NewStratumServer(agent, config.MinerStratum, config.MinerStratumDiff); err != nil {
+			return nil, err
+		}
+		dos.miner.Register(agent)
+	}
 
 	dos.APIBackend = &DosAPIBackend{dos, nil}
 	gpoParams := config.GPO
@@ -208,25 +240,63 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (dosdb.Data
 	return db, nil
 }
 
+// CreateDBWithFreezer creates the chain database, attaching an ancient store
+// that blocks deeper than the configured threshold are moved into.
+func CreateDBWithFreezer(ctx *node.ServiceContext, config *Config, name string) (dosdb.Database, error) {
+	db, err := CreateDB(ctx, config, name)
+	if err != nil {
+		return nil, err
+	}
+	// Ephemeral nodes keep everything in memory, there's nothing to freeze
+	if _, ok := db.(*dosdb.LDBDatabase); !ok {
+		return db, nil
+	}
+	freezer := AncientDir(ctx.ResolvePath, name, config.DatabaseFreezer)
+	frdb, err := rawdb.NewDatabaseWithFreezer(db, freezer, "dos/db/chaindata/", config.DatabaseFreezerThreshold)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// AncientDir resolves the directory of the ancient store of the named database,
+// defaulting to a folder inside the database and resolving relative paths with
+// the given resolver.
+func AncientDir(resolve func(string) string, name string, dir string) string {
+	switch {
+	case dir == "":
+		return filepath.Join(resolve(name), "ancient")
+	case !filepath.IsAbs(dir):
+		return resolve(dir)
+	}
+	return dir
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Doslink service
 func CreateConsensusEngine(ctx *node.ServiceContext, config *dosash.Config, chainConfig *params.ChainConfig, db dosdb.Database) consensus.Engine {
-	// If proof-of-authority is requested, set it up
-	if chainConfig.Clique != nil {
+	// If proof-of-authority is requested from genesis, set it up
+	if chainConfig.Clique != nil && chainConfig.CliqueBlock == nil {
 		return clique.New(chainConfig.Clique, db)
 	}
+	// If Byzantine fault tolerance is requested, set it up
+	if chainConfig.Ibft != nil {
+		return ibft.New(chainConfig.Ibft, db)
+	}
 	// Otherwise assume proof-of-work
+	var engine consensus.Engine
 	switch {
 	case config.PowMode == dosash.ModeFake:
 		log.Warn("Dosash used in fake mode")
-		return dosash.NewFaker()
+		engine = dosash.NewFaker()
 	case config.PowMode == dosash.ModeTest:
 		log.Warn("Dosash used in test mode")
-		return dosash.NewTester()
+		engine = dosash.NewTester()
 	case config.PowMode == dosash.ModeShared:
 		log.Warn("Dosash used in shared mode")
-		return dosash.NewShared()
+		engine = dosash.NewShared()
 	default:
-		engine := dosash.New(dosash.Config{
+		pow := dosash.New(dosash.Config{
 			CacheDir:       ctx.ResolvePath(config.CacheDir),
 			CachesInMem:    config.CachesInMem,
 			CachesOnDisk:   config.CachesOnDisk,
@@ -234,9 +304,14 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *dosash.Config, chai
 			DatasetsInMem:  config.DatasetsInMem,
 			DatasetsOnDisk: config.DatasetsOnDisk,
 		})
-		engine.SetThreads(-1) // Disable CPU mining
-		return engine
+		pow.SetThreads(-1) // Disable CPU mining
+		engine = pow
+	}
+	// If proof-of-authority takes over at a later block, switch over to it
+	if chainConfig.Clique != nil {
+		engine = transition.New(chainConfig, engine, clique.New(chainConfig.Clique, db))
 	}
+	return engine
 }
 
 // APIs returns the collection of RPC services the doslink package offers.
@@ -287,6 +362,11 @@ func (s *Doslink) APIs() []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(s.chainConfig, s),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPublicTraceAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "net",
 			Version:   "1.0",
@@ -346,6 +426,22 @@ func (s *Doslink) StartMining(local bool) error {
 		}
 		clique.Authorize(eb, wallet.SignHash)
 	}
+	if transition, ok := s.engine.(*transition.Transition); ok {
+		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+		if wallet == nil || err != nil {
+			log.Error("Doserbase account unavailable locally", "err", err)
+			return fmt.Errorf("signer missing: %v", err)
+		}
+		transition.Authorize(eb, wallet.SignHash)
+	}
+	if ibft, ok := s.engine.(*ibft.Ibft); ok {
+		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+		if wallet == nil || err != nil {
+			log.Error("Doserbase account unavailable locally", "err", err)
+			return fmt.Errorf("validator missing: %v", err)
+		}
+		ibft.Authorize(eb, wallet.SignHash)
+	}
 	if local {
 		// If local (CPU) mining is started, we can disable the transaction rejection
 		// mechanism introduced to speed sync times. CPU mining on mainnet is ludicrous
@@ -375,10 +471,14 @@ func (s *Doslink) Downloader() *downloader.Downloader { return s.protocolManager
 // Protocols implements node.Service, returning all the currently configured
 // network protocols to start.
 func (s *Doslink) Protocols() []p2p.Protocol {
+	protos := s.protocolManager.SubProtocols
+	if ibft, ok := s.engine.(*ibft.Ibft); ok {
+		protos = append(protos, ibft.Protocol())
+	}
 	if s.lesServer == nil {
-		return s.protocolManager.SubProtocols
+		return protos
 	}
-	return append(s.protocolManager.SubProtocols, s.lesServer.Protocols()...)
+	return append(protos, s.lesServer.Protocols()...)
 }
 
 // Start implements node.Service, starting all internal goroutines needed by the
@@ -400,9 +500,20 @@ func (s *Doslink) Start(srvr *p2p.Server) error {
 	}
 	// Start the networking layer and the light server if requested
 	s.protocolManager.Start(maxPeers)
+	if ibft, ok := s.engine.(*ibft.Ibft); ok {
+		ibft.Start(s.blockchain, func(block *types.Block) {
+			s.protocolManager.fetcher.Enqueue("ibft", block)
+		})
+	}
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	// Start pushing work to external miners if requested
+	if s.stratum != nil {
+		if err := s.stratum.Start(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -410,12 +521,21 @@ func (s *Doslink) Start(srvr *p2p.Server) error {
 // Doslink protocol.
 func (s *Doslink) Stop() error {
 	s.bloomIndexer.Close()
+	if s.traceIndexer != nil {
+		s.traceIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
+	if ibft, ok := s.engine.(*ibft.Ibft); ok {
+		ibft.Stop()
+	}
 	if s.lesServer != nil {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	if s.stratum != nil {
+		s.stratum.Stop()
+	}
 	s.miner.Stop()
 	s.eventMux.Stop()
 
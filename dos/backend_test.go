@@ -0,0 +1,46 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package dos
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// Tests that the directory of the ancient store defaults to a folder inside the
+// database and that relative paths are resolved against the data directory.
+func TestAncientDir(t *testing.T) {
+	datadir, _ := filepath.Abs("datadir")
+	resolve := func(path string) string {
+		return filepath.Join(datadir, path)
+	}
+	absolute, _ := filepath.Abs("ancient")
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"", filepath.Join(datadir, "chaindata", "ancient")},
+		{"frozen", filepath.Join(datadir, "frozen")},
+		{absolute, absolute},
+	}
+	for i, tt := range tests {
+		if dir := AncientDir(resolve, "chaindata", tt.dir); dir != tt.want {
+			t.Errorf("test %d: ancient dir mismatch: have %s, want %s", i, dir, tt.want)
+		}
+	}
+}
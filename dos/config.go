@@ -43,12 +43,14 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:     605,
-	LightPeers:    100,
-	DatabaseCache: 768,
-	TrieCache:     256,
-	TrieTimeout:   5 * time.Minute,
-	GasPrice:      big.NewInt(18 * params.Shannon),
+	NetworkId:                605,
+	LightPeers:               100,
+	DatabaseCache:            768,
+	DatabaseFreezerThreshold: params.ImmutabilityThreshold,
+	TrieCache:                256,
+	TrieTimeout:              5 * time.Minute,
+	GasPrice:                 big.NewInt(18 * params.Shannon),
+	MinerStratumDiff:         1,
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -94,11 +96,34 @@ type Config struct {
 	TrieCache          int
 	TrieTimeout        time.Duration
 
+	// Ancient chain data options
+	DatabaseFreezer          string `toml:",omitempty"` // Directory of the ancient store (default = inside the chaindata)
+	DatabaseFreezerThreshold uint64 // Number of recent blocks kept in the key-value database
+
+	// State snapshot options
+	Snapshot        bool `toml:",omitempty"` // Whether to maintain a flat snapshot of the state
+	SnapshotRebuild bool `toml:"-"`          // Whether to regenerate the snapshot on startup
+
+	// Call trace options
+	TraceIndex bool `toml:",omitempty"` // Whether to index the accounts of call traces (archive only)
+
 	// Mining-related options
-	Doserbase    common.Address `toml:",omitempty"`
-	MinerThreads int            `toml:",omitempty"`
-	ExtraData    []byte         `toml:",omitempty"`
-	GasPrice     *big.Int
+	Doserbase     common.Address `toml:",omitempty"`
+	MinerThreads  int            `toml:",omitempty"`
+	ExtraData     []byte         `toml:",omitempty"`
+	GasPrice      *big.Int
+	MinerRecommit time.Duration // Interval to rebuild the mined block with newly arrived transactions
+	MinerNoEmpty  bool          // Whether to withhold empty blocks from sealing while transactions are pending
+	MinerNotify   []string      `toml:",omitempty"` // HTTP URLs to notify of new work packages
+
+	// Transaction ordering options
+	MinerTxOrder         string           `toml:",omitempty"` // Ordering of the pending transactions in mined blocks (price, fifo, priority)
+	MinerPrioritySenders []common.Address `toml:",omitempty"` // Senders whose transactions are included first with the priority ordering
+	MinerSenderTxLimit   int              `toml:",omitempty"` // Maximum number of transactions from a single sender per block (0 = unlimited)
+
+	// Stratum mining options
+	MinerStratum     string  `toml:",omitempty"` // Stratum server listening endpoint (disabled if empty)
+	MinerStratumDiff float64 // Share difficulty announced to Stratum miners
 
 	// Dosash options
 	Dosash dosash.Config
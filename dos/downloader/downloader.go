@@ -90,6 +90,7 @@ var (
 	errCancelContentProcessing = errors.New("content processing canceled (requested)")
 	errNoSyncActive            = errors.New("no sync active")
 	errTooOld                  = errors.New("peer doesn't speak recent enough protocol version (need version >= 62)")
+	errCheckpointMismatch      = errors.New("header chain contradicts trusted checkpoint")
 )
 
 type Downloader struct {
@@ -112,6 +113,8 @@ type Downloader struct {
 	lightchain LightChain
 	blockchain BlockChain
 
+	checkpoint *params.TrustedCheckpoint // Trusted checkpoint the synced header chain must contain (nil = none)
+
 	// Callbacks
 	dropPeer peerDropFn // Drops a peer for misbehaving
 
@@ -198,7 +201,7 @@ type BlockChain interface {
 }
 
 // New creates a new downloader to fetch hashes and blocks from remote peers.
-func New(mode SyncMode, stateDb dosdb.Database, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn) *Downloader {
+func New(checkpoint *params.TrustedCheckpoint, mode SyncMode, stateDb dosdb.Database, mux *event.TypeMux, chain BlockChain, lightchain LightChain, dropPeer peerDropFn) *Downloader {
 	if lightchain == nil {
 		lightchain = chain
 	}
@@ -213,6 +216,7 @@ func New(mode SyncMode, stateDb dosdb.Database, mux *event.TypeMux, chain BlockC
 		rttConfidence:  uint64(1000000),
 		blockchain:     chain,
 		lightchain:     lightchain,
+		checkpoint:     checkpoint,
 		dropPeer:       dropPeer,
 		headerCh:       make(chan dataPack, 1),
 		bodyCh:         make(chan dataPack, 1),
@@ -321,7 +325,7 @@ func (d *Downloader) Synchronise(id string, head common.Hash, td *big.Int, mode
 
 	case errTimeout, errBadPeer, errStallingPeer,
 		errEmptyHeaderSet, errPeersUnavailable, errTooOld,
-		errInvalidAncestor, errInvalidChain:
+		errInvalidAncestor, errInvalidChain, errCheckpointMismatch:
 		log.Warn("Synchronisation failed, dropping peer", "peer", id, "err", err)
 		if d.dropPeer == nil {
 			// The dropPeer method is nil when `--copydb` is used for a local copy.
@@ -1255,6 +1259,10 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 				}
 				chunk := headers[:limit]
 
+				// Reject the chunk if it contradicts the trusted checkpoint
+				if err := d.verifyCheckpoint(chunk); err != nil {
+					return err
+				}
 				// In case of header only syncing, validate the chunk immediately
 				if d.mode == FastSync || d.mode == LightSync {
 					// Collect the yet unknown headers to mark them as uncertain
@@ -1322,6 +1330,25 @@ func (d *Downloader) processHeaders(origin uint64, pivot uint64, td *big.Int) er
 	}
 }
 
+// verifyCheckpoint ensures that a batch of headers, if it reaches the head of the
+// trusted checkpoint section, contains the checkpointed section head.
+func (d *Downloader) verifyCheckpoint(headers []*types.Header) error {
+	if d.checkpoint == nil {
+		return nil
+	}
+	number := d.checkpoint.HeadNumber()
+	for _, header := range headers {
+		if header.Number.Uint64() != number {
+			continue
+		}
+		if hash := header.Hash(); hash != d.checkpoint.SectionHead {
+			log.Warn("Header chain contradicts trusted checkpoint", "number", number, "hash", hash, "checkpoint", d.checkpoint.SectionHead)
+			return errCheckpointMismatch
+		}
+	}
+	return nil
+}
+
 // processFullSyncContent takes fetch results from the queue and imports them into the chain.
 func (d *Downloader) processFullSyncContent() error {
 	for {
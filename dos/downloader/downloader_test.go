@@ -96,7 +96,7 @@ func newTester() *downloadTester {
 	tester.stateDb = dosdb.NewMemDatabase()
 	tester.stateDb.Put(genesis.Root().Bytes(), []byte{0x00})
 
-	tester.downloader = New(FullSync, tester.stateDb, new(event.TypeMux), tester, nil, tester.dropPeer)
+	tester.downloader = New(nil, FullSync, tester.stateDb, new(event.TypeMux), tester, nil, tester.dropPeer)
 
 	return tester
 }
@@ -1753,3 +1753,31 @@ func testDeliverHeadersHang(t *testing.T, protocol int, mode SyncMode) {
 		tester.downloader.peers.peers["peer"].peer.(*floodingTestPeer).pend.Wait()
 	}
 }
+
+// Tests that header batches reaching the trusted checkpoint are only accepted if
+// they contain its section head.
+func TestCheckpointVerification(t *testing.T) {
+	headers := make([]*types.Header, 3)
+	for i := range headers {
+		headers[i] = &types.Header{Number: new(big.Int).SetUint64(params.CheckpointFrequency - 2 + uint64(i))}
+	}
+	checkpoint := &params.TrustedCheckpoint{SectionIndex: 0, SectionHead: headers[1].Hash()}
+
+	tests := []struct {
+		checkpoint *params.TrustedCheckpoint
+		headers    []*types.Header
+		err        error
+	}{
+		{nil, headers, nil},                                           // No checkpoint to enforce
+		{checkpoint, headers, nil},                                    // Batch containing the section head
+		{checkpoint, headers[:1], nil},                                // Batch before the section head
+		{checkpoint, headers[2:], nil},                                // Batch after the section head
+		{&params.TrustedCheckpoint{}, headers, errCheckpointMismatch}, // Batch contradicting the section head
+	}
+	for i, tt := range tests {
+		d := &Downloader{checkpoint: tt.checkpoint}
+		if err := d.verifyCheckpoint(tt.headers); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
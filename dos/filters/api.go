@@ -28,6 +28,7 @@ import (
 	doslink "github.com/doslink/dos"
 	"github.com/doslink/dos/common"
 	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/core"
 	"github.com/doslink/dos/core/types"
 	"github.com/doslink/dos/dosdb"
 	"github.com/doslink/dos/event"
@@ -164,6 +165,69 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 	return rpcSub, nil
 }
 
+// TxStatus is the notification sent when the transaction pool drops a
+// transaction, or moves it back from pending into the future queue.
+type TxStatus struct {
+	Hash        common.Hash            `json:"hash"`
+	From        common.Address         `json:"from"`
+	Nonce       hexutil.Uint64         `json:"nonce"`
+	Reason      core.TxLifecycleReason `json:"reason"`
+	Replacement *common.Hash           `json:"replacement,omitempty"`
+}
+
+// newTxStatus creates the notification of a transaction lifecycle event.
+func newTxStatus(ev core.TxLifecycleEvent) *TxStatus {
+	var signer types.Signer = types.HomesteadSigner{}
+	if ev.Tx.Protected() {
+		signer = types.NewEIP155Signer(ev.Tx.ChainId())
+	}
+	from, _ := types.Sender(signer, ev.Tx)
+
+	status := &TxStatus{
+		Hash:   ev.Tx.Hash(),
+		From:   from,
+		Nonce:  hexutil.Uint64(ev.Tx.Nonce()),
+		Reason: ev.Reason,
+	}
+	if ev.Replacement != nil {
+		hash := ev.Replacement.Hash()
+		status.Replacement = &hash
+	}
+	return status
+}
+
+// TransactionStatus creates a subscription that is triggered each time a
+// transaction is dropped from the transaction pool, or demoted from the pending
+// set back into the future queue, reporting the reason why.
+func (api *PublicFilterAPI) TransactionStatus(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		statuses := make(chan core.TxLifecycleEvent)
+		statusSub := api.events.SubscribeTxStatusEvents(statuses)
+
+		for {
+			select {
+			case ev := <-statuses:
+				notifier.Notify(rpcSub.ID, newTxStatus(ev))
+			case <-rpcSub.Err():
+				statusSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				statusSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
 // NewBlockFilter creates a filter that fetches blocks that are imported into the chain.
 // It is part of the filter package since polling goes with dos_getFilterChanges.
 //
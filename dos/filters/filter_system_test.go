@@ -39,13 +39,14 @@ import (
 )
 
 type testBackend struct {
-	mux        *event.TypeMux
-	db         dosdb.Database
-	sections   uint64
-	txFeed     *event.Feed
-	rmLogsFeed *event.Feed
-	logsFeed   *event.Feed
-	chainFeed  *event.Feed
+	mux           *event.TypeMux
+	db            dosdb.Database
+	sections      uint64
+	txFeed        *event.Feed
+	rmLogsFeed    *event.Feed
+	logsFeed      *event.Feed
+	chainFeed     *event.Feed
+	lifecycleFeed *event.Feed
 }
 
 func (b *testBackend) ChainDb() dosdb.Database {
@@ -100,6 +101,10 @@ func (b *testBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subsc
 	return b.txFeed.Subscribe(ch)
 }
 
+func (b *testBackend) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	return b.lifecycleFeed.Subscribe(ch)
+}
+
 func (b *testBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
 	return b.rmLogsFeed.Subscribe(ch)
 }
@@ -158,7 +163,7 @@ func TestBlockSubscription(t *testing.T) {
 		rmLogsFeed  = new(event.Feed)
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
-		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api         = NewPublicFilterAPI(backend, false)
 		genesis     = new(core.Genesis).MustCommit(db)
 		chain, _    = core.GenerateChain(params.TestChainConfig, genesis, dosash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
@@ -215,7 +220,7 @@ func TestPendingTxFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		transactions = []*types.Transaction{
@@ -268,6 +273,49 @@ func TestPendingTxFilter(t *testing.T) {
 	}
 }
 
+// TestTxStatusSubscription tests whether transaction status subscriptions
+// receive the lifecycle events posted by the transaction pool.
+func TestTxStatusSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux           = new(event.TypeMux)
+		db            = dosdb.NewMemDatabase()
+		lifecycleFeed = new(event.Feed)
+		backend       = &testBackend{mux, db, 0, new(event.Feed), new(event.Feed), new(event.Feed), new(event.Feed), lifecycleFeed}
+		api           = NewPublicFilterAPI(backend, false)
+
+		original    = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+		replacement = types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(2), nil)
+		dropped     = types.NewTransaction(1, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, big.NewInt(1), nil)
+
+		events = []core.TxLifecycleEvent{
+			{Tx: original, Reason: core.TxReplaced, Replacement: replacement},
+			{Tx: dropped, Reason: core.TxUnfunded},
+		}
+	)
+	statuses := make(chan core.TxLifecycleEvent)
+	sub := api.events.SubscribeTxStatusEvents(statuses)
+	defer sub.Unsubscribe()
+
+	for _, ev := range events {
+		lifecycleFeed.Send(ev)
+	}
+	for i, want := range events {
+		select {
+		case ev := <-statuses:
+			if ev.Tx.Hash() != want.Tx.Hash() || ev.Reason != want.Reason || ev.Replacement != want.Replacement {
+				t.Errorf("event %d: mismatch: have %x/%s, want %x/%s", i, ev.Tx.Hash(), ev.Reason, want.Tx.Hash(), want.Reason)
+			}
+			if status := newTxStatus(ev); status.Hash != want.Tx.Hash() || (want.Replacement != nil) != (status.Replacement != nil) {
+				t.Errorf("event %d: notification mismatch: %+v", i, status)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d: status not delivered", i)
+		}
+	}
+}
+
 // TestLogFilterCreation test whether a given filter criteria makes sense.
 // If not it must return an error.
 func TestLogFilterCreation(t *testing.T) {
@@ -278,7 +326,7 @@ func TestLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		testCases = []struct {
@@ -327,7 +375,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 	)
 
@@ -357,7 +405,7 @@ func TestLogFilter(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -476,7 +524,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		rmLogsFeed = new(event.Feed)
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
-		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed, new(event.Feed)}
 		api        = NewPublicFilterAPI(backend, false)
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
@@ -0,0 +1,151 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/rpc"
+)
+
+// maxFeeHistory is the maximum number of blocks a fee history can be requested for.
+const maxFeeHistory = 1024
+
+var (
+	errInvalidPercentile = errors.New("invalid gas price percentile")
+	errRequestBeyondHead = errors.New("request beyond head block")
+)
+
+// txGasAndPrice is a transaction of a block along with the gas it consumed.
+type txGasAndPrice struct {
+	gasUsed  uint64
+	gasPrice *big.Int
+}
+
+type txsByGasPrice []txGasAndPrice
+
+func (t txsByGasPrice) Len() int           { return len(t) }
+func (t txsByGasPrice) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
+func (t txsByGasPrice) Less(i, j int) bool { return t[i].gasPrice.Cmp(t[j].gasPrice) < 0 }
+
+// FeeHistory returns data relevant for fee estimation based on the given number
+// of blocks ending with lastBlock (the pending block is not available, so it's
+// treated as the latest one). It returns the number of the oldest block in the
+// range, the gas used / gas limit ratio of each block and the requested gas
+// price percentiles of each block, weighted by the gas used by transactions.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, percentiles []float64) (*big.Int, []float64, [][]*big.Int, error) {
+	if blocks < 1 {
+		return new(big.Int), nil, nil, nil
+	}
+	if blocks > maxFeeHistory {
+		blocks = maxFeeHistory
+	}
+	for i, p := range percentiles {
+		if p < 0 || p > 100 {
+			return nil, nil, nil, fmt.Errorf("%v: %f", errInvalidPercentile, p)
+		}
+		if i > 0 && p < percentiles[i-1] {
+			return nil, nil, nil, fmt.Errorf("%v: #%d:%f > #%d:%f", errInvalidPercentile, i-1, percentiles[i-1], i, p)
+		}
+	}
+	// Resolve the range of blocks to report
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return nil, nil, nil, err
+	}
+	last := head.Number.Uint64()
+	if lastBlock >= 0 {
+		if uint64(lastBlock) > last {
+			return nil, nil, nil, fmt.Errorf("%v: requested %d, head %d", errRequestBeyondHead, lastBlock, last)
+		}
+		last = uint64(lastBlock)
+	}
+	if uint64(blocks) > last+1 {
+		blocks = int(last + 1)
+	}
+	oldest := last + 1 - uint64(blocks)
+
+	var (
+		ratios = make([]float64, blocks)
+		prices = make([][]*big.Int, blocks)
+	)
+	for i := 0; i < blocks; i++ {
+		block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(oldest+uint64(i)))
+		if block == nil {
+			return nil, nil, nil, err
+		}
+		if block.GasLimit() > 0 {
+			ratios[i] = float64(block.GasUsed()) / float64(block.GasLimit())
+		}
+		if len(percentiles) > 0 {
+			if prices[i], err = gpo.blockPercentiles(ctx, block, percentiles); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+	if len(percentiles) == 0 {
+		prices = nil
+	}
+	return new(big.Int).SetUint64(oldest), ratios, prices, nil
+}
+
+// blockPercentiles calculates the requested gas price percentiles of a block,
+// weighting every transaction by the gas it consumed.
+func (gpo *Oracle) blockPercentiles(ctx context.Context, block *types.Block, percentiles []float64) ([]*big.Int, error) {
+	prices := make([]*big.Int, len(percentiles))
+	if len(block.Transactions()) == 0 {
+		for i := range prices {
+			prices[i] = new(big.Int)
+		}
+		return prices, nil
+	}
+	receipts, err := gpo.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(block.Transactions()))
+	}
+	txs := make([]txGasAndPrice, len(receipts))
+	for i, tx := range block.Transactions() {
+		gasUsed := receipts[i].CumulativeGasUsed
+		if i > 0 {
+			gasUsed -= receipts[i-1].CumulativeGasUsed
+		}
+		txs[i] = txGasAndPrice{gasUsed: gasUsed, gasPrice: tx.GasPrice()}
+	}
+	sort.Sort(txsByGasPrice(txs))
+
+	var (
+		index   = 0
+		sumUsed = txs[0].gasUsed
+	)
+	for i, p := range percentiles {
+		threshold := uint64(float64(block.GasUsed()) * p / 100)
+		for sumUsed < threshold && index < len(txs)-1 {
+			index++
+			sumUsed += txs[index].gasUsed
+		}
+		prices[i] = new(big.Int).Set(txs[index].gasPrice)
+	}
+	return prices, nil
+}
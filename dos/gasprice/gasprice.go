@@ -34,20 +34,23 @@ var maxPrice = big.NewInt(500 * params.Shannon)
 type Config struct {
 	Blocks     int
 	Percentile int
+	Pending    bool     // Whether to factor in the transactions of the pending pool
 	Default    *big.Int `toml:",omitempty"`
 }
 
 // Oracle recommends gas prices based on the content of recent
 // blocks. Suitable for both light and full clients.
 type Oracle struct {
-	backend   dosapi.Backend
-	lastHead  common.Hash
-	lastPrice *big.Int
-	cacheLock sync.RWMutex
-	fetchLock sync.Mutex
+	backend    dosapi.Backend
+	lastHead   common.Hash
+	lastPrice  *big.Int
+	lastPrices []*big.Int // Sorted block price samples gathered for the last head
+	cacheLock  sync.RWMutex
+	fetchLock  sync.Mutex
 
 	checkBlocks, maxEmpty, maxBlocks int
 	percentile                       int
+	pending                          bool
 }
 
 // NewOracle returns a new oracle.
@@ -56,34 +59,81 @@ func NewOracle(backend dosapi.Backend, params Config) *Oracle {
 	if blocks < 1 {
 		blocks = 1
 	}
-	percent := params.Percentile
-	if percent < 0 {
-		percent = 0
-	}
-	if percent > 100 {
-		percent = 100
-	}
 	return &Oracle{
 		backend:     backend,
 		lastPrice:   params.Default,
 		checkBlocks: blocks,
 		maxEmpty:    blocks / 2,
 		maxBlocks:   blocks * 5,
-		percentile:  percent,
+		percentile:  clampPercentile(params.Percentile),
+		pending:     params.Pending,
 	}
 }
 
-// SuggestPrice returns the recommended gas price.
+// clampPercentile caps a percentile into the [0, 100] range.
+func clampPercentile(percent int) int {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// SuggestPrice returns the recommended gas price, targeting the percentile the
+// oracle was configured with.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	return gpo.SuggestPercentile(ctx, gpo.percentile)
+}
+
+// SuggestPercentile returns the gas price at the given percentile of the lowest
+// prices accepted by recent blocks. If enabled, the price is raised to the same
+// percentile of the pending pool, should that be higher.
+func (gpo *Oracle) SuggestPercentile(ctx context.Context, percentile int) (*big.Int, error) {
+	percentile = clampPercentile(percentile)
+
+	prices, err := gpo.blockPrices(ctx)
+	if err != nil {
+		gpo.cacheLock.RLock()
+		defer gpo.cacheLock.RUnlock()
+		return gpo.lastPrice, err
+	}
+	gpo.cacheLock.RLock()
+	price := gpo.lastPrice
+	gpo.cacheLock.RUnlock()
+
+	if len(prices) > 0 {
+		price = prices[(len(prices)-1)*percentile/100]
+	}
+	if gpo.pending {
+		if pending := gpo.pendingPrice(percentile); pending != nil && (price == nil || pending.Cmp(price) > 0) {
+			price = pending
+		}
+	}
+	if price != nil && price.Cmp(maxPrice) > 0 {
+		price = new(big.Int).Set(maxPrice)
+	}
+	if percentile == gpo.percentile {
+		gpo.cacheLock.Lock()
+		gpo.lastPrice = price
+		gpo.cacheLock.Unlock()
+	}
+	return price, nil
+}
+
+// blockPrices returns the sorted lowest prices accepted by recent blocks,
+// gathering them only once for every chain head.
+func (gpo *Oracle) blockPrices(ctx context.Context) ([]*big.Int, error) {
 	gpo.cacheLock.RLock()
 	lastHead := gpo.lastHead
-	lastPrice := gpo.lastPrice
+	lastPrices := gpo.lastPrices
 	gpo.cacheLock.RUnlock()
 
 	head, _ := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	headHash := head.Hash()
 	if headHash == lastHead {
-		return lastPrice, nil
+		return lastPrices, nil
 	}
 
 	gpo.fetchLock.Lock()
@@ -92,10 +142,10 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	// try checking the cache again, maybe the last fetch fetched what we need
 	gpo.cacheLock.RLock()
 	lastHead = gpo.lastHead
-	lastPrice = gpo.lastPrice
+	lastPrices = gpo.lastPrices
 	gpo.cacheLock.RUnlock()
 	if headHash == lastHead {
-		return lastPrice, nil
+		return lastPrices, nil
 	}
 
 	blockNum := head.Number.Uint64()
@@ -113,7 +163,7 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	for exp > 0 {
 		res := <-ch
 		if res.err != nil {
-			return lastPrice, res.err
+			return nil, res.err
 		}
 		exp--
 		if res.price != nil {
@@ -131,20 +181,28 @@ func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
 			blockNum--
 		}
 	}
-	price := lastPrice
-	if len(blockPrices) > 0 {
-		sort.Sort(bigIntArray(blockPrices))
-		price = blockPrices[(len(blockPrices)-1)*gpo.percentile/100]
-	}
-	if price.Cmp(maxPrice) > 0 {
-		price = new(big.Int).Set(maxPrice)
-	}
+	sort.Sort(bigIntArray(blockPrices))
 
 	gpo.cacheLock.Lock()
 	gpo.lastHead = headHash
-	gpo.lastPrice = price
+	gpo.lastPrices = blockPrices
 	gpo.cacheLock.Unlock()
-	return price, nil
+	return blockPrices, nil
+}
+
+// pendingPrice returns the gas price at the given percentile of the executable
+// transactions in the pool, or nil if there are none.
+func (gpo *Oracle) pendingPrice(percentile int) *big.Int {
+	txs, err := gpo.backend.GetPoolTransactions()
+	if err != nil || len(txs) == 0 {
+		return nil
+	}
+	prices := make([]*big.Int, len(txs))
+	for i, tx := range txs {
+		prices[i] = tx.GasPrice()
+	}
+	sort.Sort(bigIntArray(prices))
+	return prices[(len(prices)-1)*percentile/100]
 }
 
 type getBlockPricesResult struct {
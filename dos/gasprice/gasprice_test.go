@@ -0,0 +1,181 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/internal/dosapi"
+	"github.com/doslink/dos/params"
+	"github.com/doslink/dos/rpc"
+)
+
+// testBackend is a chain of pre-generated blocks to feed the oracle with.
+type testBackend struct {
+	dosapi.Backend // Methods not needed by the oracle panic
+
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+	pending  types.Transactions
+}
+
+// newTestBackend creates a chain of blocks, each including a simple transfer
+// at each of the given gas prices.
+func newTestBackend(t *testing.T, prices [][]int64) *testBackend {
+	var (
+		key, _ = crypto.GenerateKey()
+		addr   = crypto.PubkeyToAddress(key.PublicKey)
+		db     = dosdb.NewMemDatabase()
+		gspec  = &core.Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  core.GenesisAlloc{addr: {Balance: big.NewInt(1000000000000000000)}},
+		}
+		genesis = gspec.MustCommit(db)
+		signer  = types.HomesteadSigner{}
+		nonce   = uint64(0)
+	)
+	blocks, receipts := core.GenerateChain(gspec.Config, genesis, dosash.NewFaker(), db, len(prices), func(i int, b *core.BlockGen) {
+		for _, price := range prices[i] {
+			tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{1}, big.NewInt(1), params.TxGas, big.NewInt(price), nil), signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			b.AddTx(tx)
+			nonce++
+		}
+	})
+	backend := &testBackend{
+		blocks:   append([]*types.Block{genesis}, blocks...),
+		receipts: make(map[common.Hash]types.Receipts),
+	}
+	for i, block := range blocks {
+		backend.receipts[block.Hash()] = receipts[i]
+	}
+	return backend
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	block, err := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, err
+	}
+	return block.Header(), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 {
+		return b.blocks[len(b.blocks)-1], nil
+	}
+	if int(number) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.pending, nil
+}
+
+func (b *testBackend) ChainConfig() *params.ChainConfig {
+	return params.TestChainConfig
+}
+
+// Tests that the suggested gas price targets the requested percentile of the
+// recent blocks' lowest prices, and factors in the pending pool if enabled.
+func TestSuggestPrice(t *testing.T) {
+	backend := newTestBackend(t, [][]int64{{10, 50}, {20}, {30, 40}, {40}, {50}})
+
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60, Default: big.NewInt(1)})
+	tests := []struct {
+		percentile int
+		want       int64
+	}{
+		{0, 10}, {25, 20}, {50, 30}, {100, 50},
+	}
+	for i, tt := range tests {
+		price, err := oracle.SuggestPercentile(context.Background(), tt.percentile)
+		if err != nil {
+			t.Fatalf("test %d: failed to suggest price: %v", i, err)
+		}
+		if price.Int64() != tt.want {
+			t.Errorf("test %d: price mismatch: have %v, want %v", i, price, tt.want)
+		}
+	}
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Int64() != 30 {
+		t.Errorf("configured price mismatch: have %v, want %v", price, 30)
+	}
+	// Congest the pool and ensure its prices are only considered if requested
+	for _, price := range []int64{100, 200, 300} {
+		backend.pending = append(backend.pending, types.NewTransaction(0, common.Address{}, nil, params.TxGas, big.NewInt(price), nil))
+	}
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Int64() != 30 {
+		t.Errorf("pool ignoring price mismatch: have %v, want %v", price, 30)
+	}
+	oracle = NewOracle(backend, Config{Blocks: 5, Percentile: 60, Pending: true, Default: big.NewInt(1)})
+	if price, _ := oracle.SuggestPrice(context.Background()); price.Int64() != 200 {
+		t.Errorf("pool aware price mismatch: have %v, want %v", price, 200)
+	}
+}
+
+// Tests that the fee history reports the gas used ratios and the gas weighted
+// price percentiles of the requested blocks.
+func TestFeeHistory(t *testing.T) {
+	backend := newTestBackend(t, [][]int64{{10, 20, 30, 40}, {}, {5}})
+	oracle := NewOracle(backend, Config{Blocks: 5, Percentile: 60})
+
+	oldest, ratios, prices, err := oracle.FeeHistory(context.Background(), 3, rpc.LatestBlockNumber, []float64{0, 50, 100})
+	if err != nil {
+		t.Fatalf("failed to retrieve fee history: %v", err)
+	}
+	if oldest.Uint64() != 1 {
+		t.Errorf("oldest block mismatch: have %v, want %v", oldest, 1)
+	}
+	limit := float64(backend.blocks[1].GasLimit())
+	if len(ratios) != 3 || ratios[0] != 4*float64(params.TxGas)/limit || ratios[1] != 0 {
+		t.Errorf("gas used ratios mismatch: have %v", ratios)
+	}
+	want := [][]int64{{10, 20, 40}, {0, 0, 0}, {5, 5, 5}}
+	for i := range want {
+		for j := range want[i] {
+			if prices[i][j].Int64() != want[i][j] {
+				t.Errorf("block %d, percentile %d: price mismatch: have %v, want %v", i, j, prices[i][j], want[i][j])
+			}
+		}
+	}
+	// Ensure the range is clipped to the chain and invalid requests rejected
+	if oldest, ratios, _, _ := oracle.FeeHistory(context.Background(), 10, 1, nil); oldest.Uint64() != 0 || len(ratios) != 2 {
+		t.Errorf("clipped range mismatch: have oldest %v, %d blocks", oldest, len(ratios))
+	}
+	if _, _, _, err := oracle.FeeHistory(context.Background(), 1, 10, nil); err == nil {
+		t.Errorf("fee history beyond head accepted")
+	}
+	if _, _, _, err := oracle.FeeHistory(context.Background(), 1, rpc.LatestBlockNumber, []float64{50, 10}); err == nil {
+		t.Errorf("unsorted percentiles accepted")
+	}
+}
@@ -4,6 +4,7 @@ package dos
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/doslink/dos/common"
 	"github.com/doslink/dos/common/hexutil"
@@ -17,23 +18,36 @@ var _ = (*configMarshaling)(nil)
 
 func (c Config) MarshalTOML() (interface{}, error) {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               uint64
-		SyncMode                downloader.SyncMode
-		LightServ               int  `toml:",omitempty"`
-		LightPeers              int  `toml:",omitempty"`
-		SkipBcVersionCheck      bool `toml:"-"`
-		DatabaseHandles         int  `toml:"-"`
-		DatabaseCache           int
-		Doserbase               common.Address `toml:",omitempty"`
-		MinerThreads            int            `toml:",omitempty"`
-		ExtraData               hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Dosash                  dosash.Config
-		TxPool                  core.TxPoolConfig
-		GPO                     gasprice.Config
-		EnablePreimageRecording bool
-		DocRoot                 string `toml:"-"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                uint64
+		SyncMode                 downloader.SyncMode
+		LightServ                int  `toml:",omitempty"`
+		LightPeers               int  `toml:",omitempty"`
+		SkipBcVersionCheck       bool `toml:"-"`
+		DatabaseHandles          int  `toml:"-"`
+		DatabaseCache            int
+		DatabaseFreezer          string `toml:",omitempty"`
+		DatabaseFreezerThreshold uint64
+		Snapshot                 bool           `toml:",omitempty"`
+		SnapshotRebuild          bool           `toml:"-"`
+		TraceIndex               bool           `toml:",omitempty"`
+		Doserbase                common.Address `toml:",omitempty"`
+		MinerThreads             int            `toml:",omitempty"`
+		ExtraData                hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                 *big.Int
+		MinerRecommit            time.Duration
+		MinerNoEmpty             bool
+		MinerNotify              []string         `toml:",omitempty"`
+		MinerTxOrder             string           `toml:",omitempty"`
+		MinerPrioritySenders     []common.Address `toml:",omitempty"`
+		MinerSenderTxLimit       int              `toml:",omitempty"`
+		MinerStratum             string           `toml:",omitempty"`
+		MinerStratumDiff         float64
+		Dosash                   dosash.Config
+		TxPool                   core.TxPoolConfig
+		GPO                      gasprice.Config
+		EnablePreimageRecording  bool
+		DocRoot                  string `toml:"-"`
 	}
 	var enc Config
 	enc.Genesis = c.Genesis
@@ -44,10 +58,23 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.DatabaseFreezer = c.DatabaseFreezer
+	enc.DatabaseFreezerThreshold = c.DatabaseFreezerThreshold
+	enc.Snapshot = c.Snapshot
+	enc.SnapshotRebuild = c.SnapshotRebuild
+	enc.TraceIndex = c.TraceIndex
 	enc.Doserbase = c.Doserbase
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
 	enc.GasPrice = c.GasPrice
+	enc.MinerRecommit = c.MinerRecommit
+	enc.MinerNoEmpty = c.MinerNoEmpty
+	enc.MinerNotify = c.MinerNotify
+	enc.MinerTxOrder = c.MinerTxOrder
+	enc.MinerPrioritySenders = c.MinerPrioritySenders
+	enc.MinerSenderTxLimit = c.MinerSenderTxLimit
+	enc.MinerStratum = c.MinerStratum
+	enc.MinerStratumDiff = c.MinerStratumDiff
 	enc.Dosash = c.Dosash
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
@@ -58,23 +85,36 @@ func (c Config) MarshalTOML() (interface{}, error) {
 
 func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	type Config struct {
-		Genesis                 *core.Genesis `toml:",omitempty"`
-		NetworkId               *uint64
-		SyncMode                *downloader.SyncMode
-		LightServ               *int  `toml:",omitempty"`
-		LightPeers              *int  `toml:",omitempty"`
-		SkipBcVersionCheck      *bool `toml:"-"`
-		DatabaseHandles         *int  `toml:"-"`
-		DatabaseCache           *int
-		Doserbase               *common.Address `toml:",omitempty"`
-		MinerThreads            *int            `toml:",omitempty"`
-		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
-		GasPrice                *big.Int
-		Dosash                  *dosash.Config
-		TxPool                  *core.TxPoolConfig
-		GPO                     *gasprice.Config
-		EnablePreimageRecording *bool
-		DocRoot                 *string `toml:"-"`
+		Genesis                  *core.Genesis `toml:",omitempty"`
+		NetworkId                *uint64
+		SyncMode                 *downloader.SyncMode
+		LightServ                *int  `toml:",omitempty"`
+		LightPeers               *int  `toml:",omitempty"`
+		SkipBcVersionCheck       *bool `toml:"-"`
+		DatabaseHandles          *int  `toml:"-"`
+		DatabaseCache            *int
+		DatabaseFreezer          *string `toml:",omitempty"`
+		DatabaseFreezerThreshold *uint64
+		Snapshot                 *bool           `toml:",omitempty"`
+		SnapshotRebuild          *bool           `toml:"-"`
+		TraceIndex               *bool           `toml:",omitempty"`
+		Doserbase                *common.Address `toml:",omitempty"`
+		MinerThreads             *int            `toml:",omitempty"`
+		ExtraData                *hexutil.Bytes  `toml:",omitempty"`
+		GasPrice                 *big.Int
+		MinerRecommit            *time.Duration
+		MinerNoEmpty             *bool
+		MinerNotify              []string         `toml:",omitempty"`
+		MinerTxOrder             *string          `toml:",omitempty"`
+		MinerPrioritySenders     []common.Address `toml:",omitempty"`
+		MinerSenderTxLimit       *int             `toml:",omitempty"`
+		MinerStratum             *string          `toml:",omitempty"`
+		MinerStratumDiff         *float64
+		Dosash                   *dosash.Config
+		TxPool                   *core.TxPoolConfig
+		GPO                      *gasprice.Config
+		EnablePreimageRecording  *bool
+		DocRoot                  *string `toml:"-"`
 	}
 	var dec Config
 	if err := unmarshal(&dec); err != nil {
@@ -104,6 +144,21 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.DatabaseFreezer != nil {
+		c.DatabaseFreezer = *dec.DatabaseFreezer
+	}
+	if dec.DatabaseFreezerThreshold != nil {
+		c.DatabaseFreezerThreshold = *dec.DatabaseFreezerThreshold
+	}
+	if dec.Snapshot != nil {
+		c.Snapshot = *dec.Snapshot
+	}
+	if dec.SnapshotRebuild != nil {
+		c.SnapshotRebuild = *dec.SnapshotRebuild
+	}
+	if dec.TraceIndex != nil {
+		c.TraceIndex = *dec.TraceIndex
+	}
 	if dec.Doserbase != nil {
 		c.Doserbase = *dec.Doserbase
 	}
@@ -116,6 +171,30 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GasPrice != nil {
 		c.GasPrice = dec.GasPrice
 	}
+	if dec.MinerRecommit != nil {
+		c.MinerRecommit = *dec.MinerRecommit
+	}
+	if dec.MinerNoEmpty != nil {
+		c.MinerNoEmpty = *dec.MinerNoEmpty
+	}
+	if dec.MinerNotify != nil {
+		c.MinerNotify = dec.MinerNotify
+	}
+	if dec.MinerTxOrder != nil {
+		c.MinerTxOrder = *dec.MinerTxOrder
+	}
+	if dec.MinerPrioritySenders != nil {
+		c.MinerPrioritySenders = dec.MinerPrioritySenders
+	}
+	if dec.MinerSenderTxLimit != nil {
+		c.MinerSenderTxLimit = *dec.MinerSenderTxLimit
+	}
+	if dec.MinerStratum != nil {
+		c.MinerStratum = *dec.MinerStratum
+	}
+	if dec.MinerStratumDiff != nil {
+		c.MinerStratumDiff = *dec.MinerStratumDiff
+	}
 	if dec.Dosash != nil {
 		c.Dosash = *dec.Dosash
 	}
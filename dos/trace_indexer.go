@@ -0,0 +1,129 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package dos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
+)
+
+const (
+	// traceIndexSection is the number of blocks in a single trace index section.
+	traceIndexSection = 4096
+
+	// traceIndexConfirms is the number of confirmation blocks before a trace index
+	// section is considered probably final and its calls are indexed.
+	traceIndexConfirms = 256
+
+	// traceIndexThrottling is the time to wait between processing two consecutive
+	// index sections. It's useful during chain upgrades to prevent disk overload.
+	traceIndexThrottling = 100 * time.Millisecond
+)
+
+// TraceIndexer implements a core.ChainIndexer, building up an index of the blocks
+// in which each account took part in a call, either as the sender or recipient,
+// permitting fast trace filtering over large block ranges.
+//
+// Indexing reexecutes every block, so it needs the historical states to be
+// available, i.e. an archive node.
+type TraceIndexer struct {
+	db    dosdb.Database                                                           // database instance to write index data into
+	trace func(ctx context.Context, block *types.Block) ([]*LocalizedTrace, error) // reexecutes a block, flattening its calls
+
+	section uint64                      // Section is the section number being processed currently
+	head    common.Hash                 // Head is the hash of the last header processed
+	offsets map[common.Address][]uint64 // Offsets of the blocks within the section each account took part in
+	err     error                       // Failure encountered while processing the section
+}
+
+// NewTraceIndexer returns a chain indexer that generates the account to block
+// index of the call traces of the canonical chain.
+func NewTraceIndexer(db dosdb.Database, api *PrivateDebugAPI) *core.ChainIndexer {
+	backend := &TraceIndexer{
+		db:    db,
+		trace: api.traceBlockCalls,
+	}
+	table := dosdb.NewTable(db, string(rawdb.TraceIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, traceIndexSection, traceIndexConfirms, traceIndexThrottling, "traces")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new trace index section.
+func (t *TraceIndexer) Reset(section uint64, lastSectionHead common.Hash) error {
+	t.section, t.head = section, common.Hash{}
+	t.offsets, t.err = make(map[common.Address][]uint64), nil
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, adding the accounts of all the
+// calls of a new block into the index.
+func (t *TraceIndexer) Process(header *types.Header) {
+	if t.err != nil {
+		return
+	}
+	number := header.Number.Uint64()
+
+	block := rawdb.ReadBlock(t.db, header.Hash(), number)
+	if block == nil {
+		t.err = fmt.Errorf("block #%d [%x…] not found", number, header.Hash().Bytes()[:4])
+		return
+	}
+	traces, err := t.trace(context.Background(), block)
+	if err != nil {
+		t.err = fmt.Errorf("block #%d [%x…] not traced: %v", number, header.Hash().Bytes()[:4], err)
+		return
+	}
+	offset := number - t.section*traceIndexSection
+	for _, trace := range traces {
+		from, to := traceParties(trace.FlatCall)
+		for _, addr := range []*common.Address{from, to} {
+			if addr == nil {
+				continue
+			}
+			if offsets := t.offsets[*addr]; len(offsets) == 0 || offsets[len(offsets)-1] != offset {
+				t.offsets[*addr] = append(offsets, offset)
+			}
+		}
+	}
+	t.head = header.Hash()
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the index of the
+// section into the database.
+func (t *TraceIndexer) Commit() error {
+	if t.err != nil {
+		return t.err
+	}
+	batch := t.db.NewBatch()
+	for addr, offsets := range t.offsets {
+		rawdb.WriteTraceIndex(batch, t.section, t.head, addr, offsets)
+		if batch.ValueSize() > dosdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	return batch.Write()
+}
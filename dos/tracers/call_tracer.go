@@ -0,0 +1,341 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/log"
+)
+
+// callFrame is a single call reported by the call tracer. The fields are in the
+// same order the JavaScript callTracer serializes them.
+type callFrame struct {
+	Type    string       `json:"type"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Value   string       `json:"value,omitempty"`
+	Gas     string       `json:"gas,omitempty"`
+	GasUsed string       `json:"gasUsed,omitempty"`
+	Input   string       `json:"input,omitempty"`
+	Output  string       `json:"output,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Time    string       `json:"time,omitempty"`
+	Calls   []*callFrame `json:"calls,omitempty"`
+
+	gasIn   uint64 // Gas available before the call opcode executed
+	gasCost uint64 // Cost of the call opcode, including the forwarded gas
+	gas     uint64 // Gas available to the callee when it started executing
+	hasGas  bool   // Whether the callee executed code, making gas meaningful
+	outOff  uint64 // Memory offset of the return data in the caller
+	outLen  uint64 // Length of the return data in the caller
+
+	address common.Address // Self destructed contract, not reported by the callTracer
+	refund  common.Address // Beneficiary of a self destruct, not reported by the callTracer
+	balance *big.Int       // Balance moved by a self destruct, not reported by the callTracer
+}
+
+// callTracer is the native implementation of the JavaScript callTracer, which
+// extracts and reports all the internal calls made by a transaction.
+type callTracer struct {
+	callstack  []*callFrame // Current recursive call stack of the EVM execution
+	descended  bool         // Whether we've just descended into an inner call
+	typ        string       // Type of the outer transaction (CALL or CREATE)
+	from       common.Address
+	to         common.Address
+	input      []byte
+	gas        uint64
+	value      *big.Int
+	output     []byte
+	gasUsed    uint64
+	time       string
+	err        error
+	interrupt  uint32 // Atomic flag to signal execution interruption
+	reason     error  // Textual reason for the interruption
+	stopReason error  // Interruption reason the tracing actually stopped with
+}
+
+// newCallTracer creates a native call tracer.
+func newCallTracer() *callTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.typ = "CALL"
+	if create {
+		t.typ = "CREATE"
+	}
+	t.from, t.to, t.input, t.gas, t.value = from, to, input, gas, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.stopReason != nil {
+		return nil
+	}
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.stopReason = t.reason
+		return nil
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(err)
+		return nil
+	}
+	// We only care about system opcodes, faster if we pre-check once
+	syscall := op&0xf0 == 0xf0
+
+	// If a new contract is being created, add to the call stack
+	if syscall && (op == vm.CREATE || op == vm.CREATE2) {
+		inOff := stackUint64(stack, 1)
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			Input:   hexutil.Encode(memorySlice(memory, inOff, inOff+stackUint64(stack, 2))),
+			Value:   "0x" + stackPeek(stack, 0).Text(16),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return nil
+	}
+	// If a contract is being self destructed, gather that as a subcall too
+	if syscall && op == vm.SELFDESTRUCT {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &callFrame{
+			Type:    op.String(),
+			address: contract.Address(),
+			refund:  common.BigToAddress(stackPeek(stack, 0)),
+			balance: new(big.Int).Set(env.StateDB.GetBalance(contract.Address())),
+		})
+		return nil
+	}
+	// If a new method invocation is being done, add to the call stack
+	if syscall && (op == vm.CALL || op == vm.CALLCODE || op == vm.DELEGATECALL || op == vm.STATICCALL) {
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.BigToAddress(stackPeek(stack, 1))
+		if env.IsPrecompile(to) {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		inOff := stackUint64(stack, 2+off)
+
+		call := &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			To:      hexutil.Encode(to.Bytes()),
+			Input:   hexutil.Encode(memorySlice(memory, inOff, inOff+stackUint64(stack, 3+off))),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  stackUint64(stack, 4+off),
+			outLen:  stackUint64(stack, 5+off),
+		}
+		if op != vm.DELEGATECALL && op != vm.STATICCALL {
+			call.Value = "0x" + stackPeek(stack, 2).Text(16)
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+	// If we've just descended into an inner call, retrieve it's true allowance. We
+	// need to extract if from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	if t.descended {
+		// Calls made to plain accounts don't expose their true gas amount, skip them
+		if depth >= len(t.callstack) {
+			call := t.callstack[len(t.callstack)-1]
+			call.gas, call.hasGas = gas, true
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if syscall && op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = "execution reverted"
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		if call.Type == vm.CREATE.String() || call.Type == vm.CREATE2.String() {
+			// If the call was a CREATE, retrieve the contract address and output code
+			call.GasUsed = hexInt64(int64(call.gasIn) - int64(call.gasCost) - int64(gas))
+
+			if ret := stackPeek(stack, 0); ret.Sign() != 0 {
+				addr := common.BigToAddress(ret)
+				call.To = hexutil.Encode(addr.Bytes())
+				call.Output = hexutil.Encode(env.StateDB.GetCode(addr))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else if call.hasGas {
+			// If the call was a contract call, retrieve the gas usage and output
+			call.GasUsed = hexInt64(int64(call.gasIn) - int64(call.gasCost) + int64(call.gas) - int64(gas))
+
+			if ret := stackPeek(stack, 0); ret.Sign() != 0 {
+				call.Output = hexutil.Encode(memorySlice(memory, call.outOff, call.outOff+call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.hasGas {
+			call.Gas = hexutil.EncodeUint64(call.gas)
+		}
+		// Inject the call into the previous one
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.stopReason == nil {
+		t.fault(err)
+	}
+	return nil
+}
+
+// fault handles the failure of the currently executing call.
+func (t *callTracer) fault(err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+	call.Error = err.Error()
+
+	// Consume all available gas
+	if call.hasGas {
+		call.Gas = hexutil.EncodeUint64(call.gas)
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent, or leave it if it was the last one
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output, t.gasUsed, t.time = output, gasUsed, d.String()
+	if err != nil {
+		t.err = err
+	}
+	return nil
+}
+
+// GetResult returns the JSON encoded call tree of the transaction.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	blob, err := json.Marshal(t.result())
+	if err != nil {
+		return nil, err
+	}
+	return blob, t.stopReason
+}
+
+// result assembles the outer call of the transaction, containing all the inner
+// calls made.
+func (t *callTracer) result() *callFrame {
+	value := new(big.Int)
+	if t.value != nil {
+		value = t.value
+	}
+	result := &callFrame{
+		Type:    t.typ,
+		From:    hexutil.Encode(t.from.Bytes()),
+		To:      hexutil.Encode(t.to.Bytes()),
+		Value:   "0x" + value.Text(16),
+		Gas:     hexutil.EncodeUint64(t.gas),
+		GasUsed: hexutil.EncodeUint64(t.gasUsed),
+		Input:   hexutil.Encode(t.input),
+		Output:  hexutil.Encode(t.output),
+		Time:    t.time,
+		Calls:   t.callstack[0].Calls,
+	}
+	if t.callstack[0].Error != "" {
+		result.Error = t.callstack[0].Error
+	} else if t.err != nil {
+		result.Error = t.err.Error()
+	}
+	if result.Error != "" {
+		result.Output = ""
+	}
+	return result
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *callTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// stackPeek returns the nth-from-the-top element of the stack, or zero if the
+// stack is not deep enough.
+func stackPeek(stack *vm.Stack, n int) *big.Int {
+	data := stack.Data()
+	if len(data) <= n {
+		log.Warn("Tracer accessed out of bound stack", "size", len(data), "index", n)
+		return new(big.Int)
+	}
+	return data[len(data)-n-1]
+}
+
+// stackUint64 returns the nth-from-the-top element of the stack as an offset or
+// length into the memory, saturating on overflow.
+func stackUint64(stack *vm.Stack, n int) uint64 {
+	value := stackPeek(stack, n)
+	if !value.IsUint64() {
+		return ^uint64(0)
+	}
+	return value.Uint64()
+}
+
+// memorySlice returns the requested range of memory as a byte slice, or nil if
+// it's out of bounds.
+func memorySlice(memory *vm.Memory, begin, end uint64) []byte {
+	if end < begin || uint64(memory.Len()) < end {
+		log.Warn("Tracer accessed out of bound memory", "available", memory.Len(), "offset", begin, "size", end-begin)
+		return nil
+	}
+	return memory.Get(int64(begin), int64(end-begin))
+}
+
+// hexInt64 encodes a possibly negative integer the same way the JavaScript
+// tracers do.
+func hexInt64(n int64) string {
+	return "0x" + strconv.FormatInt(n, 16)
+}
@@ -0,0 +1,182 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/core/vm"
+)
+
+// FlatCallAction is the input of a flattened call: the parameters of a call or
+// a contract creation, or the funds moved by a self destruct.
+type FlatCallAction struct {
+	CallType      string          `json:"callType,omitempty"`
+	From          *common.Address `json:"from,omitempty"`
+	To            *common.Address `json:"to,omitempty"`
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`
+	Input         *hexutil.Bytes  `json:"input,omitempty"`
+	Init          *hexutil.Bytes  `json:"init,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// FlatCallResult is the output of a successful flattened call or creation.
+type FlatCallResult struct {
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+}
+
+// FlatCall is a single call of a transaction in the flat, Parity style trace
+// format. Its position in the call tree is given by the trace address, the list
+// of child indexes leading to it from the outer call.
+type FlatCall struct {
+	Action       FlatCallAction  `json:"action"`
+	Error        string          `json:"error,omitempty"`
+	Result       *FlatCallResult `json:"result"`
+	Subtraces    int             `json:"subtraces"`
+	TraceAddress []int           `json:"traceAddress"`
+	Type         string          `json:"type"`
+}
+
+// FlatCallTracer is a native tracer collecting all the calls of a transaction,
+// including the value transfers of self destructs, flattened in depth first
+// order. It is not reachable by name through TraceConfig, it's meant to back
+// the trace_* APIs.
+type FlatCallTracer struct {
+	*callTracer
+}
+
+// NewFlatCallTracer creates a native flat call tracer.
+func NewFlatCallTracer() *FlatCallTracer {
+	return &FlatCallTracer{callTracer: newCallTracer()}
+}
+
+// Traces returns the flattened calls made by the transaction.
+func (t *FlatCallTracer) Traces() ([]*FlatCall, error) {
+	if t.stopReason != nil {
+		return nil, t.stopReason
+	}
+	return flattenCall(t.result(), []int{}), nil
+}
+
+// GetResult returns the JSON encoded flattened calls made by the transaction.
+func (t *FlatCallTracer) GetResult() (json.RawMessage, error) {
+	traces, err := t.Traces()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(traces)
+}
+
+// flattenCall converts a call and all its inner calls into the flat format,
+// depth first.
+func flattenCall(call *callFrame, address []int) []*FlatCall {
+	flat := &FlatCall{
+		Error:        call.Error,
+		Subtraces:    len(call.Calls),
+		TraceAddress: address,
+	}
+	switch call.Type {
+	case vm.OpCode(vm.SELFDESTRUCT).String():
+		flat.Type = "suicide"
+		flat.Action = FlatCallAction{
+			Address:       &call.address,
+			RefundAddress: &call.refund,
+			Balance:       (*hexutil.Big)(call.balance),
+		}
+
+	case vm.CREATE.String(), vm.CREATE2.String():
+		flat.Type = "create"
+		flat.Action = FlatCallAction{
+			From:  decodeAddress(call.From),
+			Gas:   decodeUint64(call.Gas),
+			Init:  decodeBytes(call.Input),
+			Value: decodeBig(call.Value),
+		}
+		if call.Error == "" {
+			flat.Result = &FlatCallResult{
+				Address: decodeAddress(call.To),
+				Code:    decodeBytes(call.Output),
+				GasUsed: *decodeUint64(call.GasUsed),
+			}
+		}
+
+	default:
+		flat.Type = "call"
+		flat.Action = FlatCallAction{
+			CallType: strings.ToLower(call.Type),
+			From:     decodeAddress(call.From),
+			To:       decodeAddress(call.To),
+			Gas:      decodeUint64(call.Gas),
+			Input:    decodeBytes(call.Input),
+			Value:    decodeBig(call.Value),
+		}
+		if call.Error == "" {
+			flat.Result = &FlatCallResult{
+				GasUsed: *decodeUint64(call.GasUsed),
+				Output:  decodeBytes(call.Output),
+			}
+		}
+	}
+	calls := []*FlatCall{flat}
+	for i, inner := range call.Calls {
+		calls = append(calls, flattenCall(inner, append(append([]int{}, address...), i))...)
+	}
+	return calls
+}
+
+// decodeAddress converts a hex encoded address of the call tracer back, nil if
+// it's missing.
+func decodeAddress(hex string) *common.Address {
+	if hex == "" {
+		return nil
+	}
+	addr := common.HexToAddress(hex)
+	return &addr
+}
+
+// decodeBytes converts a hex encoded binary blob of the call tracer back.
+func decodeBytes(hex string) *hexutil.Bytes {
+	blob := hexutil.Bytes(common.FromHex(hex))
+	return &blob
+}
+
+// decodeUint64 converts a hex encoded gas amount of the call tracer back, zero
+// if it's missing or invalid.
+func decodeUint64(hex string) *hexutil.Uint64 {
+	n, _ := hexutil.DecodeUint64(hex)
+	return (*hexutil.Uint64)(&n)
+}
+
+// decodeBig converts a hex encoded value of the call tracer back, zero if it's
+// missing (e.g. delegate calls).
+func decodeBig(hex string) *hexutil.Big {
+	n, err := hexutil.DecodeBig(hex)
+	if err != nil {
+		n = new(big.Int)
+	}
+	return (*hexutil.Big)(n)
+}
@@ -92,7 +92,7 @@ func _4byte_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x59\xdf\x6f\x1b\x37\xf2\x7f\x96\xfe\x8a\x49\x1e\x6a\x09\x51\x24\x27\xe9\xb7\x5f\xc0\xae\x7a\xd0\x39\x4a\x6a\xc0\x8d\x03\x5b\x69\x10\x04\x79\xa0\x76\x67\x25\xd6\x5c\x72\x4b\x72\x2d\xef\xa5\xfe\xdf\x0f\x33\xe4\xae\x56\x3f\xec\xe8\x7a\xb8\x43\xef\x45\xd0\x2e\x67\x86\xc3\x99\xcf\xfc\xe2\x8e\x46\x70\x66\x8a\xca\xca\xc5\xd2\xc3\xcb\xe3\x17\xff\x0f\xb3\x25\xc2\xc2\x3c\x47\xbf\x44\x8b\x65\x0e\x93\xd2\x2f\x8d\x75\xdd\xd1\x08\x66\x4b\xe9\x20\x93\x0a\x41\x3a\x28\x84\xf5\x60\x32\xf0\x5b\xf4\x4a\xce\xad\xb0\xd5\xb0\x3b\x1a\x05\x9e\xbd\xcb\x24\x21\xb3\x88\xe0\x4c\xe6\x57\xc2\xe2\x09\x54\xa6\x84\x44\x68\xb0\x98\x4a\xe7\xad\x9c\x97\x1e\x41\x7a\x10\x3a\x1d\x19\x0b\xb9\x49\x65\x56\x91\x48\xe9\xa1\xd4\x29\x5a\xde\xda\xa3\xcd\x5d\xad\xc7\xdb\x77\x1f\xe0\x02\x9d\x43\x0b\x6f\x51\xa3\x15\x0a\xde\x97\x73\x25\x13\xb8\x90\x09\x6a\x87\x20\x1c\x14\xf4\xc6\x2d\x31\x85\x39\x8b\x23\xc6\x37\xa4\xca\x75\x54\x05\xde\x98\x52\xa7\xc2\x4b\xa3\x07\x80\x92\x34\x87\x5b\xb4\x4e\x1a\x0d\xaf\xea\xad\xa2\xc0\x01\x18\x4b\x42\x7a\xc2\xd3\x01\x2c\x98\x82\xf8\xfa\x20\x74\x05\x4a\xf8\x35\xeb\x01\x06\x59\x9f\x3b\x05\xa9\x79\x9b\xa5\x29\x10\xfc\x52\x78\x3a\xf5\x4a\x2a\x05\x73\x84\xd2\x61\x56\xaa\x01\x49\x9b\x97\x1e\x3e\x9e\xcf\x7e\xbe\xfc\x30\x83\xc9\xbb\x4f\xf0\x71\x72\x75\x35\x79\x37\xfb\x74\x0a\x2b\xe9\x97\xa6\xf4\x80\xb7\x18\x44\xc9\xbc\x50\x12\x53\x58\x09\x6b\x85\xf6\x15\x98\x8c\x24\xfc\x32\xbd\x3a\xfb\x79\xf2\x6e\x36\xf9\xfb\xf9\xc5\xf9\xec\x13\x18\x0b\x6f\xce\x67\xef\xa6\xd7\xd7\xf0\xe6\xf2\x0a\x26\xf0\x7e\x72\x35\x3b\x3f\xfb\x70\x31\xb9\x82\xf7\x1f\xae\xde\x5f\x5e\x4f\x87\x70\x8d\xa4\x15\x12\xff\xb7\x6d\x9e\xb1\xf7\x2c\x42\x8a\x5e\x48\xe5\x6a\x4b\x7c\x32\x25\xb8\xa5\x29\x55\x0a\x4b\x71\x8b\x60\x31\x41\x79\x8b\x29\x08\x48\x4c\x51\x1d\xec\x54\x92\x25\x94\xd1\x0b\x3e\xf3\x83\x80\x84\xf3\x0c\xb4\xf1\x03\x70\x88\xf0\xe3\xd2\xfb\xe2\x64\x34\x5a\xad\x56\xc3\x85\x2e\x87\xc6\x2e\x46\x2a\x88\x73\xa3\x9f\x86\x5d\x92\x99\x08\xa5\x66\x56\x24\x68\xc9\x39\x02\xb2\x92\xcc\xaf\xcc\x4a\x83\xb7\x42\x3b\x91\x90\xab\xe9\x7f\xc2\x60\x14\x1e\xf0\x8e\x9e\xbc\x23\xd0\x82\xc5\xc2\x58\xfa\xaf\x54\x8d\x33\xa9\x3d\x5a\x2d\x14\xcb\x76\x90\x8b\x14\x61\x5e\x81\x68\x0b\x1c\xb4\x0f\x43\x30\x0a\xee\x06\xa9\x33\x63\x73\x86\xe5\xb0\xfb\xb5\xdb\x89\x1a\x3a\x2f\x92\x1b\x52\x90\xe4\x27\xa5\xb5\xa8\x3d\x99\xb2\xb4\x4e\xde\x22\x93\x40\xa0\x89\xf6\x9c\xfe\xfa\x0b\xe0\x1d\x26\x65\x90\xd4\x69\x84\x9c\xc0\xe7\xaf\xf7\x5f\x06\x5d\x16\x9d\xa2\x4b\x50\xa7\x98\xf2\xf9\x6e\x1c\xac\x96\x6c\x51\x58\xe1\xd1\x2d\xc2\x6f\xa5\xf3\x2d\x9a\xcc\x9a\x1c\x84\x06\x53\x12\xe2\xdb\xd6\x91\xda\x1b\x16\x28\xe8\xbf\x46\xcb\x1a\x0d\xbb\x9d\x86\xf9\x04\x32\xa1\x1c\xc6\x7d\x9d\xc7\x82\x4e\x23\xf5\xad\xb9\x21\xc9\xc6\x12\x84\x6d\x05\xa6\x48\x4c\x1a\x83\x81\xce\xd1\x1c\x03\xdd\xb0\xdb\x21\xbe\x13\xc8\x4a\xcd\xdb\xf6\x94\x59\x0c\x20\x9d\xf7\xe1\x6b\xb7\x43\x62\xcf\x44\xe1\x4b\x8b\x6c\x4f\xb4\xd6\x58\x07\x32\xcf\x31\x95\xc2\xa3\xaa\xba\x9d\xce\xad\xb0\x61\x01\xc6\xa0\xcc\x62\xb8\x40\x3f\xa5\xc7\x5e\xff\xb4\xdb\xe9\xc8\x0c\x7a\x61\xf5\xc9\x78\xcc\xd9\x27\x93\x1a\xd3\x20\xbe\xe3\x97\xd2\x0d\x33\x51\x2a\xdf\xec\x4b\x4c\x1d\x8b\xbe\xb4\x9a\xfe\xde\x07\x2d\x3e\x22\x18\xad\x2a\x48\x28\xcb\x88\x39\x85\xa7\xab\x9c\xc7\x3c\x1e\xce\x0d\x20\x13\x8e\x4c\x28\x33\x58\x21\x14\x16\x9f\x27\x4b\x24\xdf\xe9\x04\xa3\x96\xae\x72\xec\xd4\x31\xd0\x6e\x43\x53\x0c\xbd\x79\x57\xe6\x73\xb4\xbd\x3e\x7c\x07\xc7\x77\xd9\x71\x1f\xc6\x63\xfe\x53\xeb\x1e\x79\xa2\xbe\x24\xc5\x14\xf1\xa0\xcc\x7f\xed\xad\xd4\x8b\x70\xd6\xa8\xeb\x79\x06\x02\x34\xae\x20\x31\x9a\x41\x4d\x5e\x99\xa3\xd4\x0b\x48\x2c\x0a\x8f\xe9\x00\x44\x9a\x82\x37\x01\x79\x0d\xce\x36\xb7\x84\xef\xbe\xe3\xbd\xc6\x70\x74\x76\x35\x9d\xcc\xa6\x47\x2d\x25\xa4\xbe\xcc\xb2\xa8\x07\xf3\x0e\x0b\xc4\x9b\xde\x8b\xfe\xf0\x56\xa8\x12\x2f\xb3\xa0\x51\xa4\x9d\xea\x14\xc6\x91\xe7\xd9\x36\xcf\xcb\x0d\x1e\x62\x1a\x8d\x60\xe2\x1c\xe6\x73\x85\xbb\xb1\x17\x83\x93\xe3\xd4\x79\x4a\x4e\x04\xb4\xc4\xe4\x85\x42\x02\x50\xbd\x6b\xb4\x34\x6b\xdc\xf1\x55\x81\x27\x00\x00\xa6\x18\xf0\x0b\x82\x3d\xbf\xf0\xe6\x67\xbc\x63\x77\xd4\xd6\x22\x00\x4d\xd2\xd4\xa2\x73\xbd\x7e\x3f\x90\x4b\x5d\x94\xfe\x64\x83\x3c\xc7\xdc\xd8\x6a\xe8\x28\xf7\xf4\xf8\x68\x83\x70\xd2\x9a\x67\x21\xdc\xb9\x26\x9e\x08\xca\xb7\xc2\xf5\xd6\x4b\x67\xc6\xf9\x93\x7a\x89\x1e\xea\x35\xb6\x05\xb1\x1d\x1d\xdf\x1d\xed\x5a\xeb\xb8\xbf\x76\xfa\x8b\x1f\xfa\xc4\x72\x7f\xda\x40\xb9\xc9\x08\xc3\xa2\x74\xcb\x1e\x23\x67\xbd\xba\x8e\xfa\x31\x78\x5b\xe2\x5e\xa4\x33\x7a\x76\x91\xe3\x50\x65\x94\x36\xbc\x2d\x13\x46\xd0\x42\x70\x52\xe1\xa0\x16\x94\x64\x5d\x39\x67\x9b\x7b\x63\x1e\x04\xd2\xf5\xf4\xe2\xcd\xeb\xe9\xf5\xec\xea\xc3\xd9\xac\x0d\x27\x85\x99\x27\xa5\x36\xcf\xa0\x50\x2f\xfc\x92\xf5\x27\x71\x9b\xab\x9f\x89\xe7\xf9\x8b\x2f\xe1\x0d\x8c\xf7\x44\x77\xe7\x71\x0e\xf8\xfc\x85\x65\xdf\xef\x9a\x6f\x93\x34\x18\xf3\x6b\x00\x91\x29\xee\xdb\x39\x62\x4f\xd8\xe5\xe8\x97\x26\xe5\x3c\x98\x88\x90\x4a\x6b\x2b\xa6\x46\xe3\xc1\xc1\xd7\xab\xa3\x6f\x72\x71\x71\x04\x7f\xfc\x01\xad\xe7\xb3\xcb\xd7\xd3\xf6\xbb\xd7\xd3\x8b\xe9\xdb\xc9\x6c\xba\x4d\x7b\x3d\x9b\xcc\xce\xcf\xf8\x6d\x3f\x5a\x65\x34\x82\xeb\x1b\x59\x70\x42\xe5\x34\x65\xf2\x82\x3b\xc3\x46\x5f\x37\x00\xbf\x34\xd4\x73\xd9\x58\x2f\x32\xa1\x93\x3a\x8f\xbb\xda\x69\xde\x90\xcb\x4c\x1d\x2b\xbb\xa9\xa0\x0d\xd4\x7e\xe3\x46\xe9\xde\x5b\x8c\x9b\xa6\x3d\x6f\x6a\xbd\xd6\x06\x0d\x1e\xe1\x5c\xc7\x49\xa6\x77\xf8\x21\xe1\x6f\x70\x0c\x27\xf0\x22\x66\x92\x47\x52\xd5\x4b\x78\x46\xe2\xff\x44\xc2\x7a\xb5\x87\xf3\xaf\x99\xb6\xbc\x61\xe2\x9a\xdc\x9b\xff\x7e\x3a\x33\xa5\xbf\xcc\xb2\x13\xd8\x36\xe2\xf7\x3b\x46\x6c\xe8\x2f\x50\xef\xd2\xff\xdf\x0e\xfd\x3a\xf5\x11\xaa\x4c\x01\x4f\x76\x20\x12\x12\xcf\x93\xad\x38\x88\xc6\xe5\x6e\x86\xa5\xc1\xf8\x81\x64\xfb\x72\x13\xc3\x0f\x65\x8b\x7f\x2b\xd9\xee\xed\xca\xa8\xf7\xda\xec\xbb\x06\x60\xd1\x5b\x89\xb7\x34\x59\x1d\x39\x16\x49\xfd\xa9\x59\x09\x9d\xe0\x10\x3e\x62\x90\xa8\x11\x39\xb9\xc4\x7e\x96\xda\x11\x6e\xf1\xa8\x27\x8d\x93\x09\x43\x4c\x70\xdb\x69\x11\x72\x51\xd1\x64\x92\x95\xfa\xa6\x82\x85\x70\x90\x56\x5a\xe4\x32\x71\x41\x1e\xf7\xb2\x16\x17\xc2\xb2\x58\x8b\xbf\x97\xe8\x68\xcc\x21\x20\x8b\xc4\x97\x42\xa9\x0a\x16\x92\x66\x15\xe2\xee\xbd\x7c\x75\x7c\x0c\xce\xcb\x02\x75\x3a\x80\x1f\x5e\x8d\x7e\xf8\x1e\x6c\xa9\xb0\x3f\xec\xb6\xd2\x78\x73\xd4\xe8\x0d\x5a\x88\xe8\x79\x8d\x85\x5f\xf6\xfa\xf0\xd3\x03\xf5\xe0\x81\xe4\xbe\x97\x16\x9e\xc3\x8b\x2f\x43\xd2\x6b\xbc\x81\xdb\xe0\x49\x40\xe5\x30\x4a\xa3\xf9\xee\xf2\xf5\x65\xef\x46\x58\xa1\xc4\x1c\xfb\x27\x3c\xef\xb1\xad\x56\x22\x36\xfc\xe4\x14\x28\x94\x90\x1a\x44\x92\x98\x52\x7b\x32\x7c\xdd\xbb\xab\x8a\xf2\xfb\x91\xaf\xe5\xf1\x68\x24\x92\x04\x9d\xab\xd3\x3d\x7b\x8d\xd4\x11\x39\x71\x83\xd4\x4e\xa6\xd8\xf2\x0a\x65\x07\xc3\xa9\x39\x52\xd0\xe4\x58\x0b\xcc\x8d\xa3\x4d\xe6\x08\x2b\x4b\x73\x86\x93\x3a\xe1\x41\x3b\x45\xb2\xb6\x03\xa3\x41\x80\x32\x3c\xdd\x73\x8c\x83\xb0\x0b\x37\x0c\xf9\x9e\xb6\xa5\x9c\xa3\xcd\x6a\xb8\x09\xe4\x36\x54\xb9\xa3\xdf\x6a\x07\x34\xe0\x9d\x74\x9e\x1b\x48\xd2\x52\x3a\x08\x48\x96\x7a\x31\x80\xc2\x14\x9c\xa7\x0f\xec\x25\xaf\xa6\xbf\x4e\xaf\x9a\xe2\x7f\xb8\x13\xeb\x16\xff\x69\x33\x01\x81\xa5\xf1\xc2\x63\xfa\x74\x4f\xcf\xbe\x07\x50\xe3\x07\x00\x45\xf2\xd7\xb5\xf1\x7d\xeb\x38\x4a\x38\xbf\x76\xcc\x02\xc3\xf8\xd2\x56\xc0\x95\xca\xbb\xad\xdc\xbd\x9d\x1c\x4c\x51\x57\x08\x52\x8a\xd3\x0e\x25\xf6\x3d\x9d\x75\x34\xb8\x6f\x03\x4f\x40\xa0\x69\x25\x00\x5e\xaf\x3b\x34\x11\x72\x3e\x6b\x68\x4a\x4f\x4e\xa7\x2a\xbd\x4e\x71\x0b\xe1\x3e\x38\xf6\x6d\x4c\x72\x73\xb9\x38\xd7\xbe\x57\x2f\x9e\x6b\x78\x0e\xf5\x03\xa5\x6e\x78\xbe\x11\x2b\x7b\x72\x60\x27\x45\x85\x1e\x61\x2d\xe2\x14\xb6\x5e\x91\xa0\x70\x68\x36\x8d\x45\xbf\x5b\x82\x8f\xa3\x34\x32\xcb\x13\x8b\x7e\x88\xbf\x97\x42\xb9\xde\x71\xd3\x12\x84\x13\x78\xc3\x45\x6c\xdc\x94\xb1\xba\xce\x11\xcf\x46\x93\x11\x05\x06\xb6\x68\x8d\x9a\x2d\x9d\x87\xda\x94\xe2\xa3\x12\xa2\x88\x98\x1c\x1a\x8f\x45\xf8\xed\xeb\x32\x3b\x6d\x02\x78\xda\x94\xfd\x4c\x48\x55\x5a\x7c\x7a\x0a\x7b\x92\x8b\x2b\x6d\x26\x12\xf6\xa5\x43\xe0\x11\xd4\x81\x33\x39\x2e\xcd\x2a\x28\xb0\x2f\x45\xed\x82\xa3\xc1\xc1\x56\x91\xe0\xbb\x14\xe1\xa0\x74\x62\x81\x2d\x70\x34\x06\xaf\x1d\xb5\x77\x2e\xfe\xd3\xd0\x79\xd6\x3c\x7e\x03\x45\x61\x97\x6f\x42\xe3\x31\x6c\xec\xf5\xf2\x4e\x2f\x53\x13\x71\x47\xd3\x7a\xa8\x55\x0d\x0d\x47\x83\x9c\x7f\xc5\xef\xff\x19\xc7\x07\xcf\xc7\xdf\x43\x03\x6d\x9b\x36\x9c\x71\x93\x38\x9c\x74\xdd\xc4\x7c\x1b\x05\xcd\xea\x43\x00\x78\xa8\x3f\x22\xa8\xea\xdf\x30\xf1\x6b\xb8\x72\x4b\x43\x4f\x85\xc5\x5b\x69\x4a\xaa\x56\xf8\xbf\x34\xff\x35\xfd\xdd\x7d\xb7\x73\x1f\xef\xbc\xd8\x7d\xed\x4b\xaf\xd5\x32\xde\xd9\x86\xd6\xa8\x55\x2b\x0c\x17\xd2\x78\x15\x96\x85\xdb\xd4\x0e\xf3\x3f\x72\xf9\x15\xe3\xdd\x9b\x82\x6a\x7f\x2c\x45\xca\xa2\x48\xab\xa6\xfa\x0d\x42\xd7\x01\x4b\xa1\xd3\x38\x79\x88\x34\x95\x24\x8f\xb1\x48\x1a\x8a\x85\x90\xba\xbb\xd7\x8c\xdf\x2c\xb9\xfb\x90\xb1\xd3\xc8\xb6\xab\x66\x9c\x18\x69\xbc\x63\x8d\xbb\x07\x54\xc7\xad\x58\xda\xbe\xc7\x8b\x57\x81\x46\xbb\x32\xe7\xb6\x17\xc4\xad\x90\x4a\xd0\xa8\xc5\xed\x94\x4e\x21\x51\x28\x74\xb8\xbd\xc7\xcc\x9b\x5b\xb4\xae\x7b\x00\xc8\xff\x0c\xc6\xb7\x92\x63\xfd\x18\xcd\x71\x78\xcc\x1e\x1a\xb1\xe1\xf8\x6f\x94\xf0\x3e\xc2\xab\x65\xde\x10\x59\xd2\xf3\x87\x1d\xd4\xbe\x7b\x58\x48\x71\x83\x44\x34\x3f\xc1\x71\xab\x09\xff\xab\x04\xd9\x2e\xc4\x2e\x9a\x66\x2c\x1e\xde\x1b\x33\x00\x85\x82\x47\xa2\xfa\xb3\x4b\xdd\x7c\x3e\x36\xa1\xd5\xd1\x1b\xda\xb7\x9d\xf0\xe5\x4b\xac\x25\xd6\xd7\x1d\xa1\x8f\x9f\x23\x6a\x90\x1e\xad\xa0\xe1\x87\xd0\x15\xbf\x14\x90\x96\x8e\xc5\xb1\x5f\x24\x05\x5d\x14\x1c\xaf\xed\xa9\x3e\x4b\xbd\x18\x76\x3b\xe1\x7d\x2b\xde\x13\x7f\xb7\x8e\xf7\x50\x0c\x99\x33\x5e\x00\x34\xf3\x7f\xe2\xef\xb8\x67\xe4\x19\x79\xeb\x12\x80\xd6\xe8\x55\x18\xa0\xb7\x46\x7e\x66\x8c\x63\xff\xf6\xcd\x22\xad\xf1\xbb\x0d\x80\x33\xe9\x42\xb8\x20\x66\x2b\x24\xfc\xdd\x6e\x44\xd4\x0c\x14\x0c\x27\xfb\x19\x68\x69\x0f\xd3\xd6\x35\x04\x11\xf3\xab\xb0\x1a\x0a\xfb\x49\x7b\x35\xbc\x8a\x07\x95\x79\xcb\x36\x32\x67\xdb\xdc\x9f\xee\x4f\x72\xc7\x35\x1e\xf7\x27\x33\xb2\x79\x03\xd8\x07\x58\xdb\x83\xc5\x2e\xc9\x63\xa9\x92\xa5\xd7\x99\xed\x01\x56\x96\xde\x6a\x3d\xfc\xdd\xe1\x22\x1b\xe2\xb6\x8a\x1b\x34\xfb\x84\xc4\x3c\x13\xe9\x82\x65\x6b\x01\x01\xd5\x41\x57\x46\xb4\xfc\x07\x46\x89\xed\xf8\xa9\x97\xc0\x62\xf8\xb0\xc0\x0d\x29\x85\x8f\x99\x73\xf1\x2f\x1d\xcd\x8c\xeb\xb8\x48\xd1\x49\x8b\x29\x64\x12\x55\x0a\x26\x45\xcb\x13\xe9\x6f\xce\xe8\xf0\x09\x09\xad\x24\x89\xe1\x53\x59\xf8\x6a\xcd\x1f\xf0\xb4\x4c\xd0\x57\x90\xa1\xe0\x6f\x41\xde\x40\x21\x9c\x83\x1c\x05\xcd\xa0\x59\xa9\x54\x05\xc6\xa6\x48\xc2\x9b\xa1\x8c\x42\xd2\x40\xe9\xd0\x3a\x58\x2d\x4d\x2c\x93\xdc\xa5\x15\xd4\x74\x4a\x3f\x88\xf7\x2e\xd2\x15\x4a\x54\x20\x3d\x95\xe4\x78\xa8\x76\x94\x36\x1f\x60\xf8\x2b\x8e\xa1\xaa\xbb\x1b\xa2\xf5\x5c\xb7\x19\xa3\xfc\x9a\x9e\x36\xa3\x33\xce\x35\x9b\x71\xb9\xbe\x91\xda\x0c\xc2\xba\x6c\x6c\x46\x5a\xbb\x08\x6d\x86\x13\xaf\xf0\xd3\x66\x20\xb5\xfa\x65\x5e\x60\x70\x34\x0c\xfc\xb4\x15\x5a\xac\x65\x8c\xad\xf0\xb9\xb1\x21\xe7\xa7\x41\x04\x0c\x79\xb1\x47\xc6\xb9\xc1\x8a\x32\x71\xb0\x51\xab\xac\x84\x17\x9f\x6f\xb0\xfa\xb2\xbf\x8a\x44\x38\xb6\xe8\x9a\xb2\x51\x43\x3a\xac\x3d\x12\xc8\x8d\x16\x72\x7c\x7c\x0a\xf2\xc7\x36\x43\x5d\xf9\x40\x3e\x7b\x56\xef\xd9\x5e\xff\x2c\xbf\xd4\xd1\xd9\x20\x7e\x6b\xbd\xbf\xa1\x51\x8c\x91\x40\x43\x41\xd1\xbd\xef\xfe\x33\x00\x00\xff\xff\xb5\x25\x8b\x4d\x94\x21\x00\x00")
+var _call_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xd4\x59\xdf\x73\xdb\x36\xf2\x7f\x96\xfe\x8a\x4d\x1e\x6a\x69\xa2\x50\x8e\xd3\x6f\xbf\x33\x72\xd5\x1b\x9d\xa3\xa4\x9a\x71\xe3\x8c\xad\x34\x93\xf1\xf8\x01\x22\x97\x12\x6a\x10\x60\x01\x50\x32\x9b\xfa\x7f\xbf\x59\x10\xa0\x48\x49\x76\x9c\xde\xdc\x4d\xef\x8d\x04\xb0\x8b\xc5\xee\x67\x7f\x01\xc3\x21\x9c\xa9\xbc\xd4\x7c\xb9\xb2\x70\x72\xfc\xea\xff\x61\xbe\x42\x48\x94\x81\x49\x61\x57\x4a\x9b\xee\x70\x08\xf3\x15\x37\x90\x72\x81\xc0\x0d\xe4\x4c\x5b\x50\x29\x58\xbf\x4e\xf0\x85\x66\xba\x8c\xba\xc3\x61\xb5\xb6\x35\x4c\x14\xa9\x46\x04\xa3\x52\xbb\x61\x1a\x47\x50\xaa\x02\x62\x26\x41\x63\xc2\x8d\xd5\x7c\x51\x58\x04\x6e\x81\xc9\x64\xa8\x34\x64\x2a\xe1\x69\x49\xac\xb8\x85\x42\x26\xa8\xdd\x56\x16\x75\x66\xc2\xbe\xef\xde\x7f\x84\x73\x34\x06\x35\xbc\x43\x89\x9a\x09\xf8\x50\x2c\x04\x8f\xe1\x9c\xc7\x28\x0d\x02\x33\x90\xd3\x88\x59\x61\x02\x0b\xc7\x8e\x08\xdf\x92\x28\x57\x5e\x14\x78\xab\x0a\x99\x30\xcb\x95\x1c\x00\x72\xbb\x42\x0d\x6b\xd4\x86\x2b\x09\xaf\xc3\x56\x9e\xe1\x00\x94\x26\x26\x3d\x66\xe9\x00\x1a\x54\x4e\x74\x7d\x60\xb2\x04\xc1\xec\x96\xf4\x11\x45\x6c\xcf\x9b\x00\x97\xee\x58\x2b\x95\x23\xd8\x15\xb3\xa4\x81\x0d\x17\x02\x16\x08\x85\xc1\xb4\x10\x03\xe2\xb2\x28\x2c\x7c\x9a\xcd\x7f\xbe\xf8\x38\x87\xc9\xfb\xcf\xf0\x69\x72\x79\x39\x79\x3f\xff\x7c\x0a\x1b\x6e\x57\xaa\xb0\x80\x6b\xac\x58\xf1\x2c\x17\x1c\x13\xd8\x30\xad\x99\xb4\x25\xa8\x94\x38\xfc\x32\xbd\x3c\xfb\x79\xf2\x7e\x3e\xf9\xe7\xec\x7c\x36\xff\x0c\x4a\xc3\xdb\xd9\xfc\xfd\xf4\xea\x0a\xde\x5e\x5c\xc2\x04\x3e\x4c\x2e\xe7\xb3\xb3\x8f\xe7\x93\x4b\xf8\xf0\xf1\xf2\xc3\xc5\xd5\x34\x82\x2b\x24\xa9\x90\xe8\xbf\xae\xeb\xd4\x59\x4d\x23\x24\x68\x19\x17\x26\x68\xe0\xb3\x2a\xc0\xac\x54\x21\x12\x58\xb1\x35\x82\xc6\x18\xf9\x1a\x13\x60\x10\xab\xbc\x7c\xb2\x31\x89\x17\x13\x4a\x2e\xdd\x99\xf7\x80\x07\xb3\x14\xa4\xb2\x03\x30\x88\xf0\xe3\xca\xda\x7c\x34\x1c\x6e\x36\x9b\x68\x29\x8b\x48\xe9\xe5\x50\x54\x6c\xcc\xf0\xa7\xa8\x4b\xbc\x62\x26\xc4\x5c\xb3\x18\x35\xa1\x93\x41\x5a\x90\xda\x85\xda\x48\xb0\x9a\x49\xc3\x62\x32\x2d\x7d\xd3\x12\x67\x1c\xbc\xa3\x3f\x6b\x08\xa4\xa0\x31\x57\x9a\xbe\x85\x08\xb8\xe2\xd2\xa2\x96\x4c\x38\xde\x06\x32\x96\x20\x2c\x4a\x60\x4d\x86\x83\xe6\x21\x08\x36\x95\x99\x81\xcb\x54\xe9\xcc\xc1\x30\xea\x7e\xe9\x76\xbc\x84\xc6\xb2\xf8\x96\x04\x24\xfe\x71\xa1\x35\x4a\x4b\x2a\x2c\xb4\xe1\x6b\x74\x4b\xa0\x5a\xe3\xf5\x38\xfd\xf5\x17\xc0\x3b\x8c\x8b\x8a\x53\xa7\x66\x32\x82\xeb\x2f\xf7\x37\x83\xae\x63\x9d\xa0\x89\x51\x26\x98\x90\x68\xf1\xad\x81\xcd\x0a\x1d\xf2\x37\x78\xb4\x46\xf8\xad\x30\xb6\xb1\x26\xd5\x2a\x03\x26\x41\x15\x84\xf0\xa6\x76\xb8\xb4\xca\x31\x64\xf4\x2d\x51\x3b\x89\xa2\x6e\xa7\x26\x1e\x41\xca\x84\x41\xbf\xaf\xb1\x98\xd3\x69\xb8\x5c\xab\x5b\x4c\x1c\x68\x70\x8d\xba\x04\x95\xc7\x2a\xf1\x4e\x40\x67\xad\x8f\x81\x26\xea\x76\x88\x6e\x04\x69\x21\xdd\xb6\x3d\xa1\x96\x03\x48\x16\x7d\xf8\xd2\xed\xd0\xee\x67\x2c\xb7\x85\x46\xe7\x86\xa8\xb5\xd2\x06\x78\x96\x61\xc2\x99\x45\x51\x76\x3b\x9d\x35\xd3\xd5\x04\x8c\x41\xa8\x65\xb4\x44\x3b\xa5\xdf\x5e\xff\xb4\xdb\xe9\xf0\x14\x7a\xd5\xec\xb3\xf1\xd8\x45\x9b\x94\x4b\x4c\x2a\xf6\x1d\xbb\xe2\x26\x4a\x59\x21\x6c\xbd\x2f\x11\x75\x34\xda\x42\x4b\xfa\xbc\xaf\xa4\xf8\x84\xa0\xa4\x28\x21\xa6\xa8\xc2\x16\xe4\x96\xa6\x34\x16\x33\x7f\x38\x33\x80\x94\x19\x52\x21\x4f\x61\x83\x90\x6b\x7c\x19\xaf\x30\xbe\x05\x25\x63\xf4\x52\x9a\xd2\x90\x0a\x61\x0c\xb4\x5b\xa4\xf2\xc8\xaa\xf7\x45\xb6\x40\xdd\xeb\xc3\x77\x70\x7c\x97\x1e\xf7\x61\x3c\x76\x1f\x41\x76\x4f\xe3\xe5\xa5\xb3\xaa\xdc\x1f\xd4\xd1\x5f\x59\xcd\xe5\xb2\xd7\x6f\xc8\x3a\x4b\x81\x81\xc4\x0d\xc4\x4a\x12\x04\x2c\x59\x65\x81\x5c\x2e\x21\xd6\xc8\x2c\x26\x03\x60\x49\x02\x56\x39\x54\x6d\x71\xd6\xde\x12\xbe\xfb\x0e\x7a\xb4\xd9\x18\x8e\xce\x2e\xa7\x93\xf9\xf4\x08\xfe\xfc\x13\x5a\x23\x27\x47\xfd\x86\x64\x5c\x5e\xa4\xa9\x17\xce\xe1\x32\xca\x11\x6f\x7b\xaf\xfa\xd1\x9a\x89\x02\x2f\xd2\x4a\x4c\xbf\x76\x2a\x13\x18\x7b\x9a\x17\xbb\x34\x27\x2d\x1a\x32\xc9\x70\x08\x13\x63\x30\x5b\x08\xdc\x77\x48\xef\xb1\xce\x79\x8d\x55\xba\x0a\x59\xb1\xca\x72\x81\x84\xaa\xb0\xab\x57\xbf\x93\xb8\x63\xcb\x1c\x47\x00\x00\x2a\x1f\xb8\x01\xf2\x05\x37\x60\xd5\xcf\x78\xe7\x6c\x14\x54\x48\xa8\x9a\x24\x89\x46\x63\x7a\xfd\x7e\xb5\x9c\xcb\xbc\xb0\xa3\xd6\xf2\x0c\x33\xa5\xcb\xc8\x50\x40\xea\xb9\xa3\x0d\xaa\x93\x06\x9a\x25\x33\x33\x49\x34\x1e\xa9\xef\x98\xe9\x6d\xa7\xce\x94\xb1\xa3\x30\x45\x3f\x61\xce\xe9\x82\xc8\x8e\x8e\xef\x8e\xf6\xb5\x75\xdc\xdf\x22\xe1\xd5\x0f\x7d\x62\x77\x7f\x5a\xe3\xbb\x0e\x13\x51\x5e\x98\x55\x8f\x7e\xfb\xdb\xd9\x6d\x28\x18\x83\xd5\x05\x1e\x84\xbf\x83\xd4\x3e\x9c\x0c\x8a\x94\x62\x89\xd5\x45\xec\x60\xb5\x64\x2e\xd2\x38\x4f\x67\x14\x79\x4d\xb1\xa0\xfd\xc0\x2a\xb5\x8f\x2e\x0f\xa5\xab\xe9\xf9\xdb\x37\xd3\xab\xf9\xe5\xc7\xb3\xf9\x51\x03\x4e\x02\x53\x0b\x63\xd8\x39\x83\x40\xb9\xb4\x2b\x27\x3f\xf9\x47\x7b\xf6\x9a\x68\x5e\xbe\xba\xa9\x46\x60\x7c\xc0\xe5\x3b\x8f\x53\xc0\xf5\x8d\xe3\x7d\xdf\xfd\xca\xd2\x4a\x99\x5f\x2a\x10\xa9\xfc\xbe\x19\x38\x0e\xf8\x62\x86\x76\xa5\xa8\x28\x58\xab\xd8\x65\x82\xad\x16\x13\x25\xf1\xdb\x3d\x72\x72\x7e\xde\xf2\xc7\xc9\xf9\xf9\xd9\xc5\x9b\x96\x8f\xbe\x99\x9e\x4f\xdf\x4d\xe6\xd3\xdd\xb5\x57\xf3\xc9\x7c\x76\xe6\x46\x83\xfb\x0e\x87\x70\x75\xcb\x73\x17\x65\x5d\xec\x52\x59\xee\xca\xc1\x5a\x5e\x33\x00\xbb\x52\x54\x78\x69\x9f\x44\x52\x26\xe3\x10\xdc\x4d\x30\x9a\x55\x64\x32\x15\x7c\x65\x07\xa8\xaf\xda\x40\xed\xd7\x66\xe4\xe6\x83\x46\xf2\x57\x2e\x30\xe9\x59\x15\xe4\xda\x2a\xd4\x69\xd4\xe1\x42\xb9\x20\xd3\x7b\xfa\x21\xe1\x1f\x70\x0c\x23\x78\xe5\x23\xc9\x23\xa1\xea\x04\x5e\x80\x4a\xd3\xbf\x10\xb0\x5e\x1f\xa0\xfc\x7b\x86\x2d\xab\x1c\x75\x58\x6e\xd5\x7f\x3f\x9c\xa9\xc2\x5e\xa4\xe9\x08\x76\x95\xf8\xfd\x9e\x12\xeb\xf5\xe7\x28\xf7\xd7\xff\xdf\xde\xfa\x6d\xe8\x23\x54\xa9\x1c\x9e\xed\x41\xa4\x0a\x3c\xcf\x76\xfc\xc0\x2b\x97\x5c\xbb\x32\x3e\x8c\x1f\x08\xb6\x27\x6d\x0c\x3f\x14\x2d\xfe\xad\x60\x7b\xb0\x54\xa3\x82\xac\x5d\x8c\x0d\x40\xa3\xd5\x1c\xd7\xd4\x5e\x1d\x19\xc7\x92\x8a\x56\xb5\x61\x32\xc6\x08\x3e\xd1\x06\xc3\x21\x48\xa4\x6a\x50\x85\x22\x17\x78\x0a\x94\xeb\x5c\xa1\xea\xdb\x14\x62\x47\x3d\x15\xc5\x6f\x84\x8c\x95\xd4\xa6\xa4\x85\xbc\x2d\x61\xc9\x0c\x24\xa5\x64\x19\x8f\xc9\xcd\x87\x43\x47\x07\x1a\x97\x4c\x3b\xb6\x1a\x7f\x2f\xd0\x50\xcf\x43\xf9\x97\xc5\xb6\x60\x42\x94\xb0\xe4\xd4\xb8\x10\x75\xef\xe4\xf5\xf1\x31\x18\xcb\x73\x94\xc9\x00\x7e\x78\x3d\xfc\xe1\x7b\xd0\x85\xc0\x7e\xe4\x23\x5c\x5b\x3b\xde\x1a\x64\x42\x8f\x9e\x37\x98\xdb\x55\xaf\x0f\x3f\x3d\x90\x0f\x82\xfd\xda\x93\xd7\x07\xd7\xc2\x4b\x78\x75\x13\x91\x5c\x75\xc1\xe8\xd2\x70\x65\x49\x40\x61\xd0\x73\xa3\x6e\xf7\xe2\xcd\x45\xef\x96\x69\x26\xd8\x02\xfb\x23\xd7\x3c\x3b\x5d\x6d\x98\xef\x02\xc8\x28\x90\x0b\xc6\x25\xb0\x38\x56\x85\xb4\xa4\xf8\x50\xd0\x8b\x12\x12\x25\x8f\x6c\xe0\xe7\xfa\x24\x16\xc7\x68\x4c\x08\xf7\xce\x6a\x24\x0e\xcb\x88\x1a\xb8\x34\x9c\xf8\x86\x9d\x48\xa9\x46\xb9\xd0\xec\x57\x50\x1b\x19\x18\x66\xca\x58\xe1\xac\xb5\xd1\xd4\x41\x19\x2e\x63\x82\x03\x24\x48\xda\x36\xa0\x24\x30\x10\xca\xb5\xf4\xae\x64\x01\xa6\x97\x26\xaa\xe2\x3d\x6d\x4b\xa5\x92\x54\x9b\xa8\x0d\xe4\x2d\xee\xc6\x55\x99\xbf\x53\x0e\x48\xc0\x3b\x6e\x2c\x25\x30\xa7\x0f\x6e\x08\x8c\x85\x96\x5c\x2e\x07\x90\xab\x9c\x3c\xf3\xab\xe9\xcc\x07\xeb\xcb\xe9\xaf\xd3\xcb\x3a\xf9\x3f\xdd\x88\xa1\xee\x7f\x5e\xb7\x45\xa0\xa9\xe7\xb0\x98\x3c\x3f\x50\xc8\x1f\x00\xd4\xf8\x01\x40\x11\x7f\x2f\xce\x70\x08\x1f\x1a\xc7\x11\xcc\xd8\xad\x61\x96\x68\xdd\x68\x53\x00\x53\x08\x6b\x76\x62\xf7\xce\x26\xb9\xca\x43\x86\x20\xa1\x88\x5d\x44\x81\x7d\xb7\xda\x3e\x34\x71\x52\x47\xab\xca\x14\xb5\x8e\x09\x92\x0c\xaa\x45\x8d\xd0\xe0\xe6\x43\xed\xc6\xaa\x6c\xe0\x52\x8e\x2a\x2c\xc1\x21\x56\x09\x6e\x83\xdf\x92\x99\x8f\x06\x93\x6d\xf8\x5b\xf0\xe5\x4c\xda\x5e\x98\x9c\x49\x78\x09\xe1\x87\x82\x3a\xbc\x6c\x79\xd1\x81\xe8\xd8\x49\x50\xa0\xc5\x9a\x6a\x26\x4f\x61\x67\x88\x18\x55\xea\x70\x4a\xd3\x68\xf7\x93\xf3\xb1\xe7\x46\x0a\x7b\xa6\xd1\x46\xf8\x7b\xc1\x84\xe9\x1d\xd7\xc5\x82\xeb\x88\x23\xab\x5c\x7a\x1b\xd7\x09\x2e\x64\x40\xa2\x69\x0a\xe7\xeb\x0f\x7f\x70\xaf\x8d\x40\x96\x2c\xe8\x48\x67\x2a\xc1\x47\x39\x78\x16\x3e\x6c\xd4\xb6\xf4\xc0\x3c\x54\x7f\x76\x9a\x0b\xe0\x79\x5d\x10\xa4\x8c\x8b\x42\xe3\xf3\x53\x38\x10\x76\x4c\xa1\x53\x16\xbb\xa0\x60\x10\x5c\xc7\x6a\xc0\xa8\x0c\x57\x6a\x53\x09\x70\x28\x78\xed\x83\xa3\xae\xe1\x77\xd2\x07\x61\x84\x62\x41\x61\xd8\x12\x1b\xe0\xa8\x15\x1e\x0c\x05\xcf\x1e\x3e\xd3\xb7\x43\xe7\x45\xfd\xfb\x15\x14\x75\x3b\x4f\x82\xc6\x63\xd8\x38\x68\xe5\xbd\x2a\x27\x2c\x72\xad\x5b\xe3\x27\x88\x5a\x95\x22\x35\x72\xbe\xc5\xee\xff\x19\xc3\x57\x96\xef\xdc\x7f\x93\xa3\xed\xae\xad\x0a\xb2\xf6\xe2\xea\xa4\xdb\xf2\xe6\xeb\x28\xa8\x67\x1f\x02\xc0\x81\xd8\x70\xef\x23\xec\x4c\xfe\x86\xb1\xdd\xc2\xd5\x15\x3b\xf4\x97\x6b\x5c\x73\x55\x50\x1e\xc3\xff\xa5\xce\xb0\xae\xfc\xee\xbb\x9d\x7b\x7f\x45\xe6\xfc\xb6\x79\x47\xb6\x59\xf9\xab\xdd\xaa\x68\xda\xde\xee\x51\xb2\xa6\x5b\x39\x77\xb9\xe4\x10\x42\x57\x65\x8e\xfe\x91\xbb\x32\xef\xef\x56\xe5\x99\xaa\x93\x94\xd0\xc8\x92\xb2\xce\x8b\x83\xaa\x1e\x81\x15\x93\x89\xef\x49\x58\x92\x70\xe2\xe7\x82\x10\x49\xc8\x96\x8c\x4b\x9f\x2f\x77\x4e\x7a\x50\xe7\xcd\x64\x7c\x08\x19\x7b\x25\x6e\x33\x9f\xfa\x5e\x92\x1a\x3f\x27\x71\xf7\x09\x79\x73\xc7\x97\x76\xaf\xfd\xfc\xcd\xa1\x92\xa6\xc8\x5c\x41\x0c\x6c\xcd\xb8\x60\xd4\x84\x51\xac\xa1\xf8\x16\x0b\x64\xd2\x15\x55\x64\x3c\x45\xcf\x02\xfe\xc4\x8f\x82\xfc\xaf\x60\x7c\x27\x38\x86\x5f\xaf\x8e\xa7\xfb\xec\x53\x3d\xb6\x3a\xfe\x5b\xc1\xac\xf5\xf0\x6a\xa8\xb7\xf2\x2c\x6e\xdd\x3b\x0f\x4a\xdb\x7d\x9a\x4b\x11\x14\xdc\x9a\x9f\xe0\xd8\xab\xe2\xef\xe4\x64\xfb\x10\x3b\xaf\xcb\x34\x7f\x78\xab\xd4\x00\x04\x52\xfd\xcd\x6d\x78\x9d\x09\x65\x69\x7b\xab\x36\xf3\xe0\xbd\x55\x61\xb7\xe7\xbe\xa4\x53\x62\xe5\x2f\x42\xaa\x97\x90\x05\xa2\x04\x6e\x51\xd3\x75\x2b\x10\xba\xfc\xc3\x02\x39\x82\x71\xc1\x80\x68\x52\x4e\x09\xc0\x33\xf6\xb7\xfc\x54\xa7\x71\xb9\x8c\xba\x9d\x6a\xbc\xe1\xef\xb1\xbd\xdb\xfa\x3b\x59\xcd\x53\xfa\xab\x81\xfa\x66\x20\xb6\x77\xae\x68\x1c\x74\xf7\xaf\x07\x68\x8e\x9a\xbf\xaa\x83\xdf\xb9\x0c\xa0\xc9\x70\x21\xb0\x7b\xe7\x48\x73\x6e\xac\x05\x70\xc7\x65\xc9\x4c\xc5\x66\xc7\x25\xec\xdd\xbe\x47\x04\x02\x72\x86\xd1\x61\x02\x9a\x3a\x40\xb4\x73\x41\x41\xf2\xb8\xa1\x4a\xdc\x2a\xb1\x8f\x9a\xb3\xd5\x90\x3f\x28\xcf\x1a\xba\xe1\x19\xd2\xe8\x7d\x40\xf6\x0e\xd2\x8e\x03\x1e\x0f\x07\x33\xd2\x79\x0d\xd8\x07\x48\x03\x12\x0f\x73\x7f\x2c\x54\x3a\xee\x21\xb2\x3d\x40\x7a\xda\x6d\x97\x1e\xf6\xee\xe9\x2c\xeb\xc5\x4d\x11\x5b\x6b\x0e\x31\xf1\x71\xc6\xaf\xab\x34\x1b\x18\x54\xbe\x57\xc5\x0e\x87\x68\xfe\x07\x7a\x8e\x4d\xff\x09\x53\xf4\xc6\xe5\xde\x21\x5c\x41\x4a\xee\xa3\x16\x2e\xf9\x17\x86\xba\xc9\xad\x5f\x24\x68\xb8\xa6\x97\x24\x8e\x22\x01\x45\x0f\xc5\xd4\xab\xfe\x66\xe8\x42\x9f\x5e\x9c\x50\x73\x26\xf8\x1f\xee\x7e\x32\xaa\x1e\xb1\xdd\x7b\x9f\xe4\x31\xda\x12\x52\x64\xee\xe9\xc8\x2a\xc8\x99\x31\x90\x21\xa3\xee\x94\x5e\x03\x4b\x50\x3a\x41\x62\x5e\xb7\x6b\xe4\x92\x8a\x5e\x66\x35\x3d\x99\x29\x9f\x26\x5d\x79\x9e\x53\xd1\xc9\xed\xc0\xdf\xc8\x70\x93\x0b\x56\x02\xb7\x94\x92\xfd\xa1\x9a\x5e\x5a\xbf\xd7\x90\x8b\x1a\xa5\x29\x04\xec\xb9\x68\x68\xec\xda\x3e\x4a\xd8\x71\xee\xd9\xf6\x4e\xdf\xd7\xb4\xfd\x72\x7b\x57\xd5\x76\xc2\x90\x36\xda\x9e\x16\x46\xe9\xaf\xed\x4e\x6e\xc6\x79\x52\xdb\x91\x42\x4e\x09\x13\x0e\x34\x35\x81\xfb\xdb\x71\x2d\x22\x08\xbe\xe5\x32\xb4\xa9\x97\xbb\xbf\x81\x07\x0c\x59\xb1\x47\xca\xb9\xc5\x92\x22\x71\xa5\x23\x8f\x34\x82\x63\x35\x70\x7d\x8b\xe5\xcd\xe1\x2c\xe2\xe1\xd8\x58\x57\xa7\x8d\x00\xe9\x6a\xee\x11\x47\xae\xa5\xe0\xe3\xe3\x53\xe0\x3f\x36\x09\x42\xe6\x03\xfe\xe2\x45\xd8\xb3\x39\x7f\xcd\x6f\x82\x77\x06\x04\xb4\x36\xbc\xe6\x37\xdb\xfa\xb6\xe1\x23\xd5\x9a\xd3\x6e\xe7\xbe\x7b\xdf\xfd\xd7\x00\x74\xf9\x44\x82\x9b\x21\x00\x00")
 
 func call_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -172,7 +172,7 @@ func opcount_tracerJs() (*asset, error) {
 	return a, nil
 }
 
-var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x57\xdd\x6f\x1b\xb9\x11\x7f\xde\xfd\x2b\xa6\x7e\x91\x84\x53\x56\xce\x15\xb8\x02\x72\x5d\x60\xa3\x28\x89\x00\x9d\x6d\x48\x4a\x5d\xf7\x70\x0f\x5c\x72\x76\xc5\x13\x45\x2e\x48\xae\x3e\x10\xf8\x7f\x2f\x86\xfb\x21\xcb\x67\x27\x6e\xeb\x27\x2f\x39\xfc\xcd\xf7\x6f\x46\xa3\x11\x4c\x4c\x79\xb4\xb2\x58\x7b\xf8\xf9\xf2\xfd\xdf\x60\xb5\x46\x28\xcc\x3b\xf4\x6b\xb4\x58\x6d\x21\xad\xfc\xda\x58\x17\x8f\x46\xb0\x5a\x4b\x07\xb9\x54\x08\xd2\x41\xc9\xac\x07\x93\x83\x7f\x26\xaf\x64\x66\x99\x3d\x26\xf1\x68\x54\xbf\x79\xf1\x9a\x10\x72\x8b\x08\xce\xe4\x7e\xcf\x2c\x8e\xe1\x68\x2a\xe0\x4c\x83\x45\x21\x9d\xb7\x32\xab\x3c\x82\xf4\xc0\xb4\x18\x19\x0b\x5b\x23\x64\x7e\x24\x48\xe9\xa1\xd2\x02\x6d\x50\xed\xd1\x6e\x5d\x6b\xc7\xe7\x9b\xaf\x30\x47\xe7\xd0\xc2\x67\xd4\x68\x99\x82\xbb\x2a\x53\x92\xc3\x5c\x72\xd4\x0e\x81\x39\x28\xe9\xc4\xad\x51\x40\x16\xe0\xe8\xe1\x27\x32\x65\xd9\x98\x02\x9f\x4c\xa5\x05\xf3\xd2\xe8\x21\xa0\x24\xcb\x61\x87\xd6\x49\xa3\xe1\xaf\xad\xaa\x06\x70\x08\xc6\x12\x48\x9f\x79\x72\xc0\x82\x29\xe9\xdd\x00\x98\x3e\x82\x62\xfe\xf4\xf4\x0d\x01\x39\xf9\x2d\x40\xea\xa0\x66\x6d\x4a\x04\xbf\x66\x9e\xbc\xde\x4b\xa5\x20\x43\xa8\x1c\xe6\x95\x1a\x12\x5a\x56\x79\xb8\x9f\xad\xbe\xdc\x7e\x5d\x41\x7a\xf3\x00\xf7\xe9\x62\x91\xde\xac\x1e\xae\x60\x2f\xfd\xda\x54\x1e\x70\x87\x35\x94\xdc\x96\x4a\xa2\x80\x3d\xb3\x96\x69\x7f\x04\x93\x13\xc2\xaf\xd3\xc5\xe4\x4b\x7a\xb3\x4a\x3f\xcc\xe6\xb3\xd5\x03\x18\x0b\x9f\x66\xab\x9b\xe9\x72\x09\x9f\x6e\x17\x90\xc2\x5d\xba\x58\xcd\x26\x5f\xe7\xe9\x02\xee\xbe\x2e\xee\x6e\x97\xd3\x04\x96\x48\x56\x21\xbd\xff\x71\xcc\xf3\x90\x3d\x8b\x20\xd0\x33\xa9\x5c\x1b\x89\x07\x53\x81\x5b\x9b\x4a\x09\x58\xb3\x1d\x82\x45\x8e\x72\x87\x02\x18\x70\x53\x1e\xdf\x9c\x54\xc2\x62\xca\xe8\x22\xf8\xfc\x6a\x41\xc2\x2c\x07\x6d\xfc\x10\x1c\x22\xfc\x7d\xed\x7d\x39\x1e\x8d\xf6\xfb\x7d\x52\xe8\x2a\x31\xb6\x18\xa9\x1a\xce\x8d\xfe\x91\xc4\x84\x59\x5a\x74\x9e\x79\x5c\x59\xc6\xd1\x82\xa9\x7c\x59\x79\x07\xae\xca\x73\xc9\x25\x6a\x0f\x52\xe7\xc6\x6e\x43\xa5\x80\x37\xc0\x2d\x32\x8f\xc0\x40\x19\xce\x14\xe0\x01\x79\x15\xee\xea\x48\x87\x72\xb5\x4c\x3b\xc6\xc3\x69\x6e\xcd\x96\x7c\xad\x9c\xa7\x7f\x9c\xc3\x6d\xa6\x50\x40\x81\x1a\x9d\x74\x90\x29\xc3\x37\x49\xfc\x2d\x8e\x9e\x18\x43\x75\x12\x3c\x6c\x84\x42\x6d\xec\xb1\x67\x11\xb2\x4a\x2a\x21\x75\x91\xc4\x51\x2b\x3d\x06\x5d\x29\x35\x8c\x03\x84\x32\x66\x53\x95\x29\xe7\xa6\x0a\xb6\xff\x81\xdc\xd7\x60\xae\x44\x2e\x73\x2a\x0e\xd6\xdd\x7a\x13\xae\x3a\xbd\x26\x23\xf9\x24\x8e\xce\x60\xc6\x90\x57\x3a\xb8\xd3\x67\x42\xd8\x21\x88\x6c\xf0\x2d\x8e\xa2\x1d\xb3\x84\x05\xd7\xe0\xcd\x17\x3c\x84\xcb\xc1\x55\x1c\x45\x32\x87\xbe\x5f\x4b\x97\xb4\xc0\xbf\x31\xce\x7f\x87\xeb\xeb\xeb\xd0\xd4\xb9\xd4\x28\x06\x40\x10\xd1\x4b\x62\xf5\x4d\x94\x31\xc5\x34\xc7\x31\xf4\x2e\x0f\x3d\xf8\x09\x44\x96\x14\xe8\x3f\xd4\xa7\xb5\xb2\xc4\x9b\xa5\xb7\x52\x17\xfd\xf7\xbf\x0c\x86\xe1\x95\x36\xe1\x0d\x34\xe2\x37\xa6\x13\xae\xef\xb9\x11\xe1\xba\xb1\xb9\x96\x9a\x18\xd1\x08\x35\x52\xce\x1b\xcb\x0a\x1c\xc3\xb7\x47\xfa\x7e\x24\xaf\x1e\xe3\xe8\xf1\x2c\xca\xcb\x5a\xe8\x95\x28\x37\x10\x80\xda\xdb\xae\xce\x0b\x49\x9d\xfa\x34\x01\x01\xef\x7b\x49\x58\xb6\xa6\x3c\x4b\xc2\x06\x8f\x3f\xce\x04\x5d\x48\x71\xe8\x2e\x36\x78\x1c\x5c\xc5\xaf\xa6\x28\x69\x8c\xfe\x4d\x8a\xc3\xcb\xf9\x22\xc0\x1d\x53\x1d\x60\x1d\xbf\x25\x21\x9c\xec\x1a\x04\xdd\x41\x07\xc9\xfe\xe5\x1a\x2e\x2e\x0f\x97\xff\xe7\xdf\x45\x63\xc1\x0b\x25\xf3\xcc\xec\x37\x98\xf6\x78\x9e\x4f\x8b\xae\x52\x9e\xda\x4e\xea\x9d\xd9\x10\x81\xae\x29\x4f\x4a\x85\xd4\x98\x92\xaa\xc6\xd5\x0c\x96\x21\x6a\x90\x1e\x2d\x23\x0a\x37\x3b\xb4\x34\xbd\xc0\xa2\xaf\xac\x76\x5d\x3a\x73\xa9\x99\x6a\x81\x9b\xec\x7b\xcb\x78\xdd\xbb\xf5\xf9\x93\x9c\x72\x7f\x08\xd9\x0c\x3e\x8e\x46\x90\x7a\x20\x3f\xa1\x34\x52\xfb\x21\xec\x11\x34\xa2\x20\x02\x12\x28\x2a\xee\x03\x5e\x6f\xc7\x54\x85\xbd\x9a\x64\x88\xaa\xc3\x53\x53\xd1\x44\x7a\x42\x42\xc3\x60\xe0\xd6\xec\xc2\xa8\xcd\x18\xdf\x40\xd3\xf8\xc6\xca\x42\xea\xb8\x89\xe9\x59\xd3\x93\x45\x09\x01\x07\xb3\x42\xcd\x50\xee\xe9\xe4\x43\xc8\x7f\x26\x8b\x99\xf6\xcf\x8a\xa8\x8e\x7c\xfb\x74\xf0\x7b\xd2\x34\x71\xe2\x88\x78\xfb\x3f\x0f\x86\xf0\xfe\x97\xae\x32\xbd\x21\x28\xf8\x31\x98\x37\xaf\x43\xc5\xcf\x2b\xe2\xe5\x67\x41\x0d\x31\xc9\x4f\x41\x6b\xe2\xaa\x8c\xd2\x51\xfb\x19\xe2\x78\xce\x26\x57\xdf\xc1\x3d\xf7\xad\xc5\x6d\x42\x93\x30\x21\x5e\x07\xad\x53\xf4\x11\xb9\xc5\x2d\x4d\x17\xca\x02\x67\x4a\xa1\xed\x39\x08\xdc\x35\x6c\xca\x29\xe4\x0b\xb7\xa5\x3f\xb6\x33\xc7\x33\x5b\xa0\x77\x3f\x36\x2c\xe0\xbc\x7b\xd7\x52\x71\x08\xc5\xb1\x44\xb8\xbe\x86\xde\x64\x31\x4d\x57\xd3\x5e\xd3\x4c\xa3\x11\xdc\x63\xd8\xc8\x32\x25\x33\xa1\x8e\x20\x50\xa1\xc7\xda\x2e\xa3\x43\x88\x3a\x6a\x1a\xd2\x6a\x45\x4b\x0f\x1e\xa4\xf3\x52\x17\x50\x33\xd6\x9e\xe6\x7b\x03\x17\x7a\x84\xb3\xca\x51\xb5\x3e\x1b\x86\xde\xd0\x66\x63\x91\xf8\x8d\xe6\x50\x68\x37\xa6\x64\xb7\x09\xe5\xd2\x3a\x0f\xa5\x62\x1c\x13\xc2\xeb\x8c\x79\x3d\xbf\x0d\x33\x93\xea\x45\x68\xc1\x00\x74\x1a\xb4\x4c\xd1\xa0\x26\xf5\x0e\xfa\x2d\xc6\x20\x8e\x22\xdb\x4a\x3f\xc1\xbe\x3a\x51\x82\xf3\x58\x3e\x25\x04\x5a\x70\x70\x87\x44\xe5\x81\x0d\xea\xa1\x4c\xba\xfe\xf9\x6b\xb3\x05\xa0\x4b\xe2\x88\xde\x3d\xe9\x6b\x65\x8a\xf3\xbe\x16\x75\x58\x78\x65\x2d\xe5\xbf\x1b\x05\x39\xf5\xf8\x1f\x95\xf3\x14\x53\x4b\xe1\x69\xd8\xe2\x25\xb2\x0e\xd4\x4c\x53\x7f\xf0\xe7\x21\x4a\xf3\x33\xcc\x2b\x52\xd7\x4c\xcb\x7a\xab\x2c\x8d\x47\xed\x25\x53\xea\x48\x79\xd8\x5b\x5a\xa7\x68\x81\x1a\x82\x93\x24\x15\x18\x27\x88\x4a\xcd\x55\x25\xea\x32\x08\x75\xdc\xe0\xb9\x60\xf3\xf9\x1e\xb6\x45\xe7\x58\x81\x09\x55\x52\x2e\x0f\xcd\x26\xab\xa1\x57\x93\x5c\x7f\xd0\x4b\x3a\x23\xcf\x29\x46\x99\x22\x69\x8b\x8c\xb8\x3a\x15\xc2\xa2\x73\xfd\x41\xc3\x39\x5d\x66\xef\xd7\xa8\x29\xf8\xa0\x71\x0f\xdd\x8a\xc4\x38\xa7\x95\x51\x0c\x81\x09\x41\xd4\xf6\x6c\x9d\x89\xa3\xc8\xed\xa5\xe7\x6b\x08\x9a\x4c\x79\xea\xc5\x41\x53\xff\x9c\x39\x84\x8b\xe9\xbf\x56\x93\xdb\x8f\xd3\xc9\xed\xdd\xc3\xc5\x18\xce\xce\x96\xb3\x7f\x4f\xbb\xb3\x0f\xe9\x3c\xbd\x99\x4c\x2f\xc6\xa7\x39\x74\xee\x90\x37\xad\x0b\xa4\xd0\x79\xc6\x37\x49\x89\xb8\xe9\x5f\x9e\xf3\xc0\xc9\xc1\x28\xca\x2c\xb2\xcd\xd5\xc9\x98\xba\x41\x1b\x1d\x2d\xe5\xc2\x35\xbc\x1a\xac\xab\xd7\xad\x99\x34\xf2\xfd\x96\xc8\x4f\x2b\x51\xa0\x8a\xef\xda\x91\xce\xe7\x9d\xe7\xf4\x41\xe1\xe8\x0e\x3e\x4e\xe7\xd3\xcf\xe9\x6a\x7a\x26\xb5\x5c\xa5\xab\xd9\xa4\x3e\xfa\xaf\x43\xf4\xfe\xcd\x21\xea\x2d\x97\xab\xdb\xc5\xb4\x37\x6e\xbe\xe6\xb7\xe9\xc7\xde\x9f\x14\x36\x7b\xd3\xf7\x8a\xcc\x9b\x7b\x63\xc5\xff\x92\xab\x27\xbb\x43\xce\x5e\x5a\x1d\x02\x09\x71\x5f\x3d\xfb\x89\x00\x4c\xb7\xfc\x91\xd7\x3f\x93\xa2\xf0\xfe\x45\xc6\x78\x8c\x1f\xe3\xff\x04\x00\x00\xff\xff\xb5\x44\x89\xaf\xbc\x0f\x00\x00")
+var _prestate_tracerJs = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x57\x4b\x6f\xe3\x38\x12\x3e\x4b\xbf\xa2\xb6\x2f\xb6\xd1\x1e\x39\xc9\x00\xb3\x80\xb3\x59\x40\xed\x76\x77\x1b\xf0\x24\x81\xed\xde\x6c\x76\x30\x07\x8a\x2c\xd9\x1c\xd3\xa4\x40\x52\x7e\x4c\x23\xff\x7d\x51\x94\xe4\x47\xde\xbb\x93\x53\x4c\x16\xbf\x7a\x7f\x55\xea\xf5\x60\x60\x8a\x9d\x95\xf3\x85\x87\x8b\xb3\xf3\xbf\xc3\x6c\x81\x20\x8c\x83\xb4\xf4\x0b\x63\x5d\xdc\xeb\xc1\x6c\x21\x1d\xe4\x52\x21\x48\x07\x05\xb3\x1e\x4c\x0e\xbe\x96\x53\x32\xb3\xcc\xee\x92\xb8\xd7\xab\x64\x4f\x8e\xe9\x45\x6e\x11\xc1\x99\xdc\x6f\x98\xc5\x3e\xec\x4c\x09\x9c\x69\xb0\x28\xa4\xf3\x56\x66\xa5\x47\x90\x1e\x98\x16\x3d\x63\x61\x65\x84\xcc\x77\x04\x25\x3d\x94\x5a\xa0\x0d\xaa\x3c\xda\x95\x6b\xf4\x7e\xbd\xfe\x0e\x63\x74\x0e\x2d\x7c\x45\x8d\x96\x29\xb8\x2d\x33\x25\x39\x8c\x25\x47\xed\x10\x98\x83\x82\x4e\xdc\x02\x05\x64\x01\x8e\x1e\x7e\x21\x53\xa6\xb5\x29\xf0\xc5\x94\x5a\x30\x2f\x8d\xee\x02\x4a\xbf\x40\x0b\x6b\xb4\x4e\x1a\x0d\x3f\x37\xaa\x6a\xc0\x2e\x18\x4b\x20\x6d\xe6\xc9\x01\x0b\xa6\xa0\x77\x1d\x60\x7a\x07\x8a\xf9\xc3\xd3\x57\x02\x71\xf0\x57\x80\xd4\xc1\xad\x85\x29\x10\xfc\x82\x79\x8a\xc0\x46\x2a\x05\x19\x42\xe9\x30\x2f\x55\x97\x50\xb2\xd2\xc3\xdd\x68\xf6\xed\xe6\xfb\x0c\xd2\xeb\x7b\xb8\x4b\x27\x93\xf4\x7a\x76\x7f\x09\x1b\xe9\x17\xa6\xf4\x80\x6b\xac\xa0\xe4\xaa\x50\x12\x05\x6c\x98\xb5\x4c\xfb\x1d\x98\x9c\x10\x7e\x1d\x4e\x06\xdf\xd2\xeb\x59\xfa\x69\x34\x1e\xcd\xee\xc1\x58\xf8\x32\x9a\x5d\x0f\xa7\x53\xf8\x72\x33\x81\x14\x6e\xd3\xc9\x6c\x34\xf8\x3e\x4e\x27\x70\xfb\x7d\x72\x7b\x33\x1d\x26\x30\x45\xb2\x0a\xe9\xfd\xdb\xb1\xce\x43\xd6\x2c\x82\x40\xcf\xa4\x72\x4d\x04\xee\x4d\x09\x6e\x61\x4a\x25\x60\xc1\xd6\x08\x16\x39\xca\x35\x0a\x60\xc0\x4d\xb1\x7b\x77\x32\x09\x8b\x29\xa3\xe7\xc1\xe7\x27\x85\x07\xa3\x1c\xb4\xf1\x5d\x70\x88\xf0\x8f\x85\xf7\x45\xbf\xd7\xdb\x6c\x36\xc9\x5c\x97\x89\xb1\xf3\x9e\xaa\x60\x5c\xef\x9f\x49\x4c\x58\x85\x45\xe7\x99\xc7\x99\x65\x1c\x2d\x98\xd2\x17\xa5\x77\xe0\xca\x3c\x97\x5c\xa2\xf6\x20\x75\x6e\xec\x2a\x54\x06\x78\x03\xdc\x22\xf3\x08\x0c\x94\xe1\x4c\x01\x6e\x91\x97\xe1\xae\x8a\x30\x19\xe4\x2d\xd3\x8e\xf1\x70\x9a\x5b\xb3\x22\x1f\x4b\xe7\xe9\x1f\xe7\x70\x95\x29\x14\x30\x47\x8d\x4e\x3a\xc8\x94\xe1\xcb\x24\xfe\x11\x47\x47\xc6\x50\xa3\x10\x50\x23\x14\x6a\x62\x83\x2d\x8b\x90\x95\x52\x09\xa9\xe7\x49\x1c\x35\xd2\x7d\xd0\xa5\x52\xdd\x38\x40\x28\x63\x96\x65\x91\x72\x6e\xca\x60\xfb\x1f\xc8\x3d\x01\x20\xb8\x02\xb9\xcc\xa9\x28\xd8\xfe\xd6\x9b\x70\xb5\xd7\x6b\x32\x92\x4f\xe2\xe8\x04\xa6\x0f\x79\xa9\x83\x3b\x6d\x26\x84\xed\x82\xc8\x3a\x3f\xe2\x28\x5a\x33\x0b\x8c\x73\xb8\x02\x6f\xbe\xe1\x36\x5c\x76\x2e\xe3\x28\x92\x39\xb4\xfd\x42\xba\xa4\x01\xfe\x8d\x71\xfe\x3b\x5c\x5d\x5d\x85\x26\xce\xa5\x46\xd1\x01\x82\x88\x9e\x13\xab\x6e\xa2\x8c\x29\xa6\x39\xf6\xa1\x75\xb6\x6d\xc1\x47\x10\x59\x32\x47\xff\xa9\x3a\xad\x94\x25\xde\x4c\xbd\x95\x7a\xde\x3e\xff\xa5\xd3\x0d\xaf\xb4\x09\x6f\xa0\x16\xbf\x36\x7b\xe1\xea\x9e\x1b\x11\xae\x6b\x9b\x2b\xa9\x81\x11\xb5\x50\x2d\xe5\xbc\xb1\x6c\x8e\x7d\xf8\xf1\x40\xbf\x1f\xc8\xab\x87\x38\x7a\x38\x89\xf2\xb4\x12\x7a\x21\xca\x35\x04\xa0\xf6\x76\x5f\xdf\x73\x49\x1d\x7a\x9c\x80\x80\xf7\x5a\x12\x6a\x2d\x4f\x92\xb0\xc4\xdd\xdb\x99\xa0\x14\x49\xb1\xdd\x5f\x2c\x71\xd7\xb9\x8c\x5f\x4c\x51\x52\x1b\xfd\x9b\x14\xdb\xe7\xf3\x45\x80\x6b\xa6\xf6\x80\x55\xfc\xa6\x84\x70\xb0\xab\x13\xaa\x20\xe8\x20\xd9\xbf\x5d\xc1\x87\xb3\xed\xd9\x5f\xfc\xfb\x50\x5b\x10\xbd\x69\xf6\x3b\x4c\x7b\x38\xcd\xa7\x45\x57\x2a\x4f\x6d\x27\xf5\xda\x2c\x89\x38\x17\x94\x27\xa5\x42\xd6\x4c\x41\x55\xe3\x2a\xe6\xca\x10\x35\x48\x8f\x96\x11\x75\x9b\x35\x5a\x9a\x56\x60\xd1\x97\x56\xbb\x7d\x3a\x73\xa9\x99\x6a\x80\xeb\xec\x7b\xcb\x78\xd5\xbb\xd5\xf9\x51\x4e\xb9\xdf\x86\x6c\x06\x1f\x7b\x3d\x48\x3d\x90\x9f\x50\x18\xa9\x7d\x17\x36\x08\x1a\x51\x10\x01\x09\x14\x25\xa7\x5b\x84\xd6\x9a\xa9\x12\x5b\x15\xc9\x10\x45\x47\xa4\xdd\x94\x1e\xed\x31\x09\x75\x83\x81\x2b\xb3\x0e\xa3\x35\x63\x7c\x09\x75\xe3\x1b\x2b\xe7\x52\xc7\x75\x1b\x9e\x34\x7d\x9b\xfb\x6d\x42\xc0\xc1\xac\x50\x33\x94\x7b\x3a\xf9\x14\xf2\x9f\xc9\xf9\x48\xfb\x47\x45\x54\x45\xbe\x79\xda\xf9\x3d\xa9\x9b\x38\x71\x44\xbc\xed\x8b\x4e\x17\xce\x7f\xd9\x57\xa6\x37\x04\x05\x6f\x83\x79\xf3\x32\x54\x63\xfd\x1b\xcf\x82\x1a\x62\x92\x8f\x41\x6b\xe2\xca\x8c\xd2\xe1\x83\x60\x88\xe3\x29\x9b\x5c\xbe\x82\x7b\xea\x5b\x83\x5b\x87\x26\x61\x42\xbc\x0c\x5a\x65\xf7\x33\x72\x8b\x2b\x9a\x2e\x94\x05\xce\x94\x42\xdb\x72\x10\xb8\xab\x5b\x97\x53\xc8\x17\xae\x0a\xbf\x6b\x66\x8e\x67\x76\x8e\xde\xbd\x6d\x58\xc0\xf9\xe9\xa7\x86\x8a\xc9\x18\xbf\x2b\x10\xae\xae\xa0\x35\x98\x0c\xd3\xd9\xb0\x55\x37\x53\xaf\x07\x77\x64\x80\x86\x4c\xc9\x4c\xa8\x1d\x08\x54\xe8\xc3\xc0\x07\x6e\x74\x08\xd1\x9e\x9a\xba\xb4\x4a\xd1\x92\x83\x5b\xe9\xbc\xd4\x73\x08\xc7\xb0\xa1\xb9\x5e\xc3\x85\x1e\xe1\xac\x74\x28\x9e\x0c\x43\x6f\x68\xa3\xb1\x48\x43\x86\xe6\x50\x68\x37\xa6\xe4\x7e\x03\xca\xa5\x75\x1e\x0a\xc5\x38\x26\x84\xb7\x37\xe6\x79\x77\xa9\x2c\x6a\x66\xa6\xa8\x4e\x42\x0b\x06\xa0\xc3\xa0\x65\x8a\x06\x35\xa9\x77\xd0\x6e\x30\x3a\x71\x14\xd9\x46\xfa\x08\xfb\xf2\x40\x09\xce\x63\x71\x4c\x08\xb4\xd8\xe0\x1a\x89\xca\x03\x1b\x54\x8b\x1a\xe9\xfa\xd7\xaf\xf5\x16\x80\x2e\x89\x23\x7a\x77\xd4\xd7\xca\xcc\x4f\xfb\x5a\x54\x61\xe1\xa5\xb5\x94\xff\xfd\x28\xc8\xa9\xc7\xff\x28\x9d\xa7\x98\x5a\xa2\x96\x9a\x2d\x9e\x23\xeb\x40\xcd\x34\xf5\x3b\x4f\x87\x28\xcd\xcf\x30\xaf\xc8\x8b\x7a\x5a\x56\xdb\x64\x61\x3c\x6a\x2f\x99\x52\x3b\xca\xc3\xc6\xd2\x1a\xb5\x40\x8b\x5d\x70\x92\xa4\x08\xa7\x12\x95\x9a\xab\x52\xd0\x09\x42\x68\x8e\x1a\xcf\x05\x9b\x4f\xf7\xaf\x15\x3a\xc7\xe6\x98\x50\x25\xe5\x72\x5b\x6f\xb0\x1a\x5a\x15\xc9\xb5\x3b\xad\x24\x8e\x9e\xa5\x18\x65\xe6\x49\x53\x64\x34\x46\x52\x21\x2c\x3a\xd7\xee\xd4\x9c\xb3\xcf\xec\xdd\x02\x35\x05\x1f\x34\x6e\xea\x9a\x93\x8e\x26\x1e\xad\x8a\xa2\x0b\x4c\x08\xa2\xb6\x47\xeb\x4c\x1c\x45\x6e\x23\x3d\x5f\x40\xd0\x64\x8a\x43\x2f\x76\xea\xfa\xe7\xcc\x21\x7c\x18\xfe\x7b\x36\xb8\xf9\x3c\x1c\xdc\xdc\xde\x7f\xe8\xc3\xc9\xd9\x74\xf4\x9f\xe1\xfe\xec\x53\x3a\x4e\xaf\x07\xc3\x0f\xfd\x38\x7a\xde\x21\x6f\x1a\x17\x48\xa1\xf3\x8c\x2f\x93\x02\x71\xd9\x3e\x3b\xe5\x81\x83\x83\x51\x94\x59\x64\xcb\xcb\x83\x31\x55\x83\xd6\x3a\x1a\xca\x85\x2b\x78\x31\x58\x97\x2f\x5b\x33\xa8\xe5\xdb\x0d\x91\x1f\x56\x22\x3a\x79\x87\x1d\x17\xff\xb3\x21\x54\x25\xe4\x78\x1f\x1c\x53\xb4\x89\xcb\x3f\xb1\x0b\x26\xcf\x1d\xfa\x2e\xa0\x16\x66\x43\xcc\xb7\x47\xad\x6e\x6a\xdc\xa3\x90\x9d\x77\x2a\x06\xbd\xc9\x1b\x64\x0a\x86\x93\x7f\xe2\x53\xd9\x8b\x67\x65\x51\x0b\xb8\xaa\x35\xc3\xc7\x60\xc8\x3b\x62\x75\x51\x07\xeb\x91\x8a\x9f\x4f\x33\xd8\x0d\x26\xac\x70\x65\xec\xae\x9e\x48\x47\x2e\xbe\x1e\xd8\x74\x3c\xde\x97\xd4\x20\x1d\x8f\xa9\xf6\xf6\x07\x9f\x87\xe3\xe1\xd7\x74\x36\x3c\x91\x9a\xce\xd2\xd9\x68\x50\x1d\xbd\xec\x41\x93\x88\x47\x96\x9f\xbf\xbb\xf6\x5a\xd3\xe9\xec\x66\x32\x6c\xf5\xeb\x5f\xe3\x9b\xf4\x73\xeb\x89\xc2\x7a\x21\x7d\xad\x7b\xbd\xb9\x33\x56\xfc\x3f\x4d\x70\xb4\x94\xe5\xec\xb9\x9d\x8c\x18\x87\x71\x5f\x3e\xfa\xf6\x02\xa6\x1b\x62\xce\xab\xef\xce\x28\x67\xa7\x2b\xd6\x81\x8a\x1f\xe2\x87\xf8\xbf\x03\x00\x25\x8f\x8e\x3c\xed\x10\x00\x00")
 
 func prestate_tracerJsBytes() ([]byte, error) {
 	return bindataRead(
@@ -0,0 +1,184 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+)
+
+// prestateAccount is the state of an account before the traced transaction,
+// restricted to the storage slots the transaction accessed.
+type prestateAccount struct {
+	Balance *hexutil.Big                `json:"balance"`
+	Nonce   uint64                      `json:"nonce"`
+	Code    hexutil.Bytes               `json:"code"`
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// prestateTracer is the native implementation of the JavaScript prestateTracer,
+// which outputs sufficient information to create a local execution of the
+// transaction from a custom assembled genesis block.
+type prestateTracer struct {
+	prestate map[common.Address]*prestateAccount // Genesis allocations being built
+	db       vm.StateDB                          // State database of the last step
+	create   bool
+	from     common.Address
+	to       common.Address
+	value    *big.Int
+
+	interrupt  uint32 // Atomic flag to signal execution interruption
+	reason     error  // Textual reason for the interruption
+	stopReason error  // Interruption reason the tracing actually stopped with
+}
+
+// newPrestateTracer creates a native prestate tracer.
+func newPrestateTracer() *prestateTracer {
+	return new(prestateTracer)
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create, t.from, t.to, t.value = create, from, to, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.stopReason != nil {
+		return nil
+	}
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.stopReason = t.reason
+		return nil
+	}
+	t.db = env.StateDB
+
+	// Add the current account if we just started tracing. Balance will potentially
+	// be wrong here, since this will include the value sent along with the message.
+	// We fix that in GetResult.
+	if t.prestate == nil {
+		t.prestate = make(map[common.Address]*prestateAccount)
+		t.lookupAccount(contract.Address())
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		t.lookupAccount(common.BigToAddress(stackPeek(stack, 0)))
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+	case vm.CREATE2:
+		offset, size := stackUint64(stack, 1), stackUint64(stack, 2)
+		salt := common.BigToHash(stackPeek(stack, 3))
+		t.lookupAccount(crypto.CreateAddress2(contract.Address(), salt, crypto.Keccak256(memorySlice(memory, offset, offset+size))))
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BigToAddress(stackPeek(stack, 1)))
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.BigToHash(stackPeek(stack, 0)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface, faults need no special handling.
+func (t *prestateTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements the Tracer interface, the prestate is complete by then.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.prestate[addr]; ok {
+		return
+	}
+	t.prestate[addr] = &prestateAccount{
+		Balance: (*hexutil.Big)(new(big.Int).Set(t.db.GetBalance(addr))),
+		Nonce:   t.db.GetNonce(addr),
+		Code:    common.CopyBytes(t.db.GetCode(addr)),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate, unless it's empty.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+
+	storage := t.prestate[addr].Storage
+	if _, ok := storage[key]; ok {
+		return
+	}
+	if value := t.db.GetState(addr, key); value != (common.Hash{}) {
+		storage[key] = value
+	}
+}
+
+// GetResult returns the JSON encoded prestate of the accounts the transaction
+// touched.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	// Transactions not executing any code touch no state beyond the sender and
+	// the recipient, which are also missing the state database to look them up
+	if t.prestate == nil {
+		return json.RawMessage(`{}`), t.stopReason
+	}
+	// At this point, we need to deduct the value from the outer transaction, and
+	// move it back to the origin
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	value := new(big.Int)
+	if t.value != nil {
+		value = t.value
+	}
+	from, to := t.prestate[t.from], t.prestate[t.to]
+	fromBal, toBal := from.Balance.ToInt(), to.Balance.ToInt()
+
+	to.Balance = (*hexutil.Big)(new(big.Int).Sub(toBal, value))
+	from.Balance = (*hexutil.Big)(new(big.Int).Add(fromBal, value))
+
+	// Decrement the caller's nonce, and remove empty create targets. We can blindly
+	// delete the contract prestate, as any existing state would have caused the
+	// transaction to be rejected as invalid in the first place.
+	if from.Nonce > 0 {
+		from.Nonce--
+	}
+	if t.create {
+		delete(t.prestate, t.to)
+	}
+	blob, err := json.Marshal(t.prestate)
+	if err != nil {
+		return nil, err
+	}
+	return blob, t.stopReason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *prestateTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
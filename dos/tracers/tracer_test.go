@@ -138,3 +138,47 @@ func TestHaltBetweenSteps(t *testing.T) {
 		t.Errorf("Expected timeout error, got %v", err)
 	}
 }
+
+// Tests that the precompiled contracts reported to tracers are the ones active
+// at the traced block.
+func TestIsPrecompiled(t *testing.T) {
+	config := &params.ChainConfig{
+		ChainId:             big.NewInt(1),
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(100),
+		ConstantinopleBlock: big.NewInt(100),
+		IstanbulBlock:       big.NewInt(200),
+	}
+	tests := []struct {
+		number int64
+		want   string
+	}{
+		{0, "[true,false,false]"},  // Homestead: ecrecover only
+		{100, "[true,true,false]"}, // Byzantium: bn256 pairing added
+		{200, "[true,true,true]"},  // Istanbul: blake2f added
+	}
+	for _, tt := range tests {
+		tracer, err := New(`{res: null, step: function() { if (this.res == null) { this.res = [isPrecompiled(toAddress("0x01")), isPrecompiled(toAddress("0x08")), isPrecompiled(toAddress("0x09"))]; } }, fault: function() {}, result: function() { return this.res; }}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env := vm.NewEVM(vm.Context{BlockNumber: big.NewInt(tt.number)}, nil, config, vm.Config{Debug: true, Tracer: tracer})
+
+		contract := vm.NewContract(account{}, account{}, big.NewInt(0), 10000)
+		contract.Code = []byte{byte(vm.PUSH1), 0x1, 0x0}
+
+		if _, err := env.Interpreter().Run(contract, []byte{}); err != nil {
+			t.Fatal(err)
+		}
+		ret, err := tracer.GetResult()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(ret) != tt.want {
+			t.Errorf("block %d: precompiles mismatch: have %s, want %s", tt.number, ret, tt.want)
+		}
+	}
+}
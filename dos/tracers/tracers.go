@@ -14,19 +14,42 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the dos library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native transaction tracers.
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/dos/tracers/internal/tracers"
 )
 
+// TxTracer is implemented by all transaction tracers, whether running in the
+// JavaScript engine or natively in Go, that produce a JSON result and can be
+// interrupted midway.
+type TxTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON encoded result of the trace, or any error that
+	// occurred during tracing.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates the tracing at the first opportune moment.
+	Stop(err error)
+}
+
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
+// native contains the constructors of the built in tracers which also have a Go
+// implementation, by the name of their JavaScript counterpart.
+var native = map[string]func() TxTracer{
+	"callTracer":     func() TxTracer { return newCallTracer() },
+	"prestateTracer": func() TxTracer { return newPrestateTracer() },
+}
+
 // camel converts a snake cased input string into a camel cased output.
 func camel(str string) string {
 	pieces := strings.Split(str, "_")
@@ -51,3 +74,13 @@ func tracer(name string) (string, bool) {
 	}
 	return "", false
 }
+
+// NewTxTracer creates a transaction tracer from a built in tracer name or a
+// JavaScript snippet. Built in tracers with a native implementation are served
+// by it, everything else runs in the JavaScript engine.
+func NewTxTracer(code string) (TxTracer, error) {
+	if constructor, ok := native[code]; ok {
+		return constructor(), nil
+	}
+	return New(code)
+}
@@ -191,3 +191,184 @@ func TestCallTracer(t *testing.T) {
 		})
 	}
 }
+
+// traceTestcase executes the transaction of a tracer testcase on top of its
+// prestate with the given tracer, returning the trace result decoded into a
+// generic JSON object.
+func traceTestcase(t *testing.T, test *callTracerTest, tracer TxTracer) interface{} {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      origin,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		Difficulty:  (*big.Int)(test.Context.Difficulty),
+		GasLimit:    uint64(test.Context.GasLimit),
+		GasPrice:    tx.GasPrice(),
+	}
+	statedb := tests.MakePreState(dosdb.NewMemDatabase(), test.Genesis.Alloc)
+	evm := vm.NewEVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, _, _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	var ret interface{}
+	if err := json.Unmarshal(res, &ret); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	// Drop the execution time, which naturally differs between runs
+	if obj, ok := ret.(map[string]interface{}); ok {
+		delete(obj, "time")
+	}
+	return ret
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// checks that the native tracers produce the same results as their JavaScript
+// counterparts.
+func TestNativeTracers(t *testing.T) {
+	files, err := ioutil.ReadDir("testdata")
+	if err != nil {
+		t.Fatalf("failed to retrieve tracer test suite: %v", err)
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
+			t.Parallel()
+
+			blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+			if err != nil {
+				t.Fatalf("failed to read testcase: %v", err)
+			}
+			test := new(callTracerTest)
+			if err := json.Unmarshal(blob, test); err != nil {
+				t.Fatalf("failed to parse testcase: %v", err)
+			}
+			for name := range native {
+				jsTracer, err := New(name)
+				if err != nil {
+					t.Fatalf("failed to create JavaScript %s: %v", name, err)
+				}
+				nativeTracer, err := NewTxTracer(name)
+				if err != nil {
+					t.Fatalf("failed to create native %s: %v", name, err)
+				}
+				if _, ok := nativeTracer.(*Tracer); ok {
+					t.Fatalf("%s not served natively", name)
+				}
+				want := traceTestcase(t, test, jsTracer)
+				if have := traceTestcase(t, test, nativeTracer); !reflect.DeepEqual(have, want) {
+					t.Fatalf("%s mismatch:\nhave %+v\nwant %+v", name, have, want)
+				}
+			}
+		})
+	}
+}
+
+// flatCallSummary is the position and parties of a flattened call, used to check
+// the flat call tracer against the call tree of the tracer testcases.
+type flatCallSummary struct {
+	Type         string
+	From, To     common.Address
+	Subtraces    int
+	TraceAddress []int
+}
+
+// flattenCallTrace converts the expected call tree of a testcase into the flat
+// call summaries, depth first.
+func flattenCallTrace(call callTrace, address []int) []flatCallSummary {
+	summary := flatCallSummary{From: call.From, To: call.To, Subtraces: len(call.Calls), TraceAddress: address}
+	switch call.Type {
+	case "SELFDESTRUCT":
+		summary.Type = "suicide"
+	case "CREATE", "CREATE2":
+		summary.Type = "create"
+		if call.Error != "" {
+			summary.To = common.Address{}
+		}
+	default:
+		summary.Type = "call"
+	}
+	calls := []flatCallSummary{summary}
+	for i, inner := range call.Calls {
+		calls = append(calls, flattenCallTrace(inner, append(append([]int{}, address...), i))...)
+	}
+	return calls
+}
+
+// Iterates over all the input-output datasets in the tracer test harness and
+// checks that the flat call tracer produces the calls of the expected call tree.
+func TestFlatCallTracer(t *testing.T) {
+	files, err := ioutil.ReadDir("testdata")
+	if err != nil {
+		t.Fatalf("failed to retrieve tracer test suite: %v", err)
+	}
+	for _, file := range files {
+		if !strings.HasPrefix(file.Name(), "call_tracer_") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
+			t.Parallel()
+
+			// Call tracer test found, read if from disk
+			blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+			if err != nil {
+				t.Fatalf("failed to read testcase: %v", err)
+			}
+			test := new(callTracerTest)
+			if err := json.Unmarshal(blob, test); err != nil {
+				t.Fatalf("failed to parse testcase: %v", err)
+			}
+			tracer := NewFlatCallTracer()
+			traceTestcase(t, test, tracer)
+
+			traces, err := tracer.Traces()
+			if err != nil {
+				t.Fatalf("failed to retrieve flat traces: %v", err)
+			}
+			var have []flatCallSummary
+			for _, trace := range traces {
+				summary := flatCallSummary{Type: trace.Type, Subtraces: trace.Subtraces, TraceAddress: trace.TraceAddress}
+				switch trace.Type {
+				case "suicide":
+					summary.From, summary.To = *trace.Action.Address, *trace.Action.RefundAddress
+				case "create":
+					summary.From = *trace.Action.From
+					if trace.Result != nil {
+						summary.To = *trace.Result.Address
+					}
+				default:
+					summary.From, summary.To = *trace.Action.From, *trace.Action.To
+				}
+				if (trace.Error == "") != (trace.Result != nil) {
+					t.Errorf("trace %v: error %q with result %v", trace.TraceAddress, trace.Error, trace.Result)
+				}
+				have = append(have, summary)
+			}
+			if want := flattenCallTrace(*test.Result, []int{}); !reflect.DeepEqual(have, want) {
+				t.Fatalf("flat trace mismatch: have %+v, want %+v", have, want)
+			}
+		})
+	}
+}
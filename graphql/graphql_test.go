@@ -0,0 +1,224 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/internal/dosapi"
+	"github.com/doslink/dos/rpc"
+)
+
+// testBackend is a dosapi.Backend serving a single canonical block after the
+// genesis, panicking on any method the resolvers shouldn't need.
+type testBackend struct {
+	dosapi.Backend
+
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+}
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if block, _ := b.BlockByNumber(ctx, number); block != nil {
+		return block.Header(), nil
+	}
+	return nil, nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.CurrentBlock(), nil
+	}
+	if number < 0 || int(number) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, block := range b.blocks {
+		if block.Hash() == hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int {
+	return big.NewInt(int64(len(b.blocks)))
+}
+
+func (b *testBackend) CurrentBlock() *types.Block {
+	return b.blocks[len(b.blocks)-1]
+}
+
+// Tests that the resolvers match the schema, which would otherwise only fail
+// when starting the service.
+func TestBuildSchema(t *testing.T) {
+	if _, err := newHandler(nil); err != nil {
+		t.Fatalf("could not construct GraphQL handler: %v", err)
+	}
+}
+
+// Tests that blocks, their transactions, receipts and logs can be queried.
+func TestBlockQuery(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.HomesteadSigner{}
+	tx, _ := types.SignTx(types.NewContractCreation(0, big.NewInt(1), 100000, big.NewInt(1), nil), signer, key)
+
+	topic := common.HexToHash("0x01")
+	receipt := types.NewReceipt(nil, false, 21000)
+	receipt.GasUsed = 21000
+	receipt.ContractAddress = crypto.CreateAddress(crypto.PubkeyToAddress(key.PublicKey), 0)
+	receipt.Logs = []*types.Log{{Address: receipt.ContractAddress, Topics: []common.Hash{topic}, Data: []byte{0x02}}}
+	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+	genesis := types.NewBlock(&types.Header{Number: big.NewInt(0)}, nil, nil, nil)
+	block := types.NewBlock(&types.Header{ParentHash: genesis.Hash(), Number: big.NewInt(1), GasUsed: 21000}, []*types.Transaction{tx}, nil, []*types.Receipt{receipt})
+
+	backend := &testBackend{
+		blocks:   []*types.Block{genesis, block},
+		receipts: map[common.Hash]types.Receipts{block.Hash(): {receipt}},
+	}
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("could not construct GraphQL handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	query := `{
+		block {
+			number
+			parent { number }
+			transactionCount
+			transactions {
+				index
+				status
+				gasUsed
+				createdContract { address }
+				logs { topics data }
+			}
+			logs(filter: {topics: [["` + topic.Hex() + `"]]}) { index }
+		}
+		missing: block(number: 2) { number }
+	}`
+	body, _ := json.Marshal(map[string]string{"query": query})
+
+	res, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatalf("failed to query GraphQL endpoint: %v", err)
+	}
+	defer res.Body.Close()
+
+	var result struct {
+		Data   interface{}
+		Errors []interface{}
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode GraphQL response: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("query failed: %v", result.Errors)
+	}
+	var want interface{}
+	json.Unmarshal([]byte(`{
+		"block": {
+			"number": "0x1",
+			"parent": {"number": "0x0"},
+			"transactionCount": 1,
+			"transactions": [{
+				"index": 0,
+				"status": "0x1",
+				"gasUsed": "0x5208",
+				"createdContract": {"address": "`+strings.ToLower(receipt.ContractAddress.Hex())+`"},
+				"logs": [{"topics": ["`+topic.Hex()+`"], "data": "0x02"}]
+			}],
+			"logs": [{"index": 0}]
+		},
+		"missing": null
+	}`), &want)
+
+	if !reflect.DeepEqual(result.Data, want) {
+		have, _ := json.Marshal(result.Data)
+		t.Errorf("query result mismatch:\nhave %s\nwant %v", have, want)
+	}
+}
+
+// Tests that block ranges are capped at the current head and that too large
+// ranges are rejected instead of being iterated.
+func TestBlockRange(t *testing.T) {
+	blocks := make([]*types.Block, maxQueryBlocks+1)
+	for i := range blocks {
+		blocks[i] = types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+	}
+	handler, err := newHandler(&testBackend{blocks: blocks})
+	if err != nil {
+		t.Fatalf("could not construct GraphQL handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	tests := []struct {
+		query  string
+		blocks int
+		fail   bool
+	}{
+		{query: `{ blocks(from: 1, to: "0xffffffffffffffff") { number } }`, blocks: maxQueryBlocks},
+		{query: `{ blocks(from: "0xffffffffffffffff") { number } }`, blocks: 0},
+		{query: `{ blocks(from: 0) { number } }`, fail: true},
+		{query: `{ logs(filter: {fromBlock: 0}) { index } }`, fail: true},
+		{query: `{ logs(filter: {fromBlock: 1, toBlock: "0xffffffffffffffff"}) { index } }`},
+	}
+	for i, tt := range tests {
+		body, _ := json.Marshal(map[string]string{"query": tt.query})
+		res, err := http.Post(server.URL, "application/json", strings.NewReader(string(body)))
+		if err != nil {
+			t.Fatalf("test %d: failed to query GraphQL endpoint: %v", i, err)
+		}
+		var result struct {
+			Data   struct{ Blocks []interface{} }
+			Errors []interface{}
+		}
+		err = json.NewDecoder(res.Body).Decode(&result)
+		res.Body.Close()
+		if err != nil {
+			t.Fatalf("test %d: failed to decode GraphQL response: %v", i, err)
+		}
+		if failed := len(result.Errors) > 0; failed != tt.fail {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, result.Errors, tt.fail)
+			continue
+		}
+		if len(result.Data.Blocks) != tt.blocks {
+			t.Errorf("test %d: block count mismatch: have %d, want %d", i, len(result.Data.Blocks), tt.blocks)
+		}
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"github.com/doslink/dos/consensus/dosash"
 	"github.com/doslink/dos/core"
 	"github.com/doslink/dos/core/rawdb"
+	"github.com/doslink/dos/core/state"
 	"github.com/doslink/dos/core/types"
 	"github.com/doslink/dos/core/vm"
 	"github.com/doslink/dos/crypto"
@@ -66,6 +67,36 @@ func (s *PublicDoslinkAPI) GasPrice(ctx context.Context) (*big.Int, error) {
 	return s.b.SuggestPrice(ctx)
 }
 
+// feeHistoryResult is the fee market history of a range of blocks.
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+	GasPrice     [][]*hexutil.Big `json:"gasPrice,omitempty"`
+}
+
+// FeeHistory returns the gas used ratio and the requested gas price percentiles
+// of up to blockCount blocks ending with lastBlock.
+func (s *PublicDoslinkAPI) FeeHistory(ctx context.Context, blockCount hexutil.Uint, lastBlock rpc.BlockNumber, percentiles []float64) (*feeHistoryResult, error) {
+	oldest, ratios, prices, err := s.b.FeeHistory(ctx, int(blockCount), lastBlock, percentiles)
+	if err != nil {
+		return nil, err
+	}
+	results := &feeHistoryResult{
+		OldestBlock:  (*hexutil.Big)(oldest),
+		GasUsedRatio: ratios,
+	}
+	if prices != nil {
+		results.GasPrice = make([][]*hexutil.Big, len(prices))
+		for i, blockPrices := range prices {
+			results.GasPrice[i] = make([]*hexutil.Big, len(blockPrices))
+			for j, price := range blockPrices {
+				results.GasPrice[i][j] = (*hexutil.Big)(price)
+			}
+		}
+	}
+	return results, nil
+}
+
 // ProtocolVersion returns the current Doslink protocol version this node supports
 func (s *PublicDoslinkAPI) ProtocolVersion() hexutil.Uint {
 	return hexutil.Uint(s.b.ProtocolVersion())
@@ -132,13 +163,77 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
-	pending, queue := s.b.Stats()
-	return map[string]hexutil.Uint{
-		"pending": hexutil.Uint(pending),
-		"queued":  hexutil.Uint(queue),
+// ContentFrom returns the transactions contained within the transaction pool
+// belonging to a single account.
+func (s *PublicTxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCTransaction {
+	content := map[string]map[string]*RPCTransaction{
+		"pending": make(map[string]*RPCTransaction),
+		"queued":  make(map[string]*RPCTransaction),
 	}
+	pending, queue := s.b.TxPoolContentFrom(addr)
+
+	for _, tx := range pending {
+		content["pending"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	for _, tx := range queue {
+		content["queued"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	return content
+}
+
+// NonceGap is a range of missing nonces holding back the queued transactions of
+// an account.
+type NonceGap struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// AccountTxPoolStatus is the state of a single account in the transaction pool.
+type AccountTxPoolStatus struct {
+	Pending      hexutil.Uint            `json:"pending"`
+	Queued       hexutil.Uint            `json:"queued"`
+	NextNonce    hexutil.Uint64          `json:"nextNonce"`
+	Gaps         []NonceGap              `json:"gaps"`
+	Replacements map[string]*hexutil.Big `json:"replacements"`
+}
+
+// Status returns the number of pending and queued transaction in the pool, or
+// if an account is given, diagnostics about its transactions: the next nonce
+// expected, the nonce gaps holding back its queued transactions and the gas
+// price needed to replace each of those.
+func (s *PublicTxPoolAPI) Status(ctx context.Context, addr *common.Address) (interface{}, error) {
+	if addr == nil {
+		pending, queue := s.b.Stats()
+		return map[string]hexutil.Uint{
+			"pending": hexutil.Uint(pending),
+			"queued":  hexutil.Uint(queue),
+		}, nil
+	}
+	pending, queue := s.b.TxPoolContentFrom(*addr)
+
+	nonce, err := s.b.GetPoolNonce(ctx, *addr)
+	if err != nil {
+		return nil, err
+	}
+	status := &AccountTxPoolStatus{
+		Pending:      hexutil.Uint(len(pending)),
+		Queued:       hexutil.Uint(len(queue)),
+		NextNonce:    hexutil.Uint64(nonce),
+		Gaps:         make([]NonceGap, 0),
+		Replacements: make(map[string]*hexutil.Big),
+	}
+	// Walk the nonce sorted queue, collecting the missing nonces in front of it
+	bump := s.b.TxPoolPriceBump()
+	for _, tx := range queue {
+		if tx.Nonce() > nonce {
+			status.Gaps = append(status.Gaps, NonceGap{From: hexutil.Uint64(nonce), To: hexutil.Uint64(tx.Nonce() - 1)})
+		}
+		if tx.Nonce() >= nonce {
+			nonce = tx.Nonce() + 1
+		}
+		status.Replacements[fmt.Sprintf("%d", tx.Nonce())] = (*hexutil.Big)(core.ReplacementPrice(tx.GasPrice(), bump))
+	}
+	return status, nil
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
@@ -603,6 +698,85 @@ func (s *PublicBlockChainAPI) GetStorageAt(ctx context.Context, address common.A
 	return res[:], state.Error()
 }
 
+// AccountResult is the result of a dos_getProof request: the account fields and
+// its Merkle proof in the account trie, along with the proofs of the requested
+// storage slots in the account's storage trie.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the value and Merkle proof of a single storage slot.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// GetProof returns the Merkle proof of the given account and optionally some of
+// its storage slots (EIP-1186), at the state of the given block number. The
+// rpc.LatestBlockNumber and rpc.PendingBlockNumber meta block numbers are also
+// allowed.
+func (s *PublicBlockChainAPI) GetProof(ctx context.Context, address common.Address, storageKeys []string, blockNr rpc.BlockNumber) (*AccountResult, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	var (
+		storageTrie  = state.StorageTrie(address)
+		storageHash  = types.EmptyRootHash
+		codeHash     = state.GetCodeHash(address)
+		storageProof = make([]StorageResult, len(storageKeys))
+	)
+	// A missing storage trie means a non existent account, so the code is empty
+	if storageTrie != nil {
+		storageHash = storageTrie.Hash()
+	} else {
+		codeHash = crypto.Keccak256Hash(nil)
+	}
+	// Create the proofs for the storage slots
+	for i, key := range storageKeys {
+		if storageTrie == nil {
+			storageProof[i] = StorageResult{key, &hexutil.Big{}, []string{}}
+			continue
+		}
+		proof, err := state.GetStorageProof(address, common.HexToHash(key))
+		if err != nil {
+			return nil, err
+		}
+		value := state.GetState(address, common.HexToHash(key))
+		storageProof[i] = StorageResult{key, (*hexutil.Big)(value.Big()), toHexSlice(proof)}
+	}
+	// Create the proof for the account itself
+	accountProof, err := state.GetProof(address)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(state.GetBalance(address)),
+		CodeHash:     codeHash,
+		Nonce:        hexutil.Uint64(state.GetNonce(address)),
+		StorageHash:  storageHash,
+		StorageProof: storageProof,
+	}, state.Error()
+}
+
+// toHexSlice creates a slice of hex-strings based on []byte.
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
+
 // CallArgs represents the arguments for a call.
 type CallArgs struct {
 	From     common.Address  `json:"from"`
@@ -613,33 +787,86 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
+// ToMessage converts the call arguments to the message type used by the core
+// evm, filling in the default gas allowance and gas price if none were set.
+func (args *CallArgs) ToMessage() types.Message {
+	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
+	if gas == 0 {
+		gas = math.MaxUint64 / 2
+	}
+	if gasPrice.Sign() == 0 {
+		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
+	}
+	return types.NewMessage(args.From, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
+
+// OverrideAccount indicates the overriding fields of an account during the
+// execution of a message call. State and StateDiff are mutually exclusive: the
+// former replaces the entire storage of the account, the latter only patches
+// the given slots.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   **hexutil.Big                `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the fields of the specified accounts in the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		if account.Nonce != nil {
+			state.SetNonce(addr, uint64(*account.Nonce))
+		}
+		if account.Code != nil {
+			state.SetCode(addr, *account.Code)
+		}
+		if account.Balance != nil {
+			state.SetBalance(addr, (*big.Int)(*account.Balance))
+		}
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		// Replace the entire storage if state is set
+		if account.State != nil {
+			state.SetStorage(addr, *account.State)
+		}
+		// Apply the individual slot changes if stateDiff is set
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
 		return nil, 0, false, err
 	}
+	if err := overrides.Apply(state); err != nil {
+		return nil, 0, false, err
+	}
 	// Set sender address or use a default if none specified
-	addr := args.From
-	if addr == (common.Address{}) {
+	if args.From == (common.Address{}) {
 		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
+				args.From = accounts[0].Address
 			}
 		}
 	}
-	// Set default gas & gas price if none were set
-	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
-	if gas == 0 {
-		gas = math.MaxUint64 / 2
-	}
-	if gasPrice.Sign() == 0 {
-		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
-	}
-
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	msg := args.ToMessage()
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -677,14 +904,19 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 
 // Call executes the given transaction on the state for the given block number.
 // It doesn't make and changes in the state/blockchain and is useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (hexutil.Bytes, error) {
-	result, _, _, err := s.doCall(ctx, args, blockNr, vm.Config{}, 5*time.Second)
+//
+// Additionally, the caller can specify a batch of accounts to override before
+// executing the call, e.g. to fund the sender or to replace contract code and
+// storage.
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, overrides *StateOverride) (hexutil.Bytes, error) {
+	result, _, _, err := s.doCall(ctx, args, blockNr, overrides, vm.Config{}, 5*time.Second)
 	return (hexutil.Bytes)(result), err
 }
 
 // EstimateGas returns an estimate of the amount of gas needed to execute the
-// given transaction against the current pending block.
-func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
+// given transaction against the current pending block, optionally overriding
+// some accounts beforehand.
+func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs, overrides *StateOverride) (hexutil.Uint64, error) {
 	// Binary search the gas requirement, as it may be higher than the amount used
 	var (
 		lo  uint64 = params.TxGas - 1
@@ -707,7 +939,7 @@ func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (h
 	executable := func(gas uint64) bool {
 		args.Gas = hexutil.Uint64(gas)
 
-		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, vm.Config{}, 0)
+		_, _, failed, err := s.doCall(ctx, args, rpc.PendingBlockNumber, overrides, vm.Config{}, 0)
 		if err != nil || failed {
 			return false
 		}
@@ -136,10 +136,27 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.dos.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.dos.txPool.ContentFrom(addr)
+}
+
+func (b *LesApiBackend) TxPoolPriceBump() uint64 {
+	// Light clients don't replace transactions, the servers use the default bump
+	return core.DefaultTxPoolConfig.PriceBump
+}
+
 func (b *LesApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.dos.txPool.SubscribeTxPreEvent(ch)
 }
 
+func (b *LesApiBackend) SubscribeTxLifecycleEvent(ch chan<- core.TxLifecycleEvent) event.Subscription {
+	// Light clients don't track why transactions leave their pool
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
 func (b *LesApiBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
 	return b.dos.blockchain.SubscribeChainEvent(ch)
 }
@@ -172,6 +189,10 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) FeeHistory(ctx context.Context, blockCount int, lastBlock rpc.BlockNumber, percentiles []float64) (*big.Int, []float64, [][]*big.Int, error) {
+	return b.gpo.FeeHistory(ctx, blockCount, lastBlock, percentiles)
+}
+
 func (b *LesApiBackend) ChainDb() dosdb.Database {
 	return b.dos.chainDb
 }
@@ -83,9 +83,13 @@ out:
 			self.mu.Lock()
 			if self.quitCurrentOp != nil {
 				close(self.quitCurrentOp)
+				self.quitCurrentOp = nil
+			}
+			// A nil work only aborts the current operation
+			if work != nil {
+				self.quitCurrentOp = make(chan struct{})
+				go self.mine(work, self.quitCurrentOp)
 			}
-			self.quitCurrentOp = make(chan struct{})
-			go self.mine(work, self.quitCurrentOp)
 			self.mu.Unlock()
 		case <-self.stop:
 			self.mu.Lock()
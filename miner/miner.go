@@ -20,6 +20,7 @@ package miner
 import (
 	"fmt"
 	"sync/atomic"
+	"time"
 
 	"github.com/doslink/dos/accounts"
 	"github.com/doslink/dos/common"
@@ -176,6 +177,24 @@ func (self *Miner) PendingBlock() *types.Block {
 	return self.worker.pendingBlock()
 }
 
+// SetRecommitInterval sets the interval after which the block being mined is
+// rebuilt with the transactions arrived in the meantime. Zero disables it.
+func (self *Miner) SetRecommitInterval(interval time.Duration) {
+	self.worker.setRecommitInterval(interval)
+}
+
+// SetNoEmpty sets whether empty blocks are withheld from sealing while the
+// transaction pool has pending transactions.
+func (self *Miner) SetNoEmpty(noempty bool) {
+	self.worker.setNoEmpty(noempty)
+}
+
+// SetTxPolicy sets the policy ordering the pending transactions into the blocks
+// being built.
+func (self *Miner) SetTxPolicy(policy TxPolicy) {
+	self.worker.setTxPolicy(policy)
+}
+
 func (self *Miner) SetDoserbase(addr common.Address) {
 	self.coinbase = addr
 	self.worker.setDoserbase(addr)
@@ -17,19 +17,28 @@
 package miner
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"math/big"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
 	"github.com/doslink/dos/consensus"
 	"github.com/doslink/dos/consensus/dosash"
 	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/event"
 	"github.com/doslink/dos/log"
 )
 
+// remoteNotifyTimeout is the maximum time allowed for a notify URL to accept a
+// new work package.
+const remoteNotifyTimeout = time.Second
+
 type hashrate struct {
 	ping time.Time
 	rate uint64
@@ -50,15 +59,23 @@ type RemoteAgent struct {
 	hashrateMu sync.RWMutex
 	hashrate   map[common.Hash]hashrate
 
+	workFeed   event.Feed   // Feed announcing every new work package to push based miners
+	notifyURLs []string     // HTTP URLs to POST every new work package to
+	notifier   *http.Client // HTTP client used to deliver the work notifications
+
 	running int32 // running indicates whether the agent is active. Call atomically
 }
 
-func NewRemoteAgent(chain consensus.ChainReader, engine consensus.Engine) *RemoteAgent {
+// NewRemoteAgent creates an agent handing out work packages to external miners.
+// Besides being pollable, every new package is POSTed to the given notify URLs.
+func NewRemoteAgent(chain consensus.ChainReader, engine consensus.Engine, notify []string) *RemoteAgent {
 	return &RemoteAgent{
-		chain:    chain,
-		engine:   engine,
-		work:     make(map[common.Hash]*Work),
-		hashrate: make(map[common.Hash]hashrate),
+		chain:      chain,
+		engine:     engine,
+		work:       make(map[common.Hash]*Work),
+		hashrate:   make(map[common.Hash]hashrate),
+		notifyURLs: notify,
+		notifier:   &http.Client{Timeout: remoteNotifyTimeout},
 	}
 }
 
@@ -69,6 +86,12 @@ func (a *RemoteAgent) SubmitHashrate(id common.Hash, rate uint64) {
 	a.hashrate[id] = hashrate{time.Now(), rate}
 }
 
+// SubscribeWork registers a subscription for the work packages received by the
+// agent, allowing them to be pushed to external miners instead of polled.
+func (a *RemoteAgent) SubscribeWork(ch chan<- *Work) event.Subscription {
+	return a.workFeed.Subscribe(ch)
+}
+
 func (a *RemoteAgent) Work() chan<- *Work {
 	return a.workCh
 }
@@ -113,24 +136,56 @@ func (a *RemoteAgent) GetWork() ([3]string, error) {
 	var res [3]string
 
 	if a.currentWork != nil {
-		block := a.currentWork.Block
-
-		res[0] = block.HashNoNonce().Hex()
-		seedHash := dosash.SeedHash(block.NumberU64())
-		res[1] = common.BytesToHash(seedHash).Hex()
-		// Calculate the "target" to be returned to the external miner
-		n := big.NewInt(1)
-		n.Lsh(n, 255)
-		n.Div(n, block.Difficulty())
-		n.Lsh(n, 1)
-		res[2] = common.BytesToHash(n.Bytes()).Hex()
-
-		a.work[block.HashNoNonce()] = a.currentWork
+		pkg := workPackage(a.currentWork)
+		copy(res[:], pkg[:3])
+
+		a.work[a.currentWork.Block.HashNoNonce()] = a.currentWork
 		return res, nil
 	}
 	return res, errors.New("No work available yet, don't panic.")
 }
 
+// workPackage assembles the work package handed to external miners, consisting
+// of the header pow-hash, the seed hash, the boundary condition ("target") and
+// the block number.
+func workPackage(work *Work) [4]string {
+	var res [4]string
+
+	block := work.Block
+
+	res[0] = block.HashNoNonce().Hex()
+	seedHash := dosash.SeedHash(block.NumberU64())
+	res[1] = common.BytesToHash(seedHash).Hex()
+	// Calculate the "target" to be returned to the external miner
+	n := big.NewInt(1)
+	n.Lsh(n, 255)
+	n.Div(n, block.Difficulty())
+	n.Lsh(n, 1)
+	res[2] = common.BytesToHash(n.Bytes()).Hex()
+	res[3] = hexutil.EncodeBig(block.Number())
+
+	return res
+}
+
+// notifyWork POSTs a new work package to all the notify URLs in the background.
+func (a *RemoteAgent) notifyWork(work *Work) {
+	blob, _ := json.Marshal(workPackage(work))
+	for _, url := range a.notifyURLs {
+		go a.sendNotification(url, blob, work)
+	}
+}
+
+// sendNotification POSTs a single work package notification to a notify URL.
+func (a *RemoteAgent) sendNotification(url string, blob []byte, work *Work) {
+	res, err := a.notifier.Post(url, "application/json", bytes.NewReader(blob))
+	if err != nil {
+		log.Warn("Failed to notify remote miner", "url", url, "number", work.Block.Number(), "err", err)
+		return
+	}
+	res.Body.Close()
+	log.Trace("Notified remote miner", "url", url, "number", work.Block.Number(), "status", res.Status)
+}
+
 // SubmitWork tries to inject a pow solution into the remote agent, returning
 // whether the solution was accepted or not (not can be both a bad pow as well as
 // any other error, like no work pending).
@@ -179,7 +234,15 @@ func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}) {
 		case work := <-workCh:
 			a.mu.Lock()
 			a.currentWork = work
+			if work != nil {
+				a.work[work.Block.HashNoNonce()] = work
+			}
 			a.mu.Unlock()
+
+			if work != nil {
+				a.notifyWork(work)
+				a.workFeed.Send(work)
+			}
 		case <-ticker.C:
 			// cleanup
 			a.mu.Lock()
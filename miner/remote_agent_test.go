@@ -0,0 +1,75 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core/types"
+)
+
+// Tests that new work packages are POSTed to all the notify URLs.
+func TestRemoteNotify(t *testing.T) {
+	// Start a few simple web servers to receive the notifications
+	sink := make(chan [4]string, 2)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var work [4]string
+		if err := json.NewDecoder(req.Body).Decode(&work); err != nil {
+			t.Errorf("failed to unmarshal work package: %v", err)
+		}
+		sink <- work
+	})
+	first, second := httptest.NewServer(handler), httptest.NewServer(handler)
+	defer first.Close()
+	defer second.Close()
+
+	// Create the remote agent and push a new work package to it
+	agent := NewRemoteAgent(nil, dosash.NewFaker(), []string{first.URL, second.URL})
+	agent.Start()
+	defer agent.Stop()
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	agent.Work() <- &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+
+	target := new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 255), header.Difficulty)
+	target.Lsh(target, 1)
+
+	want := [4]string{
+		header.HashNoNonce().Hex(),
+		common.BytesToHash(dosash.SeedHash(1)).Hex(),
+		common.BytesToHash(target.Bytes()).Hex(),
+		"0x1",
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case work := <-sink:
+			if work != want {
+				t.Errorf("notification %d: work package mismatch: have %v, want %v", i, work, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("notification %d: timed out waiting for work package", i)
+		}
+	}
+}
@@ -0,0 +1,561 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/common/hexutil"
+	"github.com/doslink/dos/consensus"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/event"
+	"github.com/doslink/dos/log"
+)
+
+const (
+	stratumProtocol       = "EthereumStratum/1.0.0" // Stratum dialect spoken by the server
+	stratumJobHistory     = 8                       // Number of recent jobs shares are accepted for
+	stratumExtranonceSize = 2                       // Number of leading nonce bytes assigned to each session
+	stratumMaxLineSize    = 4096                    // Maximum size of a single request line
+	stratumIdleTimeout    = 10 * time.Minute        // Time a session may stay silent before being dropped
+	stratumWriteTimeout   = 10 * time.Second        // Time allowed for a single message to be written
+	stratumNotifyQueue    = 16                      // Number of job notifications queued for a slow miner before dropping it
+)
+
+var (
+	// stratumBaseTarget is the share target corresponding to difficulty 1, as
+	// defined by the EthereumStratum/1.0 specification.
+	stratumBaseTarget, _ = new(big.Int).SetString("00000000ffff0000000000000000000000000000000000000000000000000000", 16)
+
+	// two256 is a big integer representing 2^256, used to convert block
+	// difficulties into PoW targets.
+	two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+)
+
+// Errors returned to Stratum miners, using the codes common among pools.
+var (
+	errStratumUnknown       = &stratumError{20, "Other/Unknown"}
+	errStratumStaleJob      = &stratumError{21, "Job not found"}
+	errStratumDuplicate     = &stratumError{22, "Duplicate share"}
+	errStratumLowDifficulty = &stratumError{23, "Low difficulty share"}
+	errStratumUnauthorized  = &stratumError{24, "Unauthorized worker"}
+	errStratumNotSubscribed = &stratumError{25, "Not subscribed"}
+)
+
+// stratumError is an error reported back to a Stratum miner.
+type stratumError struct {
+	code    int
+	message string
+}
+
+func (e *stratumError) Error() string { return e.message }
+
+// MarshalJSON implements json.Marshaler, encoding the error in the Stratum
+// [code, message, traceback] format.
+func (e *stratumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{e.code, e.message, nil})
+}
+
+// stratumRequest is a request message sent by a Stratum miner.
+type stratumRequest struct {
+	Id     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// stratumResponse is the reply to a Stratum request.
+type stratumResponse struct {
+	Id     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *stratumError   `json:"error"`
+}
+
+// stratumNotification is a message pushed to a Stratum miner unsolicited.
+type stratumNotification struct {
+	Id     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stratumJob is a work package announced to the miners, along with the shares
+// already submitted against it.
+type stratumJob struct {
+	id     string
+	work   *Work
+	clean  bool                // Whether the job invalidates all previous ones
+	target *big.Int            // PoW target a share needs to meet to seal the block
+	shares map[uint64]struct{} // Nonces already submitted, to reject duplicates
+}
+
+// notification creates the mining.notify message announcing the job.
+func (job *stratumJob) notification() *stratumNotification {
+	block := job.work.Block
+	return &stratumNotification{
+		Method: "mining.notify",
+		Params: []interface{}{
+			job.id,
+			hex.EncodeToString(dosash.SeedHash(block.NumberU64())),
+			hex.EncodeToString(block.HashNoNonce().Bytes()),
+			job.clean,
+		},
+	}
+}
+
+// StratumServer is a Stratum mining server built on top of a RemoteAgent. It
+// pushes every new work package to the connected miners, validates the shares
+// they submit against the dosash verification cache, forwards the ones sealing
+// a block to the agent and tracks the per-worker hashrate.
+type StratumServer struct {
+	agent  *RemoteAgent
+	dosash *dosash.Dosash
+
+	endpoint   string   // The host:port endpoint to listen on
+	difficulty float64  // Share difficulty announced to the miners
+	target     *big.Int // Share target derived from the difficulty
+
+	listener net.Listener
+	sessions map[*stratumSession]struct{}
+	jobs     []*stratumJob // Recently announced jobs, newest last
+	jobSeq   uint64        // Counter to derive job identifiers from
+	sessSeq  uint64        // Counter to derive session identifiers and extranonces from
+	lock     sync.Mutex
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// powWrapper is a consensus engine delegating the proof-of-work sealing to an
+// inner engine, e.g. until switching over to another consensus.
+type powWrapper interface {
+	PoW() consensus.Engine
+}
+
+// NewStratumServer creates a Stratum server listening on the given endpoint,
+// announcing work from the agent with the given share difficulty.
+func NewStratumServer(agent *RemoteAgent, endpoint string, difficulty float64) (*StratumServer, error) {
+	inner := agent.engine
+	if wrapper, ok := inner.(powWrapper); ok {
+		inner = wrapper.PoW()
+	}
+	engine, ok := inner.(*dosash.Dosash)
+	if !ok {
+		return nil, errors.New("stratum mining requires the dosash consensus engine")
+	}
+	if difficulty <= 0 {
+		return nil, fmt.Errorf("invalid stratum share difficulty: %v", difficulty)
+	}
+	target, _ := new(big.Float).Quo(new(big.Float).SetInt(stratumBaseTarget), big.NewFloat(difficulty)).Int(nil)
+
+	return &StratumServer{
+		agent:      agent,
+		dosash:     engine,
+		endpoint:   endpoint,
+		difficulty: difficulty,
+		target:     target,
+		sessions:   make(map[*stratumSession]struct{}),
+	}, nil
+}
+
+// Start opens the listening socket and starts pushing work to the miners.
+func (s *StratumServer) Start() error {
+	listener, err := net.Listen("tcp", s.endpoint)
+	if err != nil {
+		return err
+	}
+	s.listener = listener
+	s.quit = make(chan struct{})
+
+	workCh := make(chan *Work, 16)
+	sub := s.agent.SubscribeWork(workCh)
+
+	s.wg.Add(2)
+	go s.loop(workCh, sub)
+	go s.accept()
+
+	log.Info("Stratum endpoint opened", "url", fmt.Sprintf("stratum+tcp://%s", listener.Addr()))
+	return nil
+}
+
+// Stop closes the listening socket and all miner connections, blocking until
+// all goroutines of the server terminate.
+func (s *StratumServer) Stop() {
+	if s.listener == nil {
+		return
+	}
+	close(s.quit)
+	s.listener.Close()
+
+	s.lock.Lock()
+	for sess := range s.sessions {
+		sess.conn.Close()
+	}
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	log.Info("Stratum endpoint closed", "url", fmt.Sprintf("stratum+tcp://%s", s.listener.Addr()))
+	s.listener = nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *StratumServer) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// loop announces every new work package of the agent to the miners until the
+// server is stopped.
+func (s *StratumServer) loop(workCh chan *Work, sub event.Subscription) {
+	defer s.wg.Done()
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case work := <-workCh:
+			s.announce(work)
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// announce creates a new job from a work package and pushes it to all the
+// authorized miners.
+func (s *StratumServer) announce(work *Work) {
+	s.lock.Lock()
+	s.jobSeq++
+	job := &stratumJob{
+		id:     strconv.FormatUint(s.jobSeq, 16),
+		work:   work,
+		target: new(big.Int).Div(two256, work.Block.Difficulty()),
+		shares: make(map[uint64]struct{}),
+	}
+	// Jobs for earlier blocks can only produce stale shares, drop them
+	if len(s.jobs) == 0 || s.jobs[len(s.jobs)-1].work.Block.NumberU64() != work.Block.NumberU64() {
+		job.clean, s.jobs = true, nil
+	}
+	s.jobs = append(s.jobs, job)
+	if len(s.jobs) > stratumJobHistory {
+		s.jobs = s.jobs[len(s.jobs)-stratumJobHistory:]
+	}
+	sessions := make([]*stratumSession, 0, len(s.sessions))
+	for sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	s.lock.Unlock()
+
+	notify := job.notification()
+	for _, sess := range sessions {
+		if _, _, ok := sess.worker(); ok {
+			sess.queue(notify)
+		}
+	}
+}
+
+// accept accepts inbound miner connections until the listener is closed.
+func (s *StratumServer) accept() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			select {
+			case <-s.quit:
+			default:
+				log.Warn("Stratum listener failed", "err", err)
+			}
+			return
+		}
+		s.lock.Lock()
+		s.sessSeq++
+		sess := &stratumSession{
+			server:     s,
+			conn:       conn,
+			enc:        json.NewEncoder(conn),
+			id:         fmt.Sprintf("%016x", s.sessSeq),
+			extranonce: fmt.Sprintf("%0*x", 2*stratumExtranonceSize, uint16(s.sessSeq)),
+			notifyCh:   make(chan *stratumNotification, stratumNotifyQueue),
+			closed:     make(chan struct{}),
+		}
+		s.sessions[sess] = struct{}{}
+		s.lock.Unlock()
+
+		s.wg.Add(2)
+		go sess.serve()
+		go sess.notify()
+	}
+}
+
+// job retrieves a recently announced job by its identifier. The server lock
+// must be held by the caller.
+func (s *StratumServer) job(id string) *stratumJob {
+	for _, job := range s.jobs {
+		if job.id == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// current returns the most recently announced job, if any.
+func (s *StratumServer) current() *stratumJob {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.jobs) == 0 {
+		return nil
+	}
+	return s.jobs[len(s.jobs)-1]
+}
+
+// submit validates a share submitted for a job, forwarding it to the agent if
+// it seals the block.
+func (s *StratumServer) submit(worker string, jobId string, nonce uint64) *stratumError {
+	s.lock.Lock()
+	job := s.job(jobId)
+	if job == nil {
+		s.lock.Unlock()
+		return errStratumStaleJob
+	}
+	if _, ok := job.shares[nonce]; ok {
+		s.lock.Unlock()
+		return errStratumDuplicate
+	}
+	job.shares[nonce] = struct{}{}
+	s.lock.Unlock()
+
+	// Verify the share against the verification cache
+	block := job.work.Block
+	hash := block.HashNoNonce()
+
+	digest, result := s.dosash.Hashimoto(block.NumberU64(), hash, nonce)
+	value := new(big.Int).SetBytes(result[:])
+
+	if value.Cmp(job.target) <= 0 {
+		if s.agent.SubmitWork(types.EncodeNonce(nonce), digest, hash) {
+			log.Info("Stratum worker sealed block", "worker", worker, "number", block.Number(), "hash", hash)
+		}
+		return nil
+	}
+	if value.Cmp(s.target) > 0 {
+		return errStratumLowDifficulty
+	}
+	log.Trace("Accepted stratum share", "worker", worker, "job", jobId, "nonce", nonce)
+	return nil
+}
+
+// stratumSession is a connection to a single Stratum miner.
+type stratumSession struct {
+	server *StratumServer
+	conn   net.Conn
+
+	id         string // Subscription identifier of the session
+	extranonce string // Hex encoded nonce prefix assigned to the session
+
+	enc       *json.Encoder
+	writeLock sync.Mutex
+
+	notifyCh chan *stratumNotification // Job notifications waiting to be sent to the miner
+	closed   chan struct{}             // Channel closed when the session terminates
+
+	subscribed bool
+	authorized bool
+	workerName string      // Name of the worker authorized on the session
+	workerId   common.Hash // Identifier to track the hashrate of the worker by
+	lock       sync.RWMutex
+}
+
+// serve reads and answers requests from the miner until the connection is
+// closed or fails.
+func (sess *stratumSession) serve() {
+	defer sess.server.wg.Done()
+	defer func() {
+		sess.server.lock.Lock()
+		delete(sess.server.sessions, sess)
+		sess.server.lock.Unlock()
+
+		sess.conn.Close()
+		close(sess.closed)
+	}()
+	log.Debug("Stratum miner connected", "addr", sess.conn.RemoteAddr())
+
+	reader := bufio.NewReaderSize(sess.conn, stratumMaxLineSize)
+	for {
+		sess.conn.SetReadDeadline(time.Now().Add(stratumIdleTimeout))
+		line, err := reader.ReadSlice('\n')
+		if err != nil {
+			log.Debug("Stratum miner disconnected", "addr", sess.conn.RemoteAddr(), "err", err)
+			return
+		}
+		var req stratumRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			log.Debug("Invalid stratum request", "addr", sess.conn.RemoteAddr(), "err", err)
+			return
+		}
+		result, serr := sess.handle(&req)
+		if err := sess.send(&stratumResponse{Id: req.Id, Result: result, Error: serr}); err != nil {
+			log.Debug("Failed to reply to stratum miner", "addr", sess.conn.RemoteAddr(), "err", err)
+			return
+		}
+		// Newly authorized workers need to be told what to mine
+		if req.Method == "mining.authorize" && serr == nil {
+			sess.send(&stratumNotification{Method: "mining.set_difficulty", Params: []interface{}{sess.server.difficulty}})
+			if job := sess.server.current(); job != nil {
+				sess.queue(job.notification())
+			}
+		}
+	}
+}
+
+// notify writes the queued job notifications to the miner until the session
+// terminates, so a slow miner doesn't hold up announcing jobs to the others.
+func (sess *stratumSession) notify() {
+	defer sess.server.wg.Done()
+
+	for {
+		select {
+		case msg := <-sess.notifyCh:
+			if err := sess.send(msg); err != nil {
+				log.Debug("Failed to notify stratum miner", "addr", sess.conn.RemoteAddr(), "err", err)
+				sess.conn.Close()
+				return
+			}
+		case <-sess.closed:
+			return
+		}
+	}
+}
+
+// queue schedules a job notification to be sent to the miner, dropping the
+// miner if it fell too far behind on reading them.
+func (sess *stratumSession) queue(msg *stratumNotification) {
+	select {
+	case sess.notifyCh <- msg:
+	default:
+		log.Debug("Dropping lagging stratum miner", "addr", sess.conn.RemoteAddr())
+		sess.conn.Close()
+	}
+}
+
+// handle executes a single Stratum request.
+func (sess *stratumSession) handle(req *stratumRequest) (interface{}, *stratumError) {
+	switch req.Method {
+	case "mining.subscribe":
+		sess.lock.Lock()
+		sess.subscribed = true
+		sess.lock.Unlock()
+
+		return []interface{}{[]string{"mining.notify", sess.id, stratumProtocol}, sess.extranonce}, nil
+
+	case "mining.extranonce.subscribe":
+		// Extranonces never change during a session, nothing to do
+		return true, nil
+
+	case "mining.authorize":
+		var worker string
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &worker) != nil || worker == "" {
+			return nil, errStratumUnauthorized
+		}
+		sess.lock.Lock()
+		defer sess.lock.Unlock()
+
+		if !sess.subscribed {
+			return nil, errStratumNotSubscribed
+		}
+		sess.authorized, sess.workerName = true, worker
+		sess.workerId = crypto.Keccak256Hash([]byte(worker))
+
+		log.Debug("Stratum worker authorized", "addr", sess.conn.RemoteAddr(), "worker", worker)
+		return true, nil
+
+	case "mining.submit":
+		worker, _, ok := sess.worker()
+		if !ok {
+			return nil, errStratumUnauthorized
+		}
+		var jobId, nonceHex string
+		if len(req.Params) < 3 || json.Unmarshal(req.Params[1], &jobId) != nil || json.Unmarshal(req.Params[2], &nonceHex) != nil {
+			return nil, errStratumUnknown
+		}
+		nonce, err := sess.nonce(nonceHex)
+		if err != nil {
+			return nil, errStratumUnknown
+		}
+		if err := sess.server.submit(worker, jobId, nonce); err != nil {
+			return nil, err
+		}
+		return true, nil
+
+	case "eth_submitHashrate":
+		_, id, ok := sess.worker()
+		if !ok {
+			return nil, errStratumUnauthorized
+		}
+		var rate hexutil.Uint64
+		if len(req.Params) == 0 || json.Unmarshal(req.Params[0], &rate) != nil {
+			return nil, errStratumUnknown
+		}
+		sess.server.agent.SubmitHashrate(id, uint64(rate))
+		return true, nil
+
+	default:
+		return nil, errStratumUnknown
+	}
+}
+
+// nonce reconstructs the full nonce of a share from the part searched by the
+// miner, accepting nonces already including the extranonce of the session too.
+func (sess *stratumSession) nonce(input string) (uint64, error) {
+	input = strings.TrimPrefix(input, "0x")
+	switch {
+	case len(input) == 16 && strings.HasPrefix(input, sess.extranonce):
+	case len(input) == 16-len(sess.extranonce):
+		input = sess.extranonce + input
+	default:
+		return 0, fmt.Errorf("invalid nonce length %d", len(input))
+	}
+	return strconv.ParseUint(input, 16, 64)
+}
+
+// worker returns the name and hashrate identifier of the worker authorized on
+// the session, along with whether there is one at all.
+func (sess *stratumSession) worker() (string, common.Hash, bool) {
+	sess.lock.RLock()
+	defer sess.lock.RUnlock()
+
+	return sess.workerName, sess.workerId, sess.authorized
+}
+
+// send writes a single message to the miner.
+func (sess *stratumSession) send(msg interface{}) error {
+	sess.writeLock.Lock()
+	defer sess.writeLock.Unlock()
+
+	sess.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+	return sess.enc.Encode(msg)
+}
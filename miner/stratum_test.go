@@ -0,0 +1,254 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/consensus/clique"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/consensus/transition"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/params"
+)
+
+// stratumMessage is a message received by a Stratum test client.
+type stratumMessage struct {
+	Id     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  []interface{}   `json:"error"`
+}
+
+// stratumClient is a minimal Stratum miner talking to a test server.
+type stratumClient struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+	id     int
+}
+
+func newStratumClient(t *testing.T, addr net.Addr) *stratumClient {
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("failed to connect to stratum server: %v", err)
+	}
+	return &stratumClient{t: t, conn: conn, reader: bufio.NewReader(conn)}
+}
+
+// read waits for the next message pushed by the server.
+func (c *stratumClient) read() *stratumMessage {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		c.t.Fatalf("failed to read stratum message: %v", err)
+	}
+	msg := new(stratumMessage)
+	if err := json.Unmarshal(line, msg); err != nil {
+		c.t.Fatalf("failed to decode stratum message %q: %v", line, err)
+	}
+	return msg
+}
+
+// call sends a request and waits for its response.
+func (c *stratumClient) call(method string, params ...interface{}) *stratumMessage {
+	c.id++
+	req, _ := json.Marshal(map[string]interface{}{"id": c.id, "method": method, "params": params})
+	if _, err := c.conn.Write(append(req, '\n')); err != nil {
+		c.t.Fatalf("failed to send stratum request: %v", err)
+	}
+	msg := c.read()
+	if msg.Id == nil || *msg.Id != c.id {
+		c.t.Fatalf("%s: response id mismatch: have %v, want %d", method, msg.Id, c.id)
+	}
+	return msg
+}
+
+// expect waits for a notification of the given method, returning its params.
+func (c *stratumClient) expect(method string) []interface{} {
+	msg := c.read()
+	if msg.Method != method {
+		c.t.Fatalf("notification method mismatch: have %q, want %q", msg.Method, method)
+	}
+	var params []interface{}
+	json.Unmarshal(msg.Params, &params)
+	return params
+}
+
+// checkCode verifies that a response failed with the given Stratum error code.
+func checkCode(t *testing.T, msg *stratumMessage, code float64) {
+	if len(msg.Error) == 0 || msg.Error[0] != code {
+		t.Errorf("error code mismatch: have %v, want %v", msg.Error, code)
+	}
+}
+
+// Tests that work is pushed to Stratum miners and that their shares are
+// validated, with block sealing ones forwarded to the remote agent.
+func TestStratumServer(t *testing.T) {
+	agent := NewRemoteAgent(nil, dosash.NewTester(), nil)
+	results := make(chan *Result, 1)
+	agent.SetReturnCh(results)
+	agent.Start()
+	defer agent.Stop()
+
+	// Accept any share, but make sealing a block practically impossible
+	server, err := NewStratumServer(agent, "127.0.0.1:0", 1e-12)
+	if err != nil {
+		t.Fatalf("failed to create stratum server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start stratum server: %v", err)
+	}
+	defer server.Stop()
+
+	client := newStratumClient(t, server.Addr())
+	defer client.conn.Close()
+
+	if res := client.call("mining.authorize", "rig", "x"); res.Error == nil {
+		t.Fatalf("authorized before subscribing")
+	}
+	var subscription []interface{}
+	json.Unmarshal(client.call("mining.subscribe", "tester", stratumProtocol).Result, &subscription)
+	if len(subscription) != 2 || subscription[1] != "0001" {
+		t.Fatalf("subscription mismatch: have %v, want extranonce 0001", subscription)
+	}
+	if res := client.call("mining.authorize", "rig", "x"); string(res.Result) != "true" {
+		t.Fatalf("failed to authorize: %v", res.Error)
+	}
+	if params := client.expect("mining.set_difficulty"); len(params) != 1 || params[0] != 1e-12 {
+		t.Fatalf("share difficulty mismatch: have %v, want %v", params, 1e-12)
+	}
+	// Push a new work package and check that it's announced
+	header := &types.Header{Number: big.NewInt(1), Difficulty: new(big.Int).Lsh(big.NewInt(1), 40)}
+	agent.Work() <- &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+
+	params := client.expect("mining.notify")
+	if len(params) != 4 || params[2] != header.HashNoNonce().Hex()[2:] || params[3] != true {
+		t.Fatalf("job announcement mismatch: have %v", params)
+	}
+	job := params[0].(string)
+
+	// Submit a few shares and make sure they are validated
+	if res := client.call("mining.submit", "rig", job, "000000000001"); string(res.Result) != "true" {
+		t.Errorf("valid share rejected: %v", res.Error)
+	}
+	checkCode(t, client.call("mining.submit", "rig", job, "0001000000000001"), 22)
+	checkCode(t, client.call("mining.submit", "rig", "ffff", "000000000002"), 21)
+	checkCode(t, client.call("mining.submit", "rig", job, "0002000000000002"), 20)
+
+	if res := client.call("eth_submitHashrate", "0x100", "0x00"); string(res.Result) != "true" {
+		t.Errorf("hashrate rejected: %v", res.Error)
+	}
+	if rate := agent.GetHashRate(); rate != 256 {
+		t.Errorf("hashrate mismatch: have %d, want %d", rate, 256)
+	}
+	// Push an easy package for the same block and ensure shares seal it
+	header = &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(1), Extra: []byte("easy")}
+	agent.Work() <- &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+
+	params = client.expect("mining.notify")
+	if params[3] != false {
+		t.Errorf("job for the same block announced as clean")
+	}
+	if res := client.call("mining.submit", "rig", params[0], "000000000003"); string(res.Result) != "true" {
+		t.Errorf("sealing share rejected: %v", res.Error)
+	}
+	select {
+	case result := <-results:
+		if result.Block.HashNoNonce() != header.HashNoNonce() || result.Block.Nonce() != 0x0001000000000003 {
+			t.Errorf("sealed block mismatch: have %x/%d", result.Block.HashNoNonce(), result.Block.Nonce())
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("sealed block not forwarded to the agent")
+	}
+}
+
+// Tests that a miner not reading its notifications doesn't hold up announcing
+// jobs to the others, and that it's dropped once it falls too far behind.
+func TestStratumSlowMiner(t *testing.T) {
+	agent := NewRemoteAgent(nil, dosash.NewTester(), nil)
+	agent.Start()
+	defer agent.Stop()
+
+	server, err := NewStratumServer(agent, "127.0.0.1:0", 1)
+	if err != nil {
+		t.Fatalf("failed to create stratum server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("failed to start stratum server: %v", err)
+	}
+	defer server.Stop()
+
+	// Register an authorized miner whose connection is never read from
+	stalled, remote := net.Pipe()
+	defer remote.Close()
+
+	slow := &stratumSession{
+		server:     server,
+		conn:       stalled,
+		enc:        json.NewEncoder(stalled),
+		notifyCh:   make(chan *stratumNotification, stratumNotifyQueue),
+		closed:     make(chan struct{}),
+		authorized: true,
+	}
+	server.lock.Lock()
+	server.sessions[slow] = struct{}{}
+	server.lock.Unlock()
+
+	client := newStratumClient(t, server.Addr())
+	defer client.conn.Close()
+
+	client.call("mining.subscribe", "tester", stratumProtocol)
+	client.call("mining.authorize", "rig", "x")
+	client.expect("mining.set_difficulty")
+
+	// Announce more jobs than the slow miner may lag behind by
+	for i := 0; i <= stratumNotifyQueue; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i + 1)), Difficulty: big.NewInt(1)}
+		agent.Work() <- &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+
+		client.expect("mining.notify")
+	}
+	remote.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := remote.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("lagging miner not dropped: %v", err)
+	}
+}
+
+// Tests that Stratum mining is available while a chain is still sealed by
+// proof-of-work before switching over to clique.
+func TestStratumTransition(t *testing.T) {
+	config := *params.AllDosashProtocolChanges
+	config.CliqueBlock = big.NewInt(100)
+	config.Clique = &params.CliqueConfig{Period: 1, Epoch: 30000}
+
+	engine := transition.New(&config, dosash.NewTester(), clique.New(config.Clique, dosdb.NewMemDatabase()))
+	if _, err := NewStratumServer(NewRemoteAgent(nil, engine, nil), "127.0.0.1:0", 1); err != nil {
+		t.Fatalf("failed to create stratum server: %v", err)
+	}
+	if _, err := NewStratumServer(NewRemoteAgent(nil, clique.New(config.Clique, dosdb.NewMemDatabase()), nil), "127.0.0.1:0", 1); err == nil {
+		t.Fatalf("stratum server created for clique")
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/types"
+)
+
+// Transaction orderings selectable for building blocks.
+const (
+	TxOrderPrice    = "price"    // Highest gas price first (default)
+	TxOrderFIFO     = "fifo"     // Earliest arrival first
+	TxOrderPriority = "priority" // Priority senders first, then highest gas price
+)
+
+// TxSet is a set of transactions retrievable in a policy specific order, while
+// honouring the nonce order of each account.
+type TxSet interface {
+	// Peek returns the next transaction to include, or nil if none remain.
+	Peek() *types.Transaction
+
+	// Shift replaces the current transaction with the next one from the same
+	// account.
+	Shift()
+
+	// Pop removes the current transaction, discarding all subsequent ones from
+	// the same account.
+	Pop()
+}
+
+// inclusionTracker is implemented by transaction sets that need to know which
+// of their transactions made it into the block, as opposed to being skipped.
+type inclusionTracker interface {
+	// Included records that the current transaction was taken into the block.
+	Included()
+}
+
+// TxPolicy decides which pending transactions are included into the blocks
+// being built, and in which order.
+type TxPolicy interface {
+	// Order creates a transaction set from the pending transactions of the
+	// pool, given the time each of them was first seen locally. The input maps
+	// are reowned so the caller should not interact any more with them after
+	// providing them.
+	Order(signer types.Signer, pending map[common.Address]types.Transactions, arrivals map[common.Hash]time.Time) TxSet
+}
+
+// NewTxPolicy creates the transaction policy for the given ordering, taking at
+// most limit transactions from every sender into a block if non-zero.
+func NewTxPolicy(order string, priority []common.Address, limit int) (TxPolicy, error) {
+	var policy TxPolicy
+	switch order {
+	case "", TxOrderPrice:
+		policy = PriceTxPolicy{}
+	case TxOrderFIFO:
+		policy = FIFOTxPolicy{}
+	case TxOrderPriority:
+		policy = NewPriorityTxPolicy(priority)
+	default:
+		return nil, fmt.Errorf("unknown transaction ordering %q", order)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("invalid per-sender transaction limit %d", limit)
+	}
+	if limit > 0 {
+		policy = &CappedTxPolicy{Policy: policy, Limit: limit}
+	}
+	return policy, nil
+}
+
+// PriceTxPolicy orders transactions by gas price, the highest paying first.
+type PriceTxPolicy struct{}
+
+// Order implements TxPolicy.
+func (PriceTxPolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions, arrivals map[common.Hash]time.Time) TxSet {
+	return types.NewTransactionsByPriceAndNonce(signer, pending)
+}
+
+// FIFOTxPolicy orders transactions by the time they were first seen locally,
+// the earliest first.
+type FIFOTxPolicy struct{}
+
+// Order implements TxPolicy.
+func (FIFOTxPolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions, arrivals map[common.Hash]time.Time) TxSet {
+	return newTxHeadSet(signer, pending, func(a, b *types.Transaction) bool {
+		return arrivals[a.Hash()].Before(arrivals[b.Hash()])
+	})
+}
+
+// PriorityTxPolicy orders the transactions of a set of priority senders before
+// all others, falling back to gas price ordering within both groups.
+type PriorityTxPolicy struct {
+	senders map[common.Address]struct{}
+}
+
+// NewPriorityTxPolicy creates a policy prioritising the given senders.
+func NewPriorityTxPolicy(senders []common.Address) *PriorityTxPolicy {
+	policy := &PriorityTxPolicy{senders: make(map[common.Address]struct{})}
+	for _, sender := range senders {
+		policy.senders[sender] = struct{}{}
+	}
+	return policy
+}
+
+// Order implements TxPolicy.
+func (p *PriorityTxPolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions, arrivals map[common.Hash]time.Time) TxSet {
+	return newTxHeadSet(signer, pending, func(a, b *types.Transaction) bool {
+		fromA, _ := types.Sender(signer, a)
+		fromB, _ := types.Sender(signer, b)
+
+		_, prioA := p.senders[fromA]
+		_, prioB := p.senders[fromB]
+		if prioA != prioB {
+			return prioA
+		}
+		return a.GasPrice().Cmp(b.GasPrice()) > 0
+	})
+}
+
+// CappedTxPolicy wraps another policy, taking at most a limited number of
+// transactions from every sender into a single block.
+type CappedTxPolicy struct {
+	Policy TxPolicy // Policy deciding the order of the transactions
+	Limit  int      // Maximum number of transactions taken from a sender
+}
+
+// Order implements TxPolicy.
+func (p *CappedTxPolicy) Order(signer types.Signer, pending map[common.Address]types.Transactions, arrivals map[common.Hash]time.Time) TxSet {
+	return &cappedTxSet{
+		TxSet:  p.Policy.Order(signer, pending, arrivals),
+		signer: signer,
+		limit:  p.Limit,
+		taken:  make(map[common.Address]int),
+	}
+}
+
+// cappedTxSet is a transaction set dropping a sender once the given number of
+// its transactions have been included.
+type cappedTxSet struct {
+	TxSet
+	signer types.Signer
+	limit  int
+	taken  map[common.Address]int
+}
+
+// Included implements inclusionTracker, counting the transaction against the
+// limit of its sender.
+func (s *cappedTxSet) Included() {
+	from, _ := types.Sender(s.signer, s.Peek())
+	s.taken[from]++
+}
+
+// Shift implements TxSet, popping the sender instead if its limit is reached.
+func (s *cappedTxSet) Shift() {
+	from, _ := types.Sender(s.signer, s.Peek())
+	if s.taken[from] >= s.limit {
+		s.TxSet.Pop()
+		return
+	}
+	s.TxSet.Shift()
+}
+
+// txHeadSet is a transaction set retrieving the heads of the accounts' nonce
+// sorted transaction lists in the order defined by a comparison function.
+type txHeadSet struct {
+	txs    map[common.Address]types.Transactions // Per account nonce-sorted list of transactions
+	heads  txHeads                               // Next transaction for each unique account
+	signer types.Signer                          // Signer for the set of transactions
+}
+
+// newTxHeadSet creates a transaction set ordering the account heads by less.
+func newTxHeadSet(signer types.Signer, txs map[common.Address]types.Transactions, less func(a, b *types.Transaction) bool) *txHeadSet {
+	heads := txHeads{txs: make([]*types.Transaction, 0, len(txs)), less: less}
+	for from, accTxs := range txs {
+		heads.txs = append(heads.txs, accTxs[0])
+		// Ensure the sender address is from the signer
+		acc, _ := types.Sender(signer, accTxs[0])
+		txs[acc] = accTxs[1:]
+		if from != acc {
+			delete(txs, from)
+		}
+	}
+	heap.Init(&heads)
+
+	return &txHeadSet{txs: txs, heads: heads, signer: signer}
+}
+
+// Peek implements TxSet.
+func (t *txHeadSet) Peek() *types.Transaction {
+	if len(t.heads.txs) == 0 {
+		return nil
+	}
+	return t.heads.txs[0]
+}
+
+// Shift implements TxSet.
+func (t *txHeadSet) Shift() {
+	acc, _ := types.Sender(t.signer, t.heads.txs[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads.txs[0], t.txs[acc] = txs[0], txs[1:]
+		heap.Fix(&t.heads, 0)
+	} else {
+		heap.Pop(&t.heads)
+	}
+}
+
+// Pop implements TxSet.
+func (t *txHeadSet) Pop() {
+	heap.Pop(&t.heads)
+}
+
+// txHeads implements heap.Interface over the account head transactions.
+type txHeads struct {
+	txs  []*types.Transaction
+	less func(a, b *types.Transaction) bool
+}
+
+func (h txHeads) Len() int           { return len(h.txs) }
+func (h txHeads) Less(i, j int) bool { return h.less(h.txs[i], h.txs[j]) }
+func (h txHeads) Swap(i, j int)      { h.txs[i], h.txs[j] = h.txs[j], h.txs[i] }
+
+func (h *txHeads) Push(x interface{}) {
+	h.txs = append(h.txs, x.(*types.Transaction))
+}
+
+func (h *txHeads) Pop() interface{} {
+	old := h.txs
+	n := len(old)
+	x := old[n-1]
+	h.txs = old[0 : n-1]
+	return x
+}
@@ -0,0 +1,143 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/crypto"
+)
+
+// policyTx is a transaction to create for testing a transaction policy.
+type policyTx struct {
+	key   int   // Index of the sending account
+	nonce int   // Nonce of the transaction
+	price int64 // Gas price of the transaction
+}
+
+// orderTxs creates the given transactions in order, arranges them with the
+// policy and returns the indexes of the transactions the way they were taken.
+// The transactions listed in skip are shifted over without being included.
+func orderTxs(policy TxPolicy, keys []*ecdsa.PrivateKey, specs []policyTx, skip ...int) []int {
+	signer := types.HomesteadSigner{}
+
+	var (
+		pending  = make(map[common.Address]types.Transactions)
+		arrivals = make(map[common.Hash]time.Time)
+		index    = make(map[common.Hash]int)
+		start    = time.Now()
+	)
+	for i, spec := range specs {
+		tx, _ := types.SignTx(types.NewTransaction(uint64(spec.nonce), common.Address{}, big.NewInt(0), 21000, big.NewInt(spec.price), nil), signer, keys[spec.key])
+		from := crypto.PubkeyToAddress(keys[spec.key].PublicKey)
+		pending[from] = append(pending[from], tx)
+		arrivals[tx.Hash()] = start.Add(time.Duration(i) * time.Millisecond)
+		index[tx.Hash()] = i
+	}
+	skipped := make(map[int]bool)
+	for _, i := range skip {
+		skipped[i] = true
+	}
+	var order []int
+	for set := policy.Order(signer, pending, arrivals); set.Peek() != nil; set.Shift() {
+		i := index[set.Peek().Hash()]
+		order = append(order, i)
+
+		if tracker, ok := set.(inclusionTracker); ok && !skipped[i] {
+			tracker.Included()
+		}
+	}
+	return order
+}
+
+// Tests that the different transaction policies order transactions correctly.
+func TestTxPolicies(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	specs := []policyTx{
+		{key: 0, nonce: 0, price: 1},
+		{key: 1, nonce: 0, price: 3},
+		{key: 0, nonce: 1, price: 5},
+		{key: 2, nonce: 0, price: 2},
+		{key: 1, nonce: 1, price: 6},
+		{key: 2, nonce: 1, price: 4},
+	}
+	tests := []struct {
+		order    string
+		priority []int
+		limit    int
+		want     []int
+	}{
+		{order: TxOrderPrice, want: []int{1, 4, 3, 5, 0, 2}},
+		{order: TxOrderFIFO, want: []int{0, 1, 2, 3, 4, 5}},
+		{order: TxOrderPriority, priority: []int{0}, want: []int{0, 2, 1, 4, 3, 5}},
+		{order: TxOrderPrice, limit: 1, want: []int{1, 3, 0}},
+		{order: TxOrderFIFO, limit: 1, want: []int{0, 1, 3}},
+	}
+	for i, tt := range tests {
+		var priority []common.Address
+		for _, key := range tt.priority {
+			priority = append(priority, crypto.PubkeyToAddress(keys[key].PublicKey))
+		}
+		policy, err := NewTxPolicy(tt.order, priority, tt.limit)
+		if err != nil {
+			t.Fatalf("test %d: failed to create policy: %v", i, err)
+		}
+		have := orderTxs(policy, keys, specs)
+		if len(have) != len(tt.want) {
+			t.Errorf("test %d: order mismatch: have %v, want %v", i, have, tt.want)
+			continue
+		}
+		for j := range have {
+			if have[j] != tt.want[j] {
+				t.Errorf("test %d: order mismatch: have %v, want %v", i, have, tt.want)
+				break
+			}
+		}
+	}
+	if _, err := NewTxPolicy("random", nil, 0); err == nil {
+		t.Errorf("unknown ordering accepted")
+	}
+}
+
+// Tests that transactions skipped over, e.g. for a too low nonce, don't count
+// against the per-sender limit of a capped policy.
+func TestCappedTxPolicySkips(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	specs := []policyTx{
+		{key: 0, nonce: 0, price: 1},
+		{key: 0, nonce: 1, price: 1},
+		{key: 0, nonce: 2, price: 1},
+		{key: 0, nonce: 3, price: 1},
+	}
+	policy, err := NewTxPolicy(TxOrderPrice, nil, 2)
+	if err != nil {
+		t.Fatalf("failed to create policy: %v", err)
+	}
+	want := []int{0, 1, 2}
+	if have := orderTxs(policy, []*ecdsa.PrivateKey{key}, specs, 0); !reflect.DeepEqual(have, want) {
+		t.Errorf("order mismatch: have %v, want %v", have, want)
+	}
+}
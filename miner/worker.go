@@ -49,6 +49,13 @@ const (
 	chainHeadChanSize = 10
 	// chainSideChanSize is the size of channel listening to ChainSideEvent.
 	chainSideChanSize = 10
+	// minRecommitInterval is the minimal time interval to recreate the mining block
+	// with any newly arrived transactions.
+	minRecommitInterval = 1 * time.Second
+	// maxWithholdInterval is the maximal time empty work packages are withheld from
+	// sealing on top of the same parent while none of the pending transactions can
+	// be included.
+	maxWithholdInterval = 30 * time.Second
 )
 
 // Agent can register themself with the worker
@@ -113,6 +120,10 @@ type worker struct {
 
 	coinbase common.Address
 	extra    []byte
+	txPolicy TxPolicy // Policy ordering the pending transactions into blocks
+
+	arrivalMu sync.Mutex
+	arrivals  map[common.Hash]time.Time // Time each pending transaction was first seen locally
 
 	currentMu sync.Mutex
 	current   *Work
@@ -126,9 +137,21 @@ type worker struct {
 
 	unconfirmed *unconfirmedBlocks // set of locally mined blocks pending canonicalness confirmations
 
+	recommitCh chan time.Duration // Channel to update the interval of rebuilding the work package
+	withholdCh chan struct{}      // Channel to rebuild the work package once withholding times out
+	exitCh     chan struct{}      // Channel closed when the update loop terminates
+
+	maxWithhold    time.Duration // Maximal time to withhold empty work packages on top of the same parent
+	withholdParent common.Hash   // Parent of the empty work packages being withheld
+	withholdStart  time.Time     // Time the first empty work package on top of withholdParent was withheld
+	withholdTimer  *time.Timer   // Timer signalling withholdCh once withholding times out
+
 	// atomic status counters
-	mining int32
-	atWork int32
+	mining   int32
+	atWork   int32
+	newTxs   int32 // Number of transactions arrived since the last work package was created
+	noempty  int32 // Whether to withhold empty work packages from sealing while transactions are pending
+	withheld int32 // Whether the current work package was withheld from sealing for being empty
 }
 
 func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase common.Address, dos Backend, mux *event.TypeMux) *worker {
@@ -146,8 +169,14 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase com
 		proc:           dos.BlockChain().Validator(),
 		possibleUncles: make(map[common.Hash]*types.Block),
 		coinbase:       coinbase,
+		txPolicy:       PriceTxPolicy{},
+		arrivals:       make(map[common.Hash]time.Time),
 		agents:         make(map[Agent]struct{}),
 		unconfirmed:    newUnconfirmedBlocks(dos.BlockChain(), miningLogAtDepth),
+		recommitCh:     make(chan time.Duration),
+		withholdCh:     make(chan struct{}, 1),
+		exitCh:         make(chan struct{}),
+		maxWithhold:    maxWithholdInterval,
 	}
 	// Subscribe TxPreEvent for tx pool
 	worker.txSub = dos.TxPool().SubscribeTxPreEvent(worker.txCh)
@@ -174,6 +203,62 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+// setTxPolicy updates the policy ordering the pending transactions into the
+// blocks being built.
+func (self *worker) setTxPolicy(policy TxPolicy) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.txPolicy = policy
+}
+
+// arrivalTimes returns the time each pending transaction was first seen locally,
+// forgetting about the transactions no longer pending. Transactions that were
+// never announced to the worker are considered to arrive now.
+func (self *worker) arrivalTimes(pending map[common.Address]types.Transactions) map[common.Hash]time.Time {
+	self.arrivalMu.Lock()
+	defer self.arrivalMu.Unlock()
+
+	var (
+		now      = time.Now()
+		arrivals = make(map[common.Hash]time.Time)
+		tracked  = make(map[common.Hash]time.Time)
+	)
+	for _, txs := range pending {
+		for _, tx := range txs {
+			arrived, ok := self.arrivals[tx.Hash()]
+			if !ok {
+				arrived = now
+			}
+			arrivals[tx.Hash()], tracked[tx.Hash()] = arrived, arrived
+		}
+	}
+	self.arrivals = tracked
+	return arrivals
+}
+
+// setRecommitInterval updates the interval of rebuilding the work package with
+// the latest transaction pool contents while mining. Zero disables recommits.
+func (self *worker) setRecommitInterval(interval time.Duration) {
+	if interval > 0 && interval < minRecommitInterval {
+		log.Warn("Sanitizing miner recommit interval", "provided", interval, "updated", minRecommitInterval)
+		interval = minRecommitInterval
+	}
+	select {
+	case self.recommitCh <- interval:
+	case <-self.exitCh:
+	}
+}
+
+// setNoEmpty sets whether empty work packages are withheld from sealing while
+// the transaction pool has pending transactions.
+func (self *worker) setNoEmpty(noempty bool) {
+	if noempty {
+		atomic.StoreInt32(&self.noempty, 1)
+	} else {
+		atomic.StoreInt32(&self.noempty, 0)
+	}
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB) {
 	if atomic.LoadInt32(&self.mining) == 0 {
 		// return a snapshot to avoid contention on currentMu mutex
@@ -241,10 +326,19 @@ func (self *worker) unregister(agent Agent) {
 }
 
 func (self *worker) update() {
+	defer close(self.exitCh)
 	defer self.txSub.Unsubscribe()
 	defer self.chainHeadSub.Unsubscribe()
 	defer self.chainSideSub.Unsubscribe()
 
+	// The recommit timer is disarmed until an interval is configured
+	var (
+		interval time.Duration
+		recommit = time.NewTimer(0)
+	)
+	defer recommit.Stop()
+	<-recommit.C
+
 	for {
 		// A real event arrived, process interesting content
 		select {
@@ -252,6 +346,42 @@ func (self *worker) update() {
 		case <-self.chainHeadCh:
 			self.commitNewWork()
 
+		// Handle recommit interval updates
+		case interval = <-self.recommitCh:
+			if !recommit.Stop() {
+				select {
+				case <-recommit.C:
+				default:
+				}
+			}
+			if interval > 0 {
+				recommit.Reset(interval)
+			}
+
+		// Rebuild the work package if transactions arrived since it was created
+		case <-recommit.C:
+			elapsed := interval
+			self.currentMu.Lock()
+			if self.current != nil {
+				elapsed = time.Since(self.current.createdAt)
+			}
+			self.currentMu.Unlock()
+
+			if elapsed < interval {
+				recommit.Reset(interval - elapsed)
+				continue
+			}
+			if atomic.LoadInt32(&self.mining) == 1 && atomic.LoadInt32(&self.newTxs) > 0 {
+				self.commitNewWork()
+			}
+			recommit.Reset(interval)
+
+		// Seal an empty block once it was withheld for too long
+		case <-self.withholdCh:
+			if atomic.LoadInt32(&self.withheld) == 1 {
+				self.commitNewWork()
+			}
+
 		// Handle ChainSideEvent
 		case ev := <-self.chainSideCh:
 			self.uncleMu.Lock()
@@ -260,6 +390,12 @@ func (self *worker) update() {
 
 		// Handle TxPreEvent
 		case ev := <-self.txCh:
+			self.arrivalMu.Lock()
+			if _, ok := self.arrivals[ev.Tx.Hash()]; !ok {
+				self.arrivals[ev.Tx.Hash()] = time.Now()
+			}
+			self.arrivalMu.Unlock()
+
 			// Apply transaction to the pending state if we're not mining
 			if atomic.LoadInt32(&self.mining) == 0 {
 				self.currentMu.Lock()
@@ -271,8 +407,13 @@ func (self *worker) update() {
 				self.updateSnapshot()
 				self.currentMu.Unlock()
 			} else {
+				atomic.AddInt32(&self.newTxs, 1)
+
 				// If we're mining, but nothing is being processed, wake on new transactions
-				if self.config.Clique != nil && self.config.Clique.Period == 0 {
+				if (self.config.Clique != nil && self.config.Clique.Period == 0) || (self.config.Ibft != nil && self.config.Ibft.Period == 0) {
+					self.commitNewWork()
+				} else if atomic.LoadInt32(&self.withheld) == 1 {
+					// An empty block was withheld, seal one with the new transaction
 					self.commitNewWork()
 				}
 			}
@@ -342,13 +483,16 @@ func (self *worker) wait() {
 	}
 }
 
-// push sends a new work task to currently live miner agents.
+// push sends a new work task to currently live miner agents. A nil work task
+// makes the agents abandon the previous one without starting a new one.
 func (self *worker) push(work *Work) {
 	if atomic.LoadInt32(&self.mining) != 1 {
 		return
 	}
 	for agent := range self.agents {
-		atomic.AddInt32(&self.atWork, 1)
+		if work != nil {
+			atomic.AddInt32(&self.atWork, 1)
+		}
 		if ch := agent.Work(); ch != nil {
 			ch <- work
 		}
@@ -449,14 +593,23 @@ func (self *worker) commitNewWork() {
 	if self.config.DAOForkSupport && self.config.DAOForkBlock != nil && self.config.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(work.state)
 	}
+	atomic.StoreInt32(&self.newTxs, 0)
+
 	pending, err := self.dos.TxPool().Pending()
 	if err != nil {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+	txs := self.txPolicy.Order(self.current.signer, pending, self.arrivalTimes(pending))
 	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
 
+	// Withhold empty blocks from sealing if requested while transactions are pending,
+	// but only for a limited time in case none of them can be included
+	withhold := atomic.LoadInt32(&self.noempty) == 1 && work.tcount == 0 && len(pending) > 0
+	if withhold {
+		withhold = self.scheduleWithhold(parent.Hash())
+	}
+
 	// compute uncles for the new block.
 	var (
 		uncles    []*types.Header
@@ -486,13 +639,47 @@ func (self *worker) commitNewWork() {
 	}
 	// We only care about logging if we're actually mining.
 	if atomic.LoadInt32(&self.mining) == 1 {
-		log.Info("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(time.Since(tstart)))
+		if withhold {
+			log.Debug("Withholding empty mining work", "number", work.Block.Number(), "pending", len(pending))
+		} else {
+			log.Info("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(time.Since(tstart)))
+		}
 		self.unconfirmed.Shift(work.Block.NumberU64() - 1)
 	}
-	self.push(work)
+	if withhold {
+		// Stop the agents sealing the previous work, which is likely stale
+		atomic.StoreInt32(&self.withheld, 1)
+		self.push(nil)
+	} else {
+		atomic.StoreInt32(&self.withheld, 0)
+		self.push(work)
+	}
 	self.updateSnapshot()
 }
 
+// scheduleWithhold reports whether an empty work package on top of the given
+// parent may still be withheld from sealing, signalling the update loop to seal
+// a new one once the withholding times out. The caller must hold self.mu.
+func (self *worker) scheduleWithhold(parent common.Hash) bool {
+	if self.withholdParent != parent {
+		self.withholdParent, self.withholdStart = parent, time.Now()
+	}
+	if self.withholdTimer != nil {
+		self.withholdTimer.Stop()
+	}
+	remaining := self.maxWithhold - time.Since(self.withholdStart)
+	if remaining <= 0 {
+		return false
+	}
+	self.withholdTimer = time.AfterFunc(remaining, func() {
+		select {
+		case self.withholdCh <- struct{}{}:
+		default:
+		}
+	})
+	return true
+}
+
 func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 	hash := uncle.Hash()
 	if work.uncles.Has(hash) {
@@ -521,7 +708,7 @@ func (self *worker) updateSnapshot() {
 	self.snapshotState = self.current.state.Copy()
 }
 
-func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
+func (env *Work) commitTransactions(mux *event.TypeMux, txs TxSet, bc *core.BlockChain, coinbase common.Address) {
 	gp := new(core.GasPool).AddGas(env.header.GasLimit)
 
 	var coalescedLogs []*types.Log
@@ -574,6 +761,9 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
 			env.tcount++
+			if tracker, ok := txs.(inclusionTracker); ok {
+				tracker.Included()
+			}
 			txs.Shift()
 
 		default:
@@ -0,0 +1,247 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/doslink/dos/accounts"
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/event"
+	"github.com/doslink/dos/params"
+)
+
+var (
+	// Test accounts funded in the genesis block
+	testBankKey, _  = crypto.GenerateKey()
+	testBankAddress = crypto.PubkeyToAddress(testBankKey.PublicKey)
+	testUserKey, _  = crypto.GenerateKey()
+	testUserAddress = crypto.PubkeyToAddress(testUserKey.PublicKey)
+
+	testFunds = big.NewInt(1000000000000000000)
+)
+
+// testWorkerBackend implements the miner.Backend interface around a fresh chain
+// and transaction pool.
+type testWorkerBackend struct {
+	db     dosdb.Database
+	chain  *core.BlockChain
+	txPool *core.TxPool
+}
+
+func newTestWorkerBackend(t *testing.T, config *params.ChainConfig) *testWorkerBackend {
+	db := dosdb.NewMemDatabase()
+	gspec := core.Genesis{
+		Config: config,
+		Alloc: core.GenesisAlloc{
+			testBankAddress: {Balance: testFunds},
+			testUserAddress: {Balance: testFunds},
+		},
+	}
+	gspec.MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, config, dosash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create blockchain: %v", err)
+	}
+	pool := core.NewTxPool(core.TxPoolConfig{
+		PriceLimit:   1,
+		AccountSlots: 16,
+		GlobalSlots:  4096,
+		AccountQueue: 64,
+		GlobalQueue:  1024,
+		Lifetime:     time.Hour,
+	}, config, chain)
+
+	return &testWorkerBackend{db: db, chain: chain, txPool: pool}
+}
+
+func (b *testWorkerBackend) AccountManager() *accounts.Manager { return nil }
+func (b *testWorkerBackend) BlockChain() *core.BlockChain      { return b.chain }
+func (b *testWorkerBackend) TxPool() *core.TxPool              { return b.txPool }
+func (b *testWorkerBackend) ChainDb() dosdb.Database           { return b.db }
+
+func (b *testWorkerBackend) close() {
+	b.txPool.Stop()
+	b.chain.Stop()
+}
+
+// testAgent is a mining agent collecting the work packages pushed to it.
+type testAgent struct {
+	workCh chan *Work
+}
+
+func newTestAgent() *testAgent {
+	return &testAgent{workCh: make(chan *Work, 16)}
+}
+
+func (a *testAgent) Work() chan<- *Work         { return a.workCh }
+func (a *testAgent) SetReturnCh(chan<- *Result) {}
+func (a *testAgent) Stop()                      {}
+func (a *testAgent) Start()                     {}
+func (a *testAgent) GetHashRate() int64         { return 0 }
+
+// newTestWorker creates a mining worker with a test agent registered.
+func newTestWorker(t *testing.T, config *params.ChainConfig) (*worker, *testWorkerBackend, *testAgent) {
+	backend := newTestWorkerBackend(t, config)
+	agent := newTestAgent()
+
+	w := newWorker(config, dosash.NewFaker(), common.Address{0x01}, backend, new(event.TypeMux))
+	w.register(agent)
+	return w, backend, agent
+}
+
+// transfer creates a simple value transfer signed with the given key.
+func transfer(t *testing.T, signer types.Signer, nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+	tx, err := types.SignTx(types.NewTransaction(nonce, common.Address{0x02}, big.NewInt(1), params.TxGas, big.NewInt(1), nil), signer, key)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	return tx
+}
+
+// waitWork waits for a work package to be pushed to the agent, failing the
+// test if none arrives in time.
+func waitWork(t *testing.T, agent *testAgent, timeout time.Duration) *Work {
+	select {
+	case work := <-agent.workCh:
+		return work
+	case <-time.After(timeout):
+		t.Fatalf("no work package pushed in %v", timeout)
+		return nil
+	}
+}
+
+// waitWithheld waits for the agent to be told to abort its previous work and
+// ensures no work package is pushed to it during the given time.
+func waitWithheld(t *testing.T, agent *testAgent, wait time.Duration) {
+	if work := waitWork(t, agent, time.Second); work != nil {
+		t.Fatalf("empty work package pushed with %d transactions", len(work.txs))
+	}
+	timeout := time.After(wait)
+	for {
+		select {
+		case work := <-agent.workCh:
+			if work != nil {
+				t.Fatalf("empty work package pushed with %d transactions", len(work.txs))
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+// Tests that a work package is rebuilt with transactions arriving after its
+// creation once the recommit interval elapses.
+func TestRecommitInterval(t *testing.T) {
+	config := *params.AllDosashProtocolChanges
+	w, backend, agent := newTestWorker(t, &config)
+	defer backend.close()
+
+	w.setRecommitInterval(minRecommitInterval)
+	w.start()
+	w.commitNewWork()
+
+	if work := waitWork(t, agent, time.Second); len(work.txs) != 0 {
+		t.Fatalf("initial work package transaction count mismatch: have %d, want 0", len(work.txs))
+	}
+	if err := backend.txPool.AddLocal(transfer(t, types.HomesteadSigner{}, 0, testBankKey)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if work := waitWork(t, agent, 3*minRecommitInterval); len(work.txs) != 1 {
+		t.Fatalf("recommitted work package transaction count mismatch: have %d, want 1", len(work.txs))
+	}
+}
+
+// Tests that empty work packages are not sealed while transactions are pending
+// if requested, aborting the previous work instead, but are sealed as soon as an
+// includable transaction arrives.
+func TestNoEmptyWork(t *testing.T) {
+	// Create a chain where replay protected transactions are pending but can't
+	// be included yet, leaving the work packages empty
+	config := *params.AllDosashProtocolChanges
+	config.EIP155Block = big.NewInt(100)
+
+	w, backend, agent := newTestWorker(t, &config)
+	defer backend.close()
+
+	if err := backend.txPool.AddLocal(transfer(t, types.NewEIP155Signer(config.ChainId), 0, testBankKey)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	w.setNoEmpty(true)
+	w.start()
+	w.commitNewWork()
+
+	waitWithheld(t, agent, 100*time.Millisecond)
+	// Add an includable transaction and ensure it's sealed right away
+	if err := backend.txPool.AddLocal(transfer(t, types.HomesteadSigner{}, 0, testUserKey)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	if work := waitWork(t, agent, time.Second); len(work.txs) != 1 {
+		t.Fatalf("work package transaction count mismatch: have %d, want 1", len(work.txs))
+	}
+}
+
+// Tests that empty work packages are only withheld for a limited time if none
+// of the pending transactions can be included.
+func TestNoEmptyWorkTimeout(t *testing.T) {
+	config := *params.AllDosashProtocolChanges
+	config.EIP155Block = big.NewInt(100)
+
+	w, backend, agent := newTestWorker(t, &config)
+	defer backend.close()
+
+	if err := backend.txPool.AddLocal(transfer(t, types.NewEIP155Signer(config.ChainId), 0, testBankKey)); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	w.maxWithhold = 200 * time.Millisecond
+	w.setNoEmpty(true)
+	w.start()
+	w.commitNewWork()
+
+	waitWithheld(t, agent, 100*time.Millisecond)
+	if work := waitWork(t, agent, time.Second); len(work.txs) != 0 {
+		t.Fatalf("work package transaction count mismatch: have %d, want 0", len(work.txs))
+	}
+}
+
+// Tests that updating the recommit interval doesn't block once the worker's
+// update loop has terminated.
+func TestRecommitIntervalAfterExit(t *testing.T) {
+	w, backend, _ := newTestWorker(t, params.AllDosashProtocolChanges)
+	backend.close()
+
+	done := make(chan struct{})
+	go func() {
+		w.setRecommitInterval(minRecommitInterval)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("recommit interval update blocked after exit")
+	}
+}
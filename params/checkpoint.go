@@ -0,0 +1,78 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package params
+
+import (
+	"encoding/binary"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/crypto/sha3"
+)
+
+// TrustedCheckpoints associates each known checkpoint with the genesis hash of
+// the chain it belongs to. Checkpoints are added once their sections are final
+// and signed by the checkpoint admins.
+var TrustedCheckpoints = map[common.Hash]*TrustedCheckpoint{}
+
+// TrustedCheckpoint represents a set of post-processed trie roots (CHT and
+// BloomTrie) associated with the appropriate section index and head hash. It is
+// used to start light syncing from this checkpoint and avoid downloading the
+// entire header chain while still being able to securely access old headers and
+// logs, as well as to reject fast syncing onto a header chain contradicting it.
+type TrustedCheckpoint struct {
+	Name         string      `json:"-"`
+	SectionIndex uint64      `json:"sectionIndex"`
+	SectionHead  common.Hash `json:"sectionHead"`
+	CHTRoot      common.Hash `json:"chtRoot"`
+	BloomRoot    common.Hash `json:"bloomRoot"`
+}
+
+// HeadNumber returns the number of the last block of the checkpointed section,
+// whose hash is the section head.
+func (c *TrustedCheckpoint) HeadNumber() uint64 {
+	return (c.SectionIndex+1)*CheckpointFrequency - 1
+}
+
+// Hash returns the hash of the checkpoint, as signed by the admins of the
+// checkpoint oracle: keccak256(sectionIndex || sectionHead || chtRoot || bloomRoot).
+func (c *TrustedCheckpoint) Hash() common.Hash {
+	buf := make([]byte, 8+3*common.HashLength)
+	binary.BigEndian.PutUint64(buf, c.SectionIndex)
+	copy(buf[8:], c.SectionHead.Bytes())
+	copy(buf[8+common.HashLength:], c.CHTRoot.Bytes())
+	copy(buf[8+2*common.HashLength:], c.BloomRoot.Bytes())
+
+	var h common.Hash
+	hasher := sha3.NewKeccak256()
+	hasher.Write(buf)
+	hasher.Sum(h[:0])
+	return h
+}
+
+// Empty returns whether the checkpoint is uninitialized.
+func (c *TrustedCheckpoint) Empty() bool {
+	return c.SectionHead == (common.Hash{}) || c.CHTRoot == (common.Hash{}) || c.BloomRoot == (common.Hash{})
+}
+
+// CheckpointOracleConfig represents a set of checkpoint contract (which acts as
+// an oracle) config which is used for light clients and checkpoint admins to
+// agree on the latest checkpoint.
+type CheckpointOracleConfig struct {
+	Address   common.Address   `json:"address"`   // Address of the deployed oracle contract
+	Signers   []common.Address `json:"signers"`   // Admins allowed to sign checkpoints
+	Threshold uint64           `json:"threshold"` // Number of admin signatures needed to register a checkpoint
+}
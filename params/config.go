@@ -41,6 +41,7 @@ var (
 		EIP158Block:         big.NewInt(0),
 		ByzantiumBlock:      big.NewInt(0),
 		ConstantinopleBlock: nil,
+		IstanbulBlock:       nil,
 		Dosash:              new(DosashConfig),
 	}
 
@@ -56,6 +57,7 @@ var (
 		EIP158Block:         big.NewInt(0),
 		ByzantiumBlock:      big.NewInt(0),
 		ConstantinopleBlock: nil,
+		IstanbulBlock:       nil,
 		Dosash:              new(DosashConfig),
 	}
 
@@ -71,6 +73,7 @@ var (
 		EIP158Block:         big.NewInt(0),
 		ByzantiumBlock:      big.NewInt(0),
 		ConstantinopleBlock: nil,
+		IstanbulBlock:       nil,
 		Clique: &CliqueConfig{
 			Period: 15,
 			Epoch:  30000,
@@ -82,16 +85,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllDosashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(DosashConfig), nil}
+	AllDosashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(DosashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Doslink core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(DosashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(DosashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -117,14 +120,240 @@ type ChainConfig struct {
 
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
+	IstanbulBlock       *big.Int `json:"istanbulBlock,omitempty"`       // Istanbul switch block (nil = no fork, 0 = already on istanbul)
+
+	CliqueBlock *big.Int `json:"cliqueBlock,omitempty"` // Switch block from dosash to clique sealing (nil = no switch)
 
 	// Various consensus engines
 	Dosash *DosashConfig `json:"dosash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+	Ibft   *IbftConfig   `json:"ibft,omitempty"`
 }
 
 // DosashConfig is the consensus engine configs for proof-of-work based sealing.
-type DosashConfig struct{}
+type DosashConfig struct {
+	RewardEras       []RewardEra `json:"rewardEras,omitempty"`       // Block reward schedule sorted by start block (empty = Frontier/Byzantium rewards)
+	HalvingInterval  uint64      `json:"halvingInterval,omitempty"`  // Number of blocks after which the reward of an era halves (0 = no halving)
+	UncleRewardRatio *uint64     `json:"uncleRewardRatio,omitempty"` // Percentage of the standard uncle rewards paid out (nil = 100)
+
+	Treasury []TreasuryEra `json:"treasury,omitempty"` // Development fund schedule sorted by start block (empty = no treasury share)
+
+	LWMABlock  *big.Int `json:"lwmaBlock,omitempty"`  // LWMA difficulty retargeting switch block (nil = no fork)
+	LWMAWindow uint64   `json:"lwmaWindow,omitempty"` // Number of recent blocks averaged by LWMA (0 = engine default)
+	LWMATarget uint64   `json:"lwmaTarget,omitempty"` // Target block time of LWMA in seconds (0 = engine default)
+}
+
+// IsLWMA returns whether num is either equal to the LWMA difficulty retargeting
+// block or greater.
+func (c *DosashConfig) IsLWMA(num *big.Int) bool {
+	return c != nil && isForked(c.LWMABlock, num)
+}
+
+// RewardEra is a period of the block reward schedule, starting at a given block
+// and lasting until the next era starts.
+type RewardEra struct {
+	Block  *big.Int `json:"block"`  // First block mined with the era's reward
+	Reward *big.Int `json:"reward"` // Block reward in wei at the start of the era
+}
+
+// BlockReward returns the reward for mining block num according to the reward
+// eras, halving it every HalvingInterval blocks since the era started. Blocks
+// before the first era are not rewarded. If no eras are configured, nil is
+// returned and the engine defaults apply.
+func (c *DosashConfig) BlockReward(num *big.Int) *big.Int {
+	if c == nil || len(c.RewardEras) == 0 {
+		return nil
+	}
+	var era *RewardEra
+	for i := range c.RewardEras {
+		if !isForked(c.RewardEras[i].Block, num) {
+			break
+		}
+		era = &c.RewardEras[i]
+	}
+	if era == nil || era.Reward == nil {
+		return new(big.Int)
+	}
+	reward := new(big.Int).Set(era.Reward)
+	if c.HalvingInterval > 0 {
+		halvings := new(big.Int).Sub(num, era.Block)
+		halvings.Div(halvings, new(big.Int).SetUint64(c.HalvingInterval))
+		if !halvings.IsUint64() || halvings.Uint64() >= uint64(reward.BitLen()) {
+			return new(big.Int)
+		}
+		reward.Rsh(reward, uint(halvings.Uint64()))
+	}
+	return reward
+}
+
+// UncleRewardPercent returns the percentage of the standard uncle rewards paid
+// out to the miners of uncle blocks.
+func (c *DosashConfig) UncleRewardPercent() uint64 {
+	if c == nil || c.UncleRewardRatio == nil {
+		return 100
+	}
+	return *c.UncleRewardRatio
+}
+
+// validate checks the sanity of the reward and treasury schedules, ensuring the
+// eras are sorted by start block and the uncle rewards and treasury shares are
+// percentages.
+func (c *DosashConfig) validate() error {
+	for i, era := range c.RewardEras {
+		if era.Block == nil {
+			return fmt.Errorf("dosash reward era %d has no start block", i)
+		}
+		if i > 0 && era.Block.Cmp(c.RewardEras[i-1].Block) <= 0 {
+			return fmt.Errorf("dosash reward era %d starts at block %v, not after the previous era (block %v)", i, era.Block, c.RewardEras[i-1].Block)
+		}
+	}
+	if c.UncleRewardRatio != nil && *c.UncleRewardRatio > 100 {
+		return fmt.Errorf("dosash uncle reward ratio %d%% above 100%%", *c.UncleRewardRatio)
+	}
+	for i, era := range c.Treasury {
+		if era.Block == nil {
+			return fmt.Errorf("dosash treasury era %d has no start block", i)
+		}
+		if i > 0 && era.Block.Cmp(c.Treasury[i-1].Block) <= 0 {
+			return fmt.Errorf("dosash treasury era %d starts at block %v, not after the previous era (block %v)", i, era.Block, c.Treasury[i-1].Block)
+		}
+		if era.Percent > 100 {
+			return fmt.Errorf("dosash treasury era %d share %d%% above 100%%", i, era.Percent)
+		}
+		if era.Percent > 0 && era.Address == (common.Address{}) {
+			return fmt.Errorf("dosash treasury era %d pays %d%% to the zero address", i, era.Percent)
+		}
+	}
+	return nil
+}
+
+// TreasuryEra is a period of the development fund schedule, starting at a given
+// block and lasting until the next era starts.
+type TreasuryEra struct {
+	Block   *big.Int       `json:"block"`   // First block paying the era's share to the treasury
+	Address common.Address `json:"address"` // Treasury address credited with the share
+	Percent uint64         `json:"percent"` // Percentage of the block reward paid to the treasury
+}
+
+// TreasuryShare returns the treasury address and the percentage of the block
+// reward paid to it for mining block num. Blocks before the first era pay no
+// share to the treasury.
+func (c *DosashConfig) TreasuryShare(num *big.Int) (common.Address, uint64) {
+	if c == nil {
+		return common.Address{}, 0
+	}
+	var era *TreasuryEra
+	for i := range c.Treasury {
+		if !isForked(c.Treasury[i].Block, num) {
+			break
+		}
+		era = &c.Treasury[i]
+	}
+	if era == nil {
+		return common.Address{}, 0
+	}
+	return era.Address, era.Percent
+}
+
+// treasuryDiverge returns the first block from which two development fund
+// schedules can pay out different shares, or nil if they're identical.
+func (c *DosashConfig) treasuryDiverge(other *DosashConfig) *big.Int {
+	var eras, others []TreasuryEra
+	if c != nil {
+		eras = c.Treasury
+	}
+	if other != nil {
+		others = other.Treasury
+	}
+	earliest := func(x, y *big.Int) *big.Int {
+		switch {
+		case x == nil:
+			return y
+		case y == nil || x.Cmp(y) < 0:
+			return x
+		default:
+			return y
+		}
+	}
+	for i := 0; i < len(eras) || i < len(others); i++ {
+		switch {
+		case i >= len(eras):
+			return others[i].Block
+		case i >= len(others):
+			return eras[i].Block
+		case !configNumEqual(eras[i].Block, others[i].Block):
+			return earliest(eras[i].Block, others[i].Block)
+		case eras[i].Address != others[i].Address || eras[i].Percent != others[i].Percent:
+			return eras[i].Block
+		}
+	}
+	return nil
+}
+
+// rewardsDiverge returns the first block from which two reward schedules can
+// pay out different rewards, or nil if they're identical.
+func (c *DosashConfig) rewardsDiverge(other *DosashConfig) *big.Int {
+	var diverge *big.Int
+	earliest := func(num *big.Int) {
+		if num == nil {
+			num = new(big.Int)
+		}
+		if diverge == nil || num.Cmp(diverge) < 0 {
+			diverge = num
+		}
+	}
+	if c.UncleRewardPercent() != other.UncleRewardPercent() {
+		earliest(new(big.Int))
+	}
+	var eras, others []RewardEra
+	if c != nil {
+		eras = c.RewardEras
+	}
+	if other != nil {
+		others = other.RewardEras
+	}
+	for i := 0; i < len(eras) || i < len(others); i++ {
+		switch {
+		case i >= len(eras):
+			earliest(others[i].Block)
+		case i >= len(others):
+			earliest(eras[i].Block)
+		case !configNumEqual(eras[i].Block, others[i].Block):
+			earliest(eras[i].Block)
+			earliest(others[i].Block)
+		case !configNumEqual(eras[i].Reward, others[i].Reward):
+			earliest(eras[i].Block)
+		default:
+			continue
+		}
+		break
+	}
+	var interval, otherInterval uint64
+	if c != nil {
+		interval = c.HalvingInterval
+	}
+	if other != nil {
+		otherInterval = other.HalvingInterval
+	}
+	if interval != otherInterval {
+		// Halvings start taking effect one interval after the first era
+		first := interval
+		if first == 0 || (otherInterval != 0 && otherInterval < first) {
+			first = otherInterval
+		}
+		var start *big.Int
+		switch {
+		case len(eras) > 0:
+			start = eras[0].Block
+		case len(others) > 0:
+			start = others[0].Block
+		}
+		if start != nil {
+			earliest(new(big.Int).Add(start, new(big.Int).SetUint64(first)))
+		}
+	}
+	return diverge
+}
 
 // String implements the stringer interface, returning the consensus engine details.
 func (c *DosashConfig) String() string {
@@ -135,6 +364,8 @@ func (c *DosashConfig) String() string {
 type CliqueConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
 	Epoch  uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
+
+	Signers []common.Address `json:"signers,omitempty"` // Initial signers when switching over from dosash at the clique block
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -142,18 +373,35 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// IbftConfig is the consensus engine configs for Byzantine fault tolerant sealing
+// with instant finality.
+type IbftConfig struct {
+	Period         uint64 `json:"period"`         // Number of seconds between blocks to enforce
+	Epoch          uint64 `json:"epoch"`          // Epoch length to reset votes and checkpoint
+	RequestTimeout uint64 `json:"requestTimeout"` // Milliseconds to wait for a round to commit before changing it
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *IbftConfig) String() string {
+	return "ibft"
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
 	switch {
+	case c.Dosash != nil && c.Clique != nil && c.CliqueBlock != nil:
+		engine = fmt.Sprintf("%v->%v@%v", c.Dosash, c.Clique, c.CliqueBlock)
 	case c.Dosash != nil:
 		engine = c.Dosash
 	case c.Clique != nil:
 		engine = c.Clique
+	case c.Ibft != nil:
+		engine = c.Ibft
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Istanbul: %v Engine: %v}",
 		c.ChainId,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -163,6 +411,7 @@ func (c *ChainConfig) String() string {
 		c.EIP158Block,
 		c.ByzantiumBlock,
 		c.ConstantinopleBlock,
+		c.IstanbulBlock,
 		engine,
 	)
 }
@@ -197,6 +446,20 @@ func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// IsIstanbul returns whether num is either equal to the Istanbul fork block or greater.
+func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
+	return isForked(c.IstanbulBlock, num)
+}
+
+// IsClique returns whether num is sealed by the clique proof-of-authority engine,
+// either from genesis or after switching over from dosash at the clique block.
+func (c *ChainConfig) IsClique(num *big.Int) bool {
+	if c.Clique == nil {
+		return false
+	}
+	return c.CliqueBlock == nil || isForked(c.CliqueBlock, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -205,6 +468,8 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 		return GasTableHomestead
 	}
 	switch {
+	case c.IsConstantinople(num):
+		return GasTableConstantinople
 	case c.IsEIP158(num):
 		return GasTableEIP158
 	case c.IsEIP150(num):
@@ -214,6 +479,59 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	}
 }
 
+// CheckConfig checks the sanity of the chain configuration, returning an error
+// if it's inconsistent and can't be used to run a chain.
+func (c *ChainConfig) CheckConfig() error {
+	if err := c.checkForkOrder(); err != nil {
+		return err
+	}
+	if c.Dosash != nil {
+		if err := c.Dosash.validate(); err != nil {
+			return err
+		}
+	}
+	// Switching over to clique needs both engines and someone to seal afterwards
+	if c.CliqueBlock != nil {
+		if c.Dosash == nil || c.Clique == nil {
+			return fmt.Errorf("clique switch block %v requires both dosash and clique configs", c.CliqueBlock)
+		}
+		if c.CliqueBlock.Sign() > 0 && len(c.Clique.Signers) == 0 {
+			return fmt.Errorf("clique switch block %v has no initial signers", c.CliqueBlock)
+		}
+	}
+	return nil
+}
+
+// checkForkOrder ensures the forks are scheduled in the order they build on each
+// other: a fork can only be enabled if the previous one is, and not before it.
+func (c *ChainConfig) checkForkOrder() error {
+	type fork struct {
+		name  string
+		block *big.Int
+	}
+	var last fork
+	for _, cur := range []fork{
+		{"homesteadBlock", c.HomesteadBlock},
+		{"eip150Block", c.EIP150Block},
+		{"eip155Block", c.EIP155Block},
+		{"eip158Block", c.EIP158Block},
+		{"byzantiumBlock", c.ByzantiumBlock},
+		{"constantinopleBlock", c.ConstantinopleBlock},
+		{"istanbulBlock", c.IstanbulBlock},
+	} {
+		if last.name != "" && cur.block != nil {
+			if last.block == nil {
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v", last.name, cur.name, cur.block)
+			}
+			if last.block.Cmp(cur.block) > 0 {
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v", last.name, last.block, cur.name, cur.block)
+			}
+		}
+		last = cur
+	}
+	return nil
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -260,9 +578,53 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	if isForkIncompatible(c.IstanbulBlock, newcfg.IstanbulBlock, head) {
+		return newCompatError("Istanbul fork block", c.IstanbulBlock, newcfg.IstanbulBlock)
+	}
+	if isForkIncompatible(c.CliqueBlock, newcfg.CliqueBlock, head) {
+		return newCompatError("Clique switch block", c.CliqueBlock, newcfg.CliqueBlock)
+	}
+	if isForked(c.CliqueBlock, head) && !cliqueSignersEqual(c.Clique, newcfg.Clique) {
+		return newCompatError("Clique initial signers", c.CliqueBlock, newcfg.CliqueBlock)
+	}
+	if c.Dosash != nil && newcfg.Dosash != nil {
+		if isForkIncompatible(c.Dosash.LWMABlock, newcfg.Dosash.LWMABlock, head) {
+			return newCompatError("LWMA fork block", c.Dosash.LWMABlock, newcfg.Dosash.LWMABlock)
+		}
+		if c.Dosash.IsLWMA(head) && (c.Dosash.LWMAWindow != newcfg.Dosash.LWMAWindow || c.Dosash.LWMATarget != newcfg.Dosash.LWMATarget) {
+			return newCompatError("LWMA parameters", c.Dosash.LWMABlock, newcfg.Dosash.LWMABlock)
+		}
+		if diverge := c.Dosash.rewardsDiverge(newcfg.Dosash); isForked(diverge, head) {
+			return newCompatError("Dosash reward schedule", diverge, diverge)
+		}
+		if diverge := c.Dosash.treasuryDiverge(newcfg.Dosash); isForked(diverge, head) {
+			return newCompatError("Dosash treasury schedule", diverge, diverge)
+		}
+	}
 	return nil
 }
 
+// cliqueSignersEqual returns whether two clique configs switch over to the same
+// initial signers.
+func cliqueSignersEqual(x, y *CliqueConfig) bool {
+	var xs, ys []common.Address
+	if x != nil {
+		xs = x.Signers
+	}
+	if y != nil {
+		ys = y.Signers
+	}
+	if len(xs) != len(ys) {
+		return false
+	}
+	for i := range xs {
+		if xs[i] != ys[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -326,7 +688,7 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainId                                   *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
-	IsByzantium                               bool
+	IsByzantium, IsConstantinople, IsIstanbul bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -334,5 +696,5 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num), IsConstantinople: c.IsConstantinople(num), IsIstanbul: c.IsIstanbul(num)}
 }
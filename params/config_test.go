@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/doslink/dos/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,107 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}}}},
+			new:     &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}, {big.NewInt(20), big.NewInt(3)}}}},
+			head:    10,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}, {big.NewInt(20), big.NewInt(3)}}}},
+			new:    &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}, {big.NewInt(20), big.NewInt(2)}}}},
+			head:   25,
+			wantErr: &ConfigCompatError{
+				What:         "Dosash reward schedule",
+				StoredConfig: big.NewInt(20),
+				NewConfig:    big.NewInt(20),
+				RewindTo:     19,
+			},
+		},
+		{
+			stored: &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(10), big.NewInt(5)}}}},
+			new:    &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(10), big.NewInt(5)}}, HalvingInterval: 100}},
+			head:   200,
+			wantErr: &ConfigCompatError{
+				What:         "Dosash reward schedule",
+				StoredConfig: big.NewInt(110),
+				NewConfig:    big.NewInt(110),
+				RewindTo:     109,
+			},
+		},
+		{
+			stored:  &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(10), big.NewInt(5)}}}},
+			new:     &ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(10), big.NewInt(5)}}, HalvingInterval: 100}},
+			head:    100,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{CliqueBlock: big.NewInt(30)},
+			new:    &ChainConfig{CliqueBlock: nil},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "Clique switch block",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    nil,
+				RewindTo:     29,
+			},
+		},
+		{
+			stored:  &ChainConfig{CliqueBlock: big.NewInt(30), Clique: &CliqueConfig{Signers: []common.Address{{0x01}}}},
+			new:     &ChainConfig{CliqueBlock: big.NewInt(30), Clique: &CliqueConfig{Signers: []common.Address{{0x02}}}},
+			head:    20,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{CliqueBlock: big.NewInt(30), Clique: &CliqueConfig{Signers: []common.Address{{0x01}}}},
+			new:    &ChainConfig{CliqueBlock: big.NewInt(30), Clique: &CliqueConfig{Signers: []common.Address{{0x01}, {0x02}}}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "Clique initial signers",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    big.NewInt(30),
+				RewindTo:     29,
+			},
+		},
+		{
+			stored: &ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Percent: 10}, {Block: big.NewInt(30), Percent: 20}}}},
+			new:    &ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Percent: 10}, {Block: big.NewInt(30), Percent: 15}}}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "Dosash treasury schedule",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    big.NewInt(30),
+				RewindTo:     29,
+			},
+		},
+		{
+			stored:  &ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Percent: 10}}}},
+			new:     &ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Percent: 10}, {Block: big.NewInt(50), Percent: 15}}}},
+			head:    40,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Dosash: &DosashConfig{LWMABlock: big.NewInt(30)}},
+			new:    &ChainConfig{Dosash: &DosashConfig{LWMABlock: big.NewInt(50)}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "LWMA fork block",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    big.NewInt(50),
+				RewindTo:     29,
+			},
+		},
+		{
+			stored: &ChainConfig{Dosash: &DosashConfig{LWMABlock: big.NewInt(30), LWMAWindow: 60}},
+			new:    &ChainConfig{Dosash: &DosashConfig{LWMABlock: big.NewInt(30), LWMAWindow: 90}},
+			head:   40,
+			wantErr: &ConfigCompatError{
+				What:         "LWMA parameters",
+				StoredConfig: big.NewInt(30),
+				NewConfig:    big.NewInt(30),
+				RewindTo:     29,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +182,63 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestDosashBlockReward(t *testing.T) {
+	config := &DosashConfig{
+		RewardEras: []RewardEra{
+			{Block: big.NewInt(10), Reward: big.NewInt(8)},
+			{Block: big.NewInt(100), Reward: big.NewInt(3)},
+		},
+		HalvingInterval: 20,
+	}
+	tests := []struct {
+		block  int64
+		reward int64
+	}{
+		{0, 0}, {9, 0}, {10, 8}, {29, 8}, {30, 4}, {50, 2}, {70, 1}, {90, 0}, {99, 0}, {100, 3}, {120, 1}, {140, 0},
+	}
+	for _, tt := range tests {
+		if reward := config.BlockReward(big.NewInt(tt.block)); reward.Int64() != tt.reward {
+			t.Errorf("block %d: reward mismatch: have %v, want %v", tt.block, reward, tt.reward)
+		}
+	}
+	if reward := new(DosashConfig).BlockReward(big.NewInt(1)); reward != nil {
+		t.Errorf("default reward mismatch: have %v, want nil", reward)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	ratio, overRatio := uint64(100), uint64(101)
+	tests := []struct {
+		config *ChainConfig
+		valid  bool
+	}{
+		{AllDosashProtocolChanges, true},
+		{TestChainConfig, true},
+		{&ChainConfig{ByzantiumBlock: big.NewInt(0), ConstantinopleBlock: big.NewInt(10), IstanbulBlock: big.NewInt(10)}, false},
+		{&ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), ByzantiumBlock: big.NewInt(0), ConstantinopleBlock: big.NewInt(10), IstanbulBlock: big.NewInt(10)}, true},
+		{&ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), ByzantiumBlock: big.NewInt(0), IstanbulBlock: big.NewInt(10)}, false},
+		{&ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), ByzantiumBlock: big.NewInt(0), ConstantinopleBlock: big.NewInt(20), IstanbulBlock: big.NewInt(10)}, false},
+		{&ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}, {big.NewInt(20), big.NewInt(3)}}}}, true},
+		{&ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(20), big.NewInt(5)}, {big.NewInt(10), big.NewInt(3)}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(10), big.NewInt(5)}, {big.NewInt(10), big.NewInt(3)}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{RewardEras: []RewardEra{{big.NewInt(0), big.NewInt(5)}, {nil, big.NewInt(3)}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{UncleRewardRatio: &ratio}}, true},
+		{&ChainConfig{Dosash: &DosashConfig{UncleRewardRatio: &overRatio}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Address: common.Address{0x01}, Percent: 10}, {Block: big.NewInt(30), Address: common.Address{0x01}, Percent: 100}}}}, true},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(30), Address: common.Address{0x01}, Percent: 10}, {Block: big.NewInt(10), Address: common.Address{0x01}, Percent: 20}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Address: common.Address{0x01}, Percent: 10}, {Address: common.Address{0x01}, Percent: 20}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Address: common.Address{0x01}, Percent: 101}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Percent: 10}}}}, false},
+		{&ChainConfig{Dosash: &DosashConfig{Treasury: []TreasuryEra{{Block: big.NewInt(10), Address: common.Address{0x01}, Percent: 10}, {Block: big.NewInt(30)}}}}, true},
+		{&ChainConfig{Dosash: &DosashConfig{}, Clique: &CliqueConfig{Signers: []common.Address{{0x01}}}, CliqueBlock: big.NewInt(10)}, true},
+		{&ChainConfig{Dosash: &DosashConfig{}, Clique: &CliqueConfig{}, CliqueBlock: big.NewInt(10)}, false},
+		{&ChainConfig{Clique: &CliqueConfig{Signers: []common.Address{{0x01}}}, CliqueBlock: big.NewInt(10)}, false},
+		{&ChainConfig{Dosash: &DosashConfig{}, CliqueBlock: big.NewInt(10)}, false},
+	}
+	for i, tt := range tests {
+		if err := tt.config.CheckConfig(); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
@@ -23,4 +23,13 @@ const (
 	// BloomBitsBlocks is the number of blocks a single bloom bit section vector
 	// contains.
 	BloomBitsBlocks uint64 = 4096
+
+	// CheckpointFrequency is the number of blocks a trusted checkpoint section
+	// contains, matching the client side CHT and bloom trie sections of les.
+	CheckpointFrequency uint64 = 32768
+
+	// ImmutabilityThreshold is the number of blocks after which a chain segment is
+	// considered immutable (i.e. soft finality). It is used by the freezer as the
+	// default depth at which blocks are moved out of the key-value database.
+	ImmutabilityThreshold uint64 = 90000
 )
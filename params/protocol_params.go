@@ -57,26 +57,41 @@ const (
 	TierStepGas      uint64 = 0     // Once per operation, for a selection of them.
 	LogTopicGas      uint64 = 375   // Multiplied by the * of the LOG*, per LOG transaction. e.g. LOG0 incurs 0 * c_txLogTopicGas, LOG4 incurs 4 * c_txLogTopicGas.
 	CreateGas        uint64 = 32000 // Once per CREATE operation & contract-creation transaction.
+	Create2Gas       uint64 = 32000 // Once per CREATE2 operation
 	SuicideRefundGas uint64 = 24000 // Refunded following a suicide operation.
 	MemoryGas        uint64 = 3     // Times the address of the (highest referenced byte in memory + 1). NOTE: referencing happens on read, write and in instructions such as RETURN and CALL.
 	TxDataNonZeroGas uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
 
+	NetSstoreNoopGas  uint64 = 200   // Once per SSTORE operation if the value doesn't change.
+	NetSstoreInitGas  uint64 = 20000 // Once per SSTORE operation from clean zero.
+	NetSstoreCleanGas uint64 = 5000  // Once per SSTORE operation from clean non-zero.
+	NetSstoreDirtyGas uint64 = 200   // Once per SSTORE operation from dirty.
+
+	NetSstoreClearRefund      uint64 = 15000 // Once per SSTORE operation for clearing an originally existing storage slot
+	NetSstoreResetRefund      uint64 = 4800  // Once per SSTORE operation for resetting to the original non-zero value
+	NetSstoreResetClearRefund uint64 = 19800 // Once per SSTORE operation for resetting to the original zero value
+
 	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
 
 	// Precompiled contract gas prices
 
-	EcrecoverGas            uint64 = 3000   // Elliptic curve sender recovery gas price
-	Sha256BaseGas           uint64 = 60     // Base price for a SHA256 operation
-	Sha256PerWordGas        uint64 = 12     // Per-word price for a SHA256 operation
-	Ripemd160BaseGas        uint64 = 600    // Base price for a RIPEMD160 operation
-	Ripemd160PerWordGas     uint64 = 120    // Per-word price for a RIPEMD160 operation
-	IdentityBaseGas         uint64 = 15     // Base price for a data copy operation
-	IdentityPerWordGas      uint64 = 3      // Per-work price for a data copy operation
-	ModExpQuadCoeffDiv      uint64 = 20     // Divisor for the quadratic particle of the big int modular exponentiation
-	Bn256AddGas             uint64 = 500    // Gas needed for an elliptic curve addition
-	Bn256ScalarMulGas       uint64 = 40000  // Gas needed for an elliptic curve scalar multiplication
-	Bn256PairingBaseGas     uint64 = 100000 // Base price for an elliptic curve pairing check
-	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
+	EcrecoverGas                    uint64 = 3000   // Elliptic curve sender recovery gas price
+	Sha256BaseGas                   uint64 = 60     // Base price for a SHA256 operation
+	Sha256PerWordGas                uint64 = 12     // Per-word price for a SHA256 operation
+	Ripemd160BaseGas                uint64 = 600    // Base price for a RIPEMD160 operation
+	Ripemd160PerWordGas             uint64 = 120    // Per-word price for a RIPEMD160 operation
+	IdentityBaseGas                 uint64 = 15     // Base price for a data copy operation
+	IdentityPerWordGas              uint64 = 3      // Per-work price for a data copy operation
+	ModExpQuadCoeffDiv              uint64 = 20     // Divisor for the quadratic particle of the big int modular exponentiation
+	Bn256AddGas                     uint64 = 500    // Gas needed for an elliptic curve addition
+	Bn256AddGasIstanbul             uint64 = 150    // Gas needed for an elliptic curve addition after Istanbul (EIP-1108)
+	Bn256ScalarMulGas               uint64 = 40000  // Gas needed for an elliptic curve scalar multiplication
+	Bn256ScalarMulGasIstanbul       uint64 = 6000   // Gas needed for an elliptic curve scalar multiplication after Istanbul (EIP-1108)
+	Bn256PairingBaseGas             uint64 = 100000 // Base price for an elliptic curve pairing check
+	Bn256PairingBaseGasIstanbul     uint64 = 45000  // Base price for an elliptic curve pairing check after Istanbul (EIP-1108)
+	Bn256PairingPerPointGas         uint64 = 80000  // Per-point price for an elliptic curve pairing check
+	Bn256PairingPerPointGasIstanbul uint64 = 34000  // Per-point price for an elliptic curve pairing check after Istanbul (EIP-1108)
+	Blake2FRoundGas                 uint64 = 1      // Per-round price for a BLAKE2b compression (EIP-152)
 )
 
 var (
@@ -30,7 +30,7 @@ func TestBlockchain(t *testing.T) {
 	bt.skipLoad(`^bcForgedTest/bcForkUncle\.json`)
 	bt.skipLoad(`^bcMultiChainTest/(ChainAtoChainB_blockorder|CallContractFromNotBestBlock)`)
 	bt.skipLoad(`^bcTotalDifficultyTest/(lotsOfLeafs|lotsOfBranches|sideChainWithMoreTransactions)`)
-	// Constantinople is not implemented yet.
+	// Constantinople consensus changes (difficulty bomb delay, block reward) are not implemented yet.
 	bt.skipLoad(`(?i)(constantinople)`)
 
 	// Still failing tests
@@ -0,0 +1,161 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/core"
+	"github.com/doslink/dos/core/state"
+	"github.com/doslink/dos/core/vm"
+	"github.com/doslink/dos/crypto"
+	"github.com/doslink/dos/dosdb"
+	"github.com/doslink/dos/params"
+)
+
+var (
+	constantinopleSender   = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	constantinopleContract = common.HexToAddress("0x2000000000000000000000000000000000000002")
+)
+
+// runConstantinopleCode deploys code into a fresh state with the given initial
+// storage, calls it on the named fork and returns the gas used by the execution
+// (excluding intrinsic gas), along with the resulting state.
+func runConstantinopleCode(t *testing.T, fork string, code []byte, storage map[common.Hash]common.Hash) (uint64, *state.StateDB) {
+	statedb := MakePreState(dosdb.NewMemDatabase(), core.GenesisAlloc{
+		constantinopleSender:   {Balance: big.NewInt(1000000000000000000)},
+		constantinopleContract: {Code: code, Nonce: 1, Balance: new(big.Int), Storage: storage},
+	})
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      constantinopleSender,
+		BlockNumber: big.NewInt(0),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(0),
+		GasLimit:    params.GenesisGasLimit,
+		GasPrice:    big.NewInt(1),
+	}
+	evm := vm.NewEVM(context, statedb, Forks[fork], vm.Config{})
+
+	gas := uint64(100000)
+	_, leftover, err := evm.Call(vm.AccountRef(constantinopleSender), constantinopleContract, nil, gas, new(big.Int))
+	if err != nil {
+		t.Fatalf("%s: failed to execute %x: %v", fork, code, err)
+	}
+	return gas - leftover, statedb
+}
+
+// Tests the net gas metering of SSTORE (EIP-1283) against the reference cases.
+func TestNetSstoreGas(t *testing.T) {
+	tests := []struct {
+		code     string
+		original byte
+		used     uint64
+		refund   uint64
+	}{
+		{"0x60006000556000600055", 0, 412, 0},
+		{"0x60006000556001600055", 0, 20212, 0},
+		{"0x60016000556000600055", 0, 20212, 19800},
+		{"0x60016000556002600055", 0, 20212, 0},
+		{"0x60016000556001600055", 0, 20212, 0},
+		{"0x60006000556000600055", 1, 5212, 15000},
+		{"0x60006000556001600055", 1, 5212, 4800},
+		{"0x60006000556002600055", 1, 5212, 0},
+		{"0x60026000556000600055", 1, 5212, 15000},
+		{"0x60026000556003600055", 1, 5212, 0},
+		{"0x60026000556001600055", 1, 5212, 4800},
+		{"0x60026000556002600055", 1, 5212, 0},
+		{"0x60016000556000600055", 1, 5212, 15000},
+		{"0x60016000556002600055", 1, 5212, 0},
+		{"0x60016000556001600055", 1, 412, 0},
+		{"0x600160005560006000556001600055", 0, 40218, 19800},
+		{"0x600060005560016000556000600055", 1, 10218, 19800},
+	}
+	for i, tt := range tests {
+		storage := map[common.Hash]common.Hash{{}: common.BytesToHash([]byte{tt.original})}
+		used, statedb := runConstantinopleCode(t, "Constantinople", common.FromHex(tt.code), storage)
+		if used != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.used)
+		}
+		if refund := statedb.GetRefund(); refund != tt.refund {
+			t.Errorf("test %d: gas refund mismatch: have %v, want %v", i, refund, tt.refund)
+		}
+	}
+	// Ensure the legacy metering is still used before the fork
+	used, statedb := runConstantinopleCode(t, "Byzantium", common.FromHex("0x60016000556000600055"), nil)
+	if used != 25012 || statedb.GetRefund() != 15000 {
+		t.Errorf("legacy metering mismatch: have %v/%v, want %v/%v", used, statedb.GetRefund(), 25012, 15000)
+	}
+}
+
+// Tests that CREATE2 deploys contracts to the salted address.
+func TestCreate2(t *testing.T) {
+	// PUSH1 salt, PUSH1 size, PUSH1 offset, PUSH1 value, CREATE2, PUSH1 0, SSTORE
+	code := common.FromHex("0x6007600060006000f5600055")
+
+	_, statedb := runConstantinopleCode(t, "Constantinople", code, nil)
+
+	want := crypto.CreateAddress2(constantinopleContract, common.BigToHash(big.NewInt(7)), crypto.Keccak256(nil))
+	if have := common.BytesToAddress(statedb.GetState(constantinopleContract, common.Hash{}).Bytes()); have != want {
+		t.Errorf("created address mismatch: have %x, want %x", have, want)
+	}
+	if nonce := statedb.GetNonce(want); nonce != 1 {
+		t.Errorf("created account nonce mismatch: have %v, want %v", nonce, 1)
+	}
+	// Running the same code a second time must collide and push zero
+	if _, _, err := vm.NewEVM(vm.Context{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(0)}, statedb, Forks["Constantinople"], vm.Config{}).Call(vm.AccountRef(constantinopleSender), constantinopleContract, nil, 100000, new(big.Int)); err != nil {
+		t.Fatalf("failed to rerun creation: %v", err)
+	}
+	if have := statedb.GetState(constantinopleContract, common.Hash{}); have != (common.Hash{}) {
+		t.Errorf("colliding creation address mismatch: have %x, want zero", have)
+	}
+	// Ensure the opcode is rejected before the fork
+	statedb = MakePreState(dosdb.NewMemDatabase(), core.GenesisAlloc{constantinopleContract: {Code: code, Balance: new(big.Int)}})
+	evm := vm.NewEVM(vm.Context{CanTransfer: core.CanTransfer, Transfer: core.Transfer, BlockNumber: big.NewInt(0)}, statedb, Forks["Byzantium"], vm.Config{})
+	if _, _, err := evm.Call(vm.AccountRef(constantinopleSender), constantinopleContract, nil, 100000, new(big.Int)); err == nil {
+		t.Errorf("CREATE2 accepted before Constantinople")
+	}
+}
+
+// Tests that EXTCODEHASH returns the code hash of contracts, the empty hash of
+// plain accounts and zero for non-existent ones.
+func TestExtCodeHash(t *testing.T) {
+	// PUSH20 addr, EXTCODEHASH, PUSH1 slot, SSTORE for the contract, the sender
+	// and a non-existent account.
+	code := common.FromHex("0x73" + common.Bytes2Hex(constantinopleContract.Bytes()) + "3f600055" +
+		"73" + common.Bytes2Hex(constantinopleSender.Bytes()) + "3f600155" +
+		"73" + common.Bytes2Hex(common.HexToAddress("0xdead").Bytes()) + "3f600255")
+
+	_, statedb := runConstantinopleCode(t, "Constantinople", code, nil)
+
+	tests := []struct {
+		slot byte
+		want common.Hash
+	}{
+		{0, crypto.Keccak256Hash(code)},
+		{1, crypto.Keccak256Hash(nil)},
+		{2, common.Hash{}},
+	}
+	for _, tt := range tests {
+		if have := statedb.GetState(constantinopleContract, common.BytesToHash([]byte{tt.slot})); have != tt.want {
+			t.Errorf("slot %d: code hash mismatch: have %x, want %x", tt.slot, have, tt.want)
+		}
+	}
+}
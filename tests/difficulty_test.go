@@ -84,3 +84,69 @@ func TestDifficulty(t *testing.T) {
 		}
 	})
 }
+
+// lwmaBlocks creates a chain of blocks for an LWMA difficulty test.
+func lwmaBlocks(times []uint64, difficulties []int64) []LWMABlock {
+	blocks := make([]LWMABlock, len(times))
+	for i := range times {
+		blocks[i] = LWMABlock{Timestamp: times[i], Difficulty: big.NewInt(difficulties[i])}
+	}
+	return blocks
+}
+
+func TestLWMADifficulty(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]LWMATest{
+		"steady": {
+			Window: 5, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 15, 30, 45, 60, 75}, []int64{1000, 1000, 1000, 1000, 1000, 1000}),
+			CurrentDifficulty: big.NewInt(1000),
+		},
+		"fast": {
+			Window: 5, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 5, 10, 15, 20, 25}, []int64{1000, 1000, 1000, 1000, 1000, 1000}),
+			CurrentDifficulty: big.NewInt(3000),
+		},
+		"slow": {
+			Window: 5, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 90, 180, 270, 360, 450}, []int64{1000, 1000, 1000, 1000, 1000, 1000}),
+			CurrentDifficulty: big.NewInt(166),
+		},
+		"stalled": {
+			Window: 5, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 15, 30, 45, 60, 1060}, []int64{1000, 1000, 1000, 1000, 1000, 1000}),
+			CurrentDifficulty: big.NewInt(375),
+		},
+		"tenfold": {
+			Window: 5, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 1, 2, 3, 4, 5}, []int64{1000, 1000, 1000, 1000, 1000, 1000}),
+			CurrentDifficulty: big.NewInt(10227),
+		},
+		"short": {
+			Window: 60, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0, 20, 30}, []int64{605, 605, 700}),
+			CurrentDifficulty: big.NewInt(734),
+		},
+		"genesis": {
+			Window: 60, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{0}, []int64{605}),
+			CurrentDifficulty: big.NewInt(605),
+		},
+		"window": {
+			Window: 3, Target: 10,
+			Blocks:            lwmaBlocks([]uint64{0, 10, 12, 40, 41, 60}, []int64{605, 700, 800, 900, 1000, 1100}),
+			CurrentDifficulty: big.NewInt(689),
+		},
+		"swing": {
+			Window: 4, Target: 15,
+			Blocks:            lwmaBlocks([]uint64{100, 101, 103, 200, 215}, []int64{131072, 140000, 150000, 90000, 95000}),
+			CurrentDifficulty: big.NewInt(53171),
+		},
+	}
+	for name, test := range tests {
+		if err := test.Run(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
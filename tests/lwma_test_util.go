@@ -0,0 +1,78 @@
+// Copyright 2018 The dos Authors
+// This file is part of the dos library.
+//
+// The dos library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The dos library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the dos library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/doslink/dos/common"
+	"github.com/doslink/dos/consensus/dosash"
+	"github.com/doslink/dos/core/types"
+	"github.com/doslink/dos/params"
+)
+
+// LWMATest checks the difficulty calculated by the LWMA retargeting algorithm
+// on top of a chain of blocks.
+type LWMATest struct {
+	Window            uint64      `json:"window"`
+	Target            uint64      `json:"target"`
+	Blocks            []LWMABlock `json:"blocks"` // Chain starting at genesis, the last one being the parent
+	CurrentDifficulty *big.Int    `json:"currentDifficulty"`
+}
+
+// LWMABlock is a block of the chain an LWMA difficulty test is run on.
+type LWMABlock struct {
+	Timestamp  uint64   `json:"timestamp"`
+	Difficulty *big.Int `json:"difficulty"`
+}
+
+func (test *LWMATest) Run() error {
+	// Assemble the chain of headers to retrieve the ancestors from
+	headers := make(map[common.Hash]*types.Header)
+
+	var parent *types.Header
+	for i, block := range test.Blocks {
+		header := &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Time:       new(big.Int).SetUint64(block.Timestamp),
+			Difficulty: block.Difficulty,
+		}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		headers[header.Hash()] = header
+		parent = header
+	}
+	getHeader := func(hash common.Hash, number uint64) *types.Header {
+		if header := headers[hash]; header != nil && header.Number.Uint64() == number {
+			return header
+		}
+		return nil
+	}
+	config := &params.DosashConfig{LWMABlock: new(big.Int), LWMAWindow: test.Window, LWMATarget: test.Target}
+
+	actual, err := dosash.CalcDifficultyLWMA(config, parent, getHeader)
+	if err != nil {
+		return err
+	}
+	if actual.Cmp(test.CurrentDifficulty) != 0 {
+		return fmt.Errorf("window %d target %d blocks %v: diff %v != expected %v",
+			test.Window, test.Target, test.Blocks, actual, test.CurrentDifficulty)
+	}
+	return nil
+}